@@ -1,15 +1,22 @@
 package main
 
 import(
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
 	"time"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
 
 	"backgo/internal/handler"
 	"backgo/internal/infoDB"
+	"backgo/internal/markdown"
 	"backgo/internal/middleware"
+	"backgo/internal/storage"
 
 	"github.com/gin-gonic/gin"
 	_ "github.com/lib/pq"
@@ -63,7 +70,7 @@ func corsMiddleware() gin.HandlerFunc {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Accept-Language")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -74,17 +81,210 @@ func corsMiddleware() gin.HandlerFunc {
 	}
 }
 
+// initImageStorage ตั้งค่าที่เก็บรูปที่ upload (local filesystem)
+func initImageStorage() *storage.LocalBlobStore {
+	root := getEnv("MEDIA_ROOT", "./uploads")
+	baseURL := getEnv("MEDIA_BASE_URL", "/media")
+
+	maxUploadMB, err := strconv.Atoi(getEnv("MAX_UPLOAD_MB", "10"))
+	if err != nil || maxUploadMB <= 0 {
+		log.Fatal("Invalid MAX_UPLOAD_MB:", getEnv("MAX_UPLOAD_MB", ""))
+	}
+
+	store, err := storage.NewLocalBlobStore(root, baseURL)
+	if err != nil {
+		log.Fatal("Failed to initialise media storage:", err)
+	}
+
+	handler.SetImageStorage(store, int64(maxUploadMB)<<20)
+	infoDB.SetBlobURLResolver(store.URL)
+
+	return store
+}
+
+// startTrashPurger ลบ breed ที่อยู่ในถังขยะเกินระยะเวลาเก็บรักษาออกถาวรเป็นระยะ
+func startTrashPurger(store storage.BlobStore) {
+	retentionDays, err := strconv.Atoi(getEnv("CAT_TRASH_RETENTION_DAYS", "30"))
+	if err != nil || retentionDays < 0 {
+		log.Fatal("Invalid CAT_TRASH_RETENTION_DAYS:", getEnv("CAT_TRASH_RETENTION_DAYS", ""))
+	}
+	interval, err := time.ParseDuration(getEnv("CAT_TRASH_PURGE_INTERVAL", "1h"))
+	if err != nil || interval <= 0 {
+		log.Fatal("Invalid CAT_TRASH_PURGE_INTERVAL:", getEnv("CAT_TRASH_PURGE_INTERVAL", ""))
+	}
+	retention := time.Duration(retentionDays) * 24 * time.Hour
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			purged, blobKeys, err := infoDB.PurgeDeletedCats(retention)
+			if err != nil {
+				log.Println("Failed to purge deleted cats:", err)
+				continue
+			}
+			for _, key := range blobKeys {
+				if err := store.Delete(context.Background(), key); err != nil {
+					log.Printf("Failed to delete blob %s: %v", key, err)
+				}
+			}
+			if purged > 0 {
+				log.Printf("Purged %d cat(s) from trash", purged)
+			}
+		}
+	}()
+}
+
+// startViewRecorder เริ่มตัวนับยอดวิวแบบ batch (ไม่นับซ้ำภายใน VIEW_DEDUP_WINDOW)
+func startViewRecorder() {
+	window, err := time.ParseDuration(getEnv("VIEW_DEDUP_WINDOW", "30m"))
+	if err != nil || window < 0 {
+		log.Fatal("Invalid VIEW_DEDUP_WINDOW:", getEnv("VIEW_DEDUP_WINDOW", ""))
+	}
+	interval, err := time.ParseDuration(getEnv("VIEW_FLUSH_INTERVAL", "10s"))
+	if err != nil || interval <= 0 {
+		log.Fatal("Invalid VIEW_FLUSH_INTERVAL:", getEnv("VIEW_FLUSH_INTERVAL", ""))
+	}
+	bufferSize, err := strconv.Atoi(getEnv("VIEW_BUFFER_SIZE", "10000"))
+	if err != nil || bufferSize <= 0 {
+		log.Fatal("Invalid VIEW_BUFFER_SIZE:", getEnv("VIEW_BUFFER_SIZE", ""))
+	}
+
+	infoDB.StartViewRecorder(window, interval, bufferSize, 1000)
+}
+
+// configureTrustedProxies เชื่อ X-Forwarded-For เฉพาะจาก proxy ใน TRUSTED_PROXIES (คั่นด้วย ,)
+// ถ้าไม่ได้ตั้งไว้ ClientIP() คือ IP ที่ต่อเข้ามาจริง ปลอม header เพื่อนับยอดวิวซ้ำไม่ได้
+func configureTrustedProxies(r *gin.Engine) {
+	var proxies []string
+	for _, proxy := range strings.Split(getEnv("TRUSTED_PROXIES", ""), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+
+	if err := r.SetTrustedProxies(proxies); err != nil {
+		log.Fatal("Invalid TRUSTED_PROXIES:", err)
+	}
+}
+
+// stopOnSignal เขียนยอดวิวที่ค้างใน buffer ลง DB ก่อนปิดโปรแกรม (SIGINT/SIGTERM)
+func stopOnSignal() {
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+
+	go func() {
+		sig := <-quit
+		log.Println("Shutting down:", sig)
+		infoDB.StopViewRecorder()
+		db.Close()
+		os.Exit(0)
+	}()
+}
+
+// startRankingRefresher คำนวณ trending/top ใหม่เป็นระยะแล้วเก็บไว้ใน cache
+func startRankingRefresher() {
+	interval, err := time.ParseDuration(getEnv("RANKING_REFRESH_INTERVAL", "5m"))
+	if err != nil || interval <= 0 {
+		log.Fatal("Invalid RANKING_REFRESH_INTERVAL:", getEnv("RANKING_REFRESH_INTERVAL", ""))
+	}
+	halfLife, err := time.ParseDuration(getEnv("TRENDING_HALF_LIFE", "48h"))
+	if err != nil || halfLife <= 0 {
+		log.Fatal("Invalid TRENDING_HALF_LIFE:", getEnv("TRENDING_HALF_LIFE", ""))
+	}
+	windowDays, err := strconv.Atoi(getEnv("TRENDING_WINDOW_DAYS", "14"))
+	if err != nil || windowDays <= 0 {
+		log.Fatal("Invalid TRENDING_WINDOW_DAYS:", getEnv("TRENDING_WINDOW_DAYS", ""))
+	}
+
+	infoDB.StartRankingRefresher(infoDB.TrendingConfig{
+		WindowDays:       windowDays,
+		HalfLife:         halfLife,
+		ViewWeight:       1,
+		LikeWeight:       3,
+		DislikeWeight:    -1,
+		DiscussionWeight: 5,
+	}, interval)
+}
+
+// startRelatedRefresher คำนวณสายพันธุ์ที่เกี่ยวข้องใหม่เป็นระยะแล้วเก็บลง breed_neighbors
+func startRelatedRefresher() {
+	interval, err := time.ParseDuration(getEnv("RELATED_REFRESH_INTERVAL", "1h"))
+	if err != nil || interval <= 0 {
+		log.Fatal("Invalid RELATED_REFRESH_INTERVAL:", getEnv("RELATED_REFRESH_INTERVAL", ""))
+	}
+	neighbors, err := strconv.Atoi(getEnv("RELATED_NEIGHBORS", "20"))
+	if err != nil || neighbors <= 0 {
+		log.Fatal("Invalid RELATED_NEIGHBORS:", getEnv("RELATED_NEIGHBORS", ""))
+	}
+	coLikeWeight, err := strconv.ParseFloat(getEnv("RELATED_CO_LIKE_WEIGHT", "0.6"), 64)
+	if err != nil || coLikeWeight < 0 || coLikeWeight > 1 {
+		log.Fatal("Invalid RELATED_CO_LIKE_WEIGHT:", getEnv("RELATED_CO_LIKE_WEIGHT", ""))
+	}
+
+	infoDB.StartRelatedRefresher(infoDB.RelatedConfig{
+		Neighbors:    neighbors,
+		CoLikeWeight: coLikeWeight,
+	}, interval)
+}
+
+// configureDiscussions ตั้งค่าความลึกของ thread, การเก็บ edit history และ markdown ของ discussion
+func configureDiscussions() {
+	maxDepth, err := strconv.Atoi(getEnv("DISCUSSION_MAX_DEPTH", "8"))
+	if err != nil || maxDepth < 1 {
+		log.Fatal("Invalid DISCUSSION_MAX_DEPTH:", getEnv("DISCUSSION_MAX_DEPTH", ""))
+	}
+	editGrace, err := time.ParseDuration(getEnv("DISCUSSION_EDIT_GRACE", "5m"))
+	if err != nil || editGrace < 0 {
+		log.Fatal("Invalid DISCUSSION_EDIT_GRACE:", getEnv("DISCUSSION_EDIT_GRACE", ""))
+	}
+	publicHistory, err := strconv.ParseBool(getEnv("DISCUSSION_PUBLIC_EDIT_HISTORY", "false"))
+	if err != nil {
+		log.Fatal("Invalid DISCUSSION_PUBLIC_EDIT_HISTORY:", getEnv("DISCUSSION_PUBLIC_EDIT_HISTORY", ""))
+	}
+
+	infoDB.SetDiscussionConfig(infoDB.DiscussionConfig{
+		MaxDepth:          maxDepth,
+		EditGrace:         editGrace,
+		PublicEditHistory: publicHistory,
+	})
+
+	// รูปใน markdown แสดงได้เฉพาะจาก host เหล่านี้ (คั่นด้วย ,)
+	markdown.SetImageHosts(strings.Split(getEnv("DISCUSSION_IMAGE_HOSTS", ""), ","))
+}
+
 func main(){
 	initDB()
 	infoDB.SetDB(db)
 	defer db.Close()
 
+	infoDB.SetLocales(getEnv("DEFAULT_LOCALE", "th"), strings.Split(getEnv("SUPPORTED_LOCALES", "th,en"), ","))
+
+	configureDiscussions()
+
+	store := initImageStorage()
+
+	startTrashPurger(store)
+	startViewRecorder()
+	stopOnSignal()
+	startRankingRefresher()
+	startRelatedRefresher()
+
 	r := gin.Default()
+	configureTrustedProxies(r)
 	r.Use(cors.Default())
 	r.Use(corsMiddleware())
+	r.Use(middleware.LocaleMiddleware())
+
+	// Uploaded media (local storage)
+	if strings.HasPrefix(store.BaseURL, "/") {
+		r.Static(store.BaseURL, store.Root)
+	}
 
 	// ===================== PUBLIC ROUTES =====================
 	public := r.Group("/api")
+	public.Use(middleware.OptionalAuthMiddleware())
 	{
 		// Health check
 		public.GET("/health", func(c *gin.Context) {
@@ -104,9 +304,36 @@ func main(){
 
 		// Public cat routes (view only)
 		public.GET("/cats", handler.GetAllCatsHandler)
+		public.GET("/cats/trending", handler.GetTrendingCatsHandler)
+		public.GET("/cats/top", handler.GetTopCatsHandler)
+		public.GET("/cats/compare", handler.CompareCatsHandler)
 		public.GET("/cats/:id", handler.GetCatHandler)
 		public.GET("/cats/:id/reactions", handler.GetCatReactionStatsHandler)
+		public.GET("/cats/:id/images", handler.GetCatGalleryHandler)
 		public.GET("/cats/:id/discussions", handler.GetCatDiscussionsHandler)
+		public.GET("/cats/:id/related", handler.GetRelatedCatsHandler)
+		public.GET("/cats/:id/contributors", handler.GetCatContributorsHandler)
+		public.GET("/cats/:id/lineage", handler.GetCatLineageHandler)
+		public.GET("/cats/:id/conditions", handler.GetCatConditionsHandler)
+
+		// Discussion threads (subtree / load more replies)
+		public.GET("/discussions/:id/thread", handler.GetDiscussionThreadHandler)
+		public.GET("/discussions/:id/replies", handler.GetDiscussionRepliesHandler)
+		public.GET("/discussions/:id/history", handler.GetDiscussionHistoryHandler)
+
+		// Public and shared (unlisted) collections
+		public.GET("/collections", handler.GetPublicCollectionsHandler)
+		public.GET("/collections/shared/:token", handler.GetSharedCollectionHandler)
+		public.GET("/collections/:collection_id", handler.GetCollectionHandler)
+
+		// Health condition registry
+		public.GET("/conditions", handler.GetConditionsHandler)
+		public.GET("/conditions/:condition_id", handler.GetConditionHandler)
+		public.GET("/conditions/:condition_id/cats", handler.GetConditionCatsHandler)
+
+		// Breed matcher (results are saved for signed-in users)
+		public.GET("/matcher/questions", handler.GetMatcherQuestionsHandler)
+		public.POST("/matcher", handler.MatchCatsHandler)
 	}
 
 	// ===================== USER PROTECTED ROUTES =====================
@@ -119,6 +346,49 @@ func main(){
 		// Cat reactions (like/dislike)
 		user.POST("/cats/:id/react", handler.ToggleCatReactionHandler)
 
+		// Favorites
+		user.GET("/me/favorites", handler.GetFavoritesHandler)
+		user.PUT("/me/favorites/:id", handler.AddFavoriteHandler)
+		user.DELETE("/me/favorites/:id", handler.RemoveFavoriteHandler)
+
+		// Collections
+		user.GET("/me/collections", handler.GetMyCollectionsHandler)
+		user.POST("/me/collections", handler.CreateCollectionHandler)
+		user.PUT("/me/collections/:collection_id", handler.UpdateCollectionHandler)
+		user.DELETE("/me/collections/:collection_id", handler.DeleteCollectionHandler)
+		user.PUT("/me/collections/:collection_id/items/order", handler.ReorderCollectionItemsHandler)
+		user.PUT("/me/collections/:collection_id/items/:id", handler.SetCollectionItemHandler)
+		user.DELETE("/me/collections/:collection_id/items/:id", handler.RemoveCollectionItemHandler)
+
+		// Community breed submissions (draft -> pending -> reviewed)
+		user.GET("/me/submissions", handler.GetMySubmissionsHandler)
+		user.POST("/me/submissions", handler.CreateSubmissionHandler)
+		user.GET("/me/submissions/:submission_id", handler.GetMySubmissionHandler)
+		user.PUT("/me/submissions/:submission_id", handler.UpdateSubmissionHandler)
+		user.DELETE("/me/submissions/:submission_id", handler.DeleteSubmissionHandler)
+		user.POST("/me/submissions/:submission_id/submit", handler.SubmitSubmissionHandler)
+		user.POST("/me/submissions/:submission_id/withdraw", handler.WithdrawSubmissionHandler)
+		user.POST("/me/submissions/:submission_id/comments", handler.CommentOnMySubmissionHandler)
+
+		// Personalized breed recommendations
+		user.GET("/me/recommendations", handler.GetRecommendationsHandler)
+
+		// Saved breed matcher results
+		user.GET("/me/matcher/results", handler.GetMyMatcherResultsHandler)
+		user.GET("/me/matcher/results/:result_id", handler.GetMyMatcherResultHandler)
+		user.DELETE("/me/matcher/results/:result_id", handler.DeleteMyMatcherResultHandler)
+
+		// Discussions mentioning me
+		user.GET("/me/mentions", handler.GetMyMentionsHandler)
+
+		// Notification center
+		user.GET("/me/notifications", handler.GetMyNotificationsHandler)
+		user.GET("/me/notifications/unread-count", handler.GetMyUnreadNotificationsHandler)
+		user.POST("/me/notifications/read-all", handler.MarkAllNotificationsReadHandler)
+		user.POST("/me/notifications/:notification_id/read", handler.MarkNotificationReadHandler)
+		user.GET("/me/notifications/preferences", handler.GetNotificationPreferencesHandler)
+		user.PUT("/me/notifications/preferences", handler.UpdateNotificationPreferencesHandler)
+
 		// Discussions (comments)
 		user.POST("/discussions", handler.CreateDiscussionHandler)
 		user.PUT("/discussions/:id", handler.UpdateDiscussionHandler)
@@ -134,6 +404,58 @@ func main(){
 		admin.POST("/cats", handler.CreateCatHandler)
 		admin.PUT("/cats/:id", handler.UpdateCatHandler)
 		admin.DELETE("/cats/:id", handler.DeleteCatHandler)
+
+		// Breed gallery
+		admin.POST("/cats/:id/images", handler.UploadCatImageHandler)
+		admin.PUT("/cats/:id/images/order", handler.ReorderCatImagesHandler)
+		admin.PUT("/cats/:id/images/:image_id", handler.UpdateCatImageHandler)
+		admin.DELETE("/cats/:id/images/:image_id", handler.DeleteCatImageHandler)
+		admin.PUT("/cats/:id/cover", handler.SetCatCoverImageHandler)
+
+		// Breed translations (per locale)
+		admin.GET("/cats/:id/translations", handler.GetCatTranslationsHandler)
+		admin.PUT("/cats/:id/translations/:locale", handler.UpsertCatTranslationHandler)
+		admin.DELETE("/cats/:id/translations/:locale", handler.DeleteCatTranslationHandler)
+
+		// Breed relationships (lineage graph)
+		admin.POST("/cats/:id/relationships", handler.CreateCatRelationshipHandler)
+		admin.DELETE("/cats/:id/relationships/:relationship_id", handler.DeleteCatRelationshipHandler)
+
+		// Health conditions
+		admin.POST("/conditions", handler.CreateConditionHandler)
+		admin.PUT("/conditions/:condition_id", handler.UpdateConditionHandler)
+		admin.DELETE("/conditions/:condition_id", handler.DeleteConditionHandler)
+		admin.PUT("/cats/:id/conditions/:condition_id", handler.SetCatConditionHandler)
+		admin.DELETE("/cats/:id/conditions/:condition_id", handler.RemoveCatConditionHandler)
+
+		// Bulk import/export (CSV or JSON)
+		admin.POST("/cats/import", handler.ImportCatsHandler)
+		admin.GET("/cats/export", handler.ExportCatsHandler)
+
+		// Trash (soft-deleted breeds)
+		admin.GET("/cats/trash", handler.GetDeletedCatsHandler)
+		admin.POST("/cats/:id/restore", handler.RestoreCatHandler)
+
+		// Engagement analytics (daily buckets)
+		admin.GET("/analytics/summary", handler.GetCatalogAnalyticsHandler)
+		admin.GET("/analytics/cats/:id", handler.GetCatAnalyticsHandler)
+		admin.POST("/analytics/rebuild", handler.RebuildAnalyticsHandler)
+
+		// Breed submission review
+		admin.GET("/submissions", handler.GetSubmissionsHandler)
+		admin.GET("/submissions/:submission_id", handler.GetSubmissionHandler)
+		admin.POST("/submissions/:submission_id/review", handler.ReviewSubmissionHandler)
+		admin.POST("/submissions/:submission_id/comments", handler.CommentOnSubmissionHandler)
+
+		// Breed matcher weights
+		admin.GET("/matcher/weights", handler.GetMatcherWeightsHandler)
+		admin.PUT("/matcher/weights", handler.UpdateMatcherWeightsHandler)
+
+		// Breed revision history
+		admin.GET("/cats/:id/revisions", handler.GetCatRevisionsHandler)
+		admin.GET("/cats/:id/revisions/diff", handler.GetCatRevisionDiffHandler)
+		admin.GET("/cats/:id/revisions/:rev", handler.GetCatRevisionHandler)
+		admin.POST("/cats/:id/revisions/:rev/rollback", handler.RollbackCatHandler)
 	}
 
 	
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"backgo/internal/infoDB"
+
+	"github.com/gin-gonic/gin"
+)
+
+const analyticsDefaultDays = 30
+
+// จำนวน bucket สูงสุดต่อ report (กัน generate_series ยาวเกินไป)
+const analyticsMaxBuckets = 400
+
+// ===================== Engagement Analytics Handlers =====================
+
+// GetCatAnalyticsHandler handles GET /api/admin/analytics/cats/:id?from=&to=&granularity= (Admin only)
+func GetCatAnalyticsHandler(c *gin.Context) {
+	catID, ok := catIDParam(c)
+	if !ok {
+		return
+	}
+
+	r, err := parseAnalyticsRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := infoDB.GetBreedAnalytics(catID, r)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "cat not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetCatalogAnalyticsHandler handles GET /api/admin/analytics/summary?from=&to=&granularity=&top= (Admin only)
+func GetCatalogAnalyticsHandler(c *gin.Context) {
+	r, err := parseAnalyticsRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	top, err := strconv.Atoi(c.DefaultQuery("top", "10"))
+	if err != nil || top < 0 || top > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "top must be between 0 and 100"})
+		return
+	}
+
+	report, err := infoDB.GetCatalogAnalytics(r, top)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// RebuildAnalyticsHandler handles POST /api/admin/analytics/rebuild (Admin only)
+// Recomputes reaction and discussion buckets from the source tables.
+func RebuildAnalyticsHandler(c *gin.Context) {
+	if err := infoDB.RebuildBreedDailyStats(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "analytics rebuilt"})
+}
+
+// parseAnalyticsRange reads from, to (YYYY-MM-DD, inclusive, UTC) and granularity.
+// The default is the last 30 days by day.
+func parseAnalyticsRange(c *gin.Context) (infoDB.AnalyticsRange, error) {
+	r := infoDB.AnalyticsRange{Granularity: c.DefaultQuery("granularity", "day")}
+
+	var step time.Duration
+	switch r.Granularity {
+	case "day":
+		step = 24 * time.Hour
+	case "week":
+		step = 7 * 24 * time.Hour
+	case "month":
+		step = 28 * 24 * time.Hour
+	default:
+		return r, fmt.Errorf("granularity must be day, week or month")
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	r.To = today
+	if to := c.Query("to"); to != "" {
+		t, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			return r, fmt.Errorf("to must be a date (YYYY-MM-DD)")
+		}
+		r.To = t
+	}
+
+	r.From = r.To.AddDate(0, 0, -(analyticsDefaultDays - 1))
+	if from := c.Query("from"); from != "" {
+		t, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			return r, fmt.Errorf("from must be a date (YYYY-MM-DD)")
+		}
+		r.From = t
+	}
+
+	if r.From.After(r.To) {
+		return r, fmt.Errorf("from must not be after to")
+	}
+	if r.To.Sub(r.From)/step >= analyticsMaxBuckets {
+		return r, fmt.Errorf("range is too long for granularity %s (max %d buckets)", r.Granularity, analyticsMaxBuckets)
+	}
+
+	return r, nil
+}
@@ -2,8 +2,12 @@ package handler
 
 import (
 	"database/sql"
+	"fmt"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
+	"strings"
 
 	"backgo/internal/infoDB"
 
@@ -24,23 +28,65 @@ func GetAllCatsHandler(c *gin.Context) {
 		currentUserID = &uid
 	}
 
-	cats, err := infoDB.GetAllCats(currentUserID, limit, offset)
+	filter, err := parseCatFilter(c)
 	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid filter", "details": err.Error()})
+		return
+	}
+
+	cats, err := infoDB.GetAllCats(currentUserID, filter, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	locale := requestLocale(c)
+	if err := infoDB.TranslateCats(cats, locale); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	c.Header("Content-Language", locale)
 	c.JSON(http.StatusOK, gin.H{
-		"data":  cats,
-		"count": len(cats),
+		"data":   cats,
+		"count":  len(cats),
+		"locale": locale,
 	})
 }
 
+// catIDParam resolves the :id route parameter, which may be a numeric ID or a slug.
+// A GET for the old slug of a renamed breed is redirected to its current slug.
+func catIDParam(c *gin.Context) (int, bool) {
+	param := c.Param("id")
+	if catID, err := strconv.Atoi(param); err == nil {
+		return catID, true
+	}
+
+	catID, currentSlug, err := infoDB.ResolveCatSlug(param)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "cat not found"})
+		return 0, false
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return 0, false
+	}
+
+	if currentSlug != param && c.Request.Method == http.MethodGet {
+		location := strings.Replace(c.FullPath(), ":id", url.PathEscape(currentSlug), 1)
+		if c.Request.URL.RawQuery != "" {
+			location += "?" + c.Request.URL.RawQuery
+		}
+		c.Redirect(http.StatusMovedPermanently, location)
+		return 0, false
+	}
+
+	return catID, true
+}
+
 // GetCatHandler handles GET /api/cats/:id
 func GetCatHandler(c *gin.Context) {
-	catID, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+	catID, ok := catIDParam(c)
+	if !ok {
 		return
 	}
 
@@ -60,9 +106,97 @@ func GetCatHandler(c *gin.Context) {
 		return
 	}
 
+	if err := infoDB.TranslateCat(&cat, requestLocale(c)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	recordCatView(c, cat.ID)
+
+	c.Header("Content-Language", cat.Locale)
 	c.JSON(http.StatusOK, cat)
 }
 
+// parseCatFilter reads the attribute filters of GET /api/cats from the query string
+func parseCatFilter(c *gin.Context) (infoDB.CatFilter, error) {
+	var filter infoDB.CatFilter
+	var err error
+
+	if coat := c.Query("coat_length"); coat != "" {
+		for _, value := range strings.Split(coat, ",") {
+			switch value = strings.TrimSpace(value); value {
+			case "hairless", "short", "medium", "long":
+				filter.CoatLengths = append(filter.CoatLengths, value)
+			default:
+				return filter, fmt.Errorf("coat_length: unknown value %q", value)
+			}
+		}
+	}
+
+	if value := c.Query("hypoallergenic"); value != "" {
+		hypoallergenic, err := strconv.ParseBool(value)
+		if err != nil {
+			return filter, fmt.Errorf("hypoallergenic: %q is not a boolean", value)
+		}
+		filter.Hypoallergenic = &hypoallergenic
+	}
+
+	levels := []struct {
+		param string
+		dest  **int
+	}{
+		{"min_shedding", &filter.MinShedding},
+		{"max_shedding", &filter.MaxShedding},
+		{"min_activity", &filter.MinActivity},
+		{"max_activity", &filter.MaxActivity},
+		{"min_grooming", &filter.MinGrooming},
+		{"max_grooming", &filter.MaxGrooming},
+		{"min_child_friendly", &filter.MinChildFriendly},
+		{"min_pet_friendly", &filter.MinPetFriendly},
+	}
+	for _, level := range levels {
+		if *level.dest, err = parseIntQuery(c, level.param, 1, 5); err != nil {
+			return filter, err
+		}
+	}
+
+	if filter.MinLifespanYears, err = parseIntQuery(c, "min_lifespan", 1, 40); err != nil {
+		return filter, err
+	}
+
+	if value := c.Query("max_weight"); value != "" {
+		weight, err := strconv.ParseFloat(value, 64)
+		if err != nil || weight <= 0 {
+			return filter, fmt.Errorf("max_weight: %q is not a positive number", value)
+		}
+		filter.MaxWeightKg = &weight
+	}
+
+	if temperament := c.Query("temperament"); temperament != "" {
+		for _, tag := range strings.Split(temperament, ",") {
+			if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+				filter.Temperament = append(filter.Temperament, tag)
+			}
+		}
+	}
+
+	return filter, nil
+}
+
+// parseIntQuery reads an optional integer query parameter within [min, max]
+func parseIntQuery(c *gin.Context, param string, min, max int) (*int, error) {
+	value := c.Query(param)
+	if value == "" {
+		return nil, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < min || n > max {
+		return nil, fmt.Errorf("%s: must be an integer between %d and %d", param, min, max)
+	}
+	return &n, nil
+}
+
 // CreateCatHandler handles POST /api/admin/cats (Admin only)
 func CreateCatHandler(c *gin.Context) {
     userIDVal, exists := c.Get("user_id")
@@ -81,8 +215,23 @@ func CreateCatHandler(c *gin.Context) {
         return
     }
 
+    req.Attributes.Normalize()
+    if err := req.Attributes.ValidateRanges(); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{
+            "error":   "invalid request body",
+            "details": err.Error(),
+        })
+        return
+    }
+
     cat, err := infoDB.CreateCat(userID, req)
-    if err != nil {
+    if err == infoDB.ErrSlugTaken {
+        c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+        return
+    } else if err == infoDB.ErrInvalidImageURL {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+        return
+    } else if err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
         return
     }
@@ -92,7 +241,7 @@ func CreateCatHandler(c *gin.Context) {
 
 // UpdateCatHandler handles PUT /api/admin/cats/:id (Admin only)
 func UpdateCatHandler(c *gin.Context) {
-	_, exists := c.Get("user_id")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
@@ -110,10 +259,22 @@ func UpdateCatHandler(c *gin.Context) {
 		return
 	}
 
-	cat, err := infoDB.UpdateCat(catID, req)
+	req.Attributes.Normalize()
+	if err := req.Attributes.ValidateRanges(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	cat, err := infoDB.UpdateCat(catID, userID.(int), req)
 	if err == sql.ErrNoRows {
 		c.JSON(http.StatusNotFound, gin.H{"error": "cat not found"})
 		return
+	} else if infoDB.IsRangeError(err) || err == infoDB.ErrInvalidImageURL {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	} else if err == infoDB.ErrSlugTaken {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
 	} else if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -124,7 +285,7 @@ func UpdateCatHandler(c *gin.Context) {
 
 // DeleteCatHandler handles DELETE /api/admin/cats/:id (Admin only)
 func DeleteCatHandler(c *gin.Context) {
-	_, exists := c.Get("user_id")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
@@ -136,7 +297,7 @@ func DeleteCatHandler(c *gin.Context) {
 		return
 	}
 
-	err = infoDB.DeleteCat(catID)
+	err = infoDB.DeleteCat(catID, userID.(int))
 	if err == sql.ErrNoRows {
 		c.JSON(http.StatusNotFound, gin.H{"error": "cat not found"})
 		return
@@ -145,13 +306,32 @@ func DeleteCatHandler(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "cat deleted successfully"})
+	c.JSON(http.StatusOK, gin.H{"message": "cat moved to trash"})
 }
 
-// ===================== Cat Reaction Handlers (Like/Dislike) =====================
+// GetDeletedCatsHandler handles GET /api/admin/cats/trash (Admin only)
+func GetDeletedCatsHandler(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset = max(offset, 0)
 
-// ToggleCatReactionHandler handles POST /api/cats/:id/react
-func ToggleCatReactionHandler(c *gin.Context) {
+	cats, err := infoDB.GetDeletedCats(limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  cats,
+		"count": len(cats),
+	})
+}
+
+// RestoreCatHandler handles POST /api/admin/cats/:id/restore (Admin only)
+func RestoreCatHandler(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
@@ -164,6 +344,33 @@ func ToggleCatReactionHandler(c *gin.Context) {
 		return
 	}
 
+	err = infoDB.RestoreCat(catID, userID.(int))
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "cat not found in trash"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "cat restored successfully"})
+}
+
+// ===================== Cat Reaction Handlers (Like/Dislike) =====================
+
+// ToggleCatReactionHandler handles POST /api/cats/:id/react
+func ToggleCatReactionHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	catID, ok := catIDParam(c)
+	if !ok {
+		return
+	}
+
 	var req struct {
 		ReactionType string `json:"reaction_type" binding:"required,oneof=like dislike"`
 	}
@@ -174,7 +381,10 @@ func ToggleCatReactionHandler(c *gin.Context) {
 	}
 
 	response, err := infoDB.ToggleCatReaction(catID, userID.(int), req.ReactionType)
-	if err != nil {
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "cat not found"})
+		return
+	} else if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -184,9 +394,8 @@ func ToggleCatReactionHandler(c *gin.Context) {
 
 // GetCatReactionStatsHandler handles GET /api/cats/:id/reactions
 func GetCatReactionStatsHandler(c *gin.Context) {
-	catID, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+	catID, ok := catIDParam(c)
+	if !ok {
 		return
 	}
 
@@ -207,16 +416,31 @@ func GetCatReactionStatsHandler(c *gin.Context) {
 
 // ===================== Discussion Handlers =====================
 
-// GetCatDiscussionsHandler handles GET /api/cats/:id/discussions
+// GetCatDiscussionsHandler handles GET /api/cats/:id/discussions[?sort=newest&cursor=...]
+// sort: newest, oldest, top, controversial or best. Pass next_cursor back as cursor for the next page.
 func GetCatDiscussionsHandler(c *gin.Context) {
-	catID, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+	catID, ok := catIDParam(c)
+	if !ok {
 		return
 	}
 
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	sort := c.DefaultQuery("sort", "newest")
+	if !slices.Contains(infoDB.DiscussionSorts, sort) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sort"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 100"})
+		return
+	}
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	cursor := c.Query("cursor")
+	if cursor != "" && offset != 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "use either cursor or offset"})
+		return
+	}
 
 	var currentUserID *int
 	if userID, exists := c.Get("user_id"); exists {
@@ -224,15 +448,25 @@ func GetCatDiscussionsHandler(c *gin.Context) {
 		currentUserID = &uid
 	}
 
-	discussions, err := infoDB.GetCatDiscussions(catID, currentUserID, limit, offset)
-	if err != nil {
+	discussions, nextCursor, err := infoDB.GetCatDiscussions(catID, currentUserID, sort, cursor, limit, offset)
+	if err == infoDB.ErrInvalidDiscussionCursor {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	var next *string
+	if nextCursor != "" {
+		next = &nextCursor
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"data":  discussions,
-		"count": len(discussions),
+		"data":        discussions,
+		"count":       len(discussions),
+		"sort":        sort,
+		"next_cursor": next,
 	})
 }
 
@@ -251,12 +485,18 @@ func CreateDiscussionHandler(c *gin.Context) {
 	}
 
 	discussion, err := infoDB.CreateDiscussion(userID.(int), req)
-	if err != nil {
+	switch err {
+	case nil:
+		c.JSON(http.StatusCreated, discussion)
+	case sql.ErrNoRows:
+		c.JSON(http.StatusNotFound, gin.H{"error": "cat not found"})
+	case infoDB.ErrDiscussionParentNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case infoDB.ErrDiscussionParentMismatch, infoDB.ErrDiscussionTooDeep:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
 	}
-
-	c.JSON(http.StatusCreated, discussion)
 }
 
 // UpdateDiscussionHandler handles PUT /api/discussions/:id
@@ -305,19 +545,7 @@ func DeleteDiscussionHandler(c *gin.Context) {
 		return
 	}
 
-	// Check if user is admin/moderator
-	roles, _ := c.Get("roles")
-	isAdmin := false
-	if roles != nil {
-		for _, role := range roles.([]string) {
-			if role == "admin" || role == "moderator" {
-				isAdmin = true
-				break
-			}
-		}
-	}
-
-	err = infoDB.DeleteDiscussion(discussionID, userID.(int), isAdmin)
+	err = infoDB.DeleteDiscussion(discussionID, userID.(int), isModerator(c))
 	if err == sql.ErrNoRows {
 		c.JSON(http.StatusNotFound, gin.H{"error": "discussion not found or you don't have permission"})
 		return
@@ -353,7 +581,10 @@ func ToggleDiscussionReactionHandler(c *gin.Context) {
 	}
 
 	response, err := infoDB.ToggleDiscussionReaction(discussionID, userID.(int), req.ReactionType)
-	if err != nil {
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "discussion not found"})
+		return
+	} else if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -0,0 +1,510 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"backgo/internal/infoDB"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+const maxImportBytes = 20 << 20
+const maxImportRows = 5000
+
+// catalogCSVColumns is the CSV layout of an export. An import accepts the same
+// header in any column order; the read-only columns are ignored.
+var catalogCSVColumns = []string{
+	"id", "slug", "name", "origin", "description", "care", "image_url",
+	"weight_min_kg", "weight_max_kg", "lifespan_min_years", "lifespan_max_years",
+	"coat_length", "shedding_level", "activity_level", "grooming_level",
+	"hypoallergenic", "child_friendly", "pet_friendly", "temperament",
+	"like_count", "dislike_count", "discussion_count", "view_count",
+	"created_at", "updated_at",
+}
+
+var catalogReadOnlyColumns = map[string]bool{
+	"id": true, "like_count": true, "dislike_count": true, "discussion_count": true,
+	"view_count": true, "created_at": true, "updated_at": true,
+}
+
+// temperament tags อยู่ใน cell เดียว คั่นด้วย |
+const temperamentSeparator = "|"
+
+// ===================== Catalog Import/Export Handlers =====================
+
+// ImportCatsHandler handles POST /api/admin/cats/import (Admin only)
+// The body is CSV or JSON (raw, or as the multipart field "file"); ?dry_run=true
+// validates and reports without saving.
+func ImportCatsHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid dry_run"})
+		return
+	}
+
+	body, format, ok := readImportBody(c)
+	if !ok {
+		return
+	}
+
+	var rows []infoDB.ImportRow
+	switch format {
+	case "csv":
+		rows, err = parseImportCSV(body)
+	case "json":
+		rows, err = parseImportJSON(body)
+	default:
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "import must be csv or json"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid import file", "details": err.Error()})
+		return
+	}
+
+	if len(rows) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "import file has no rows"})
+		return
+	}
+	if len(rows) > maxImportRows {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("import is limited to %d rows", maxImportRows)})
+		return
+	}
+
+	for i := range rows {
+		validateImportRow(&rows[i])
+	}
+
+	report, err := infoDB.ImportCats(userID.(int), rows, dryRun)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if report.Failed > 0 {
+		c.JSON(http.StatusUnprocessableEntity, report)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// ExportCatsHandler handles GET /api/admin/cats/export?format=json|csv (Admin only)
+func ExportCatsHandler(c *gin.Context) {
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or csv"})
+		return
+	}
+
+	filename := "cats-" + time.Now().Format("20060102") + "." + format
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+
+	var err error
+	if format == "csv" {
+		err = exportCatsCSV(c)
+	} else {
+		err = exportCatsJSON(c)
+	}
+
+	if err != nil {
+		// ถ้าเริ่มส่ง body ไปแล้วจะเปลี่ยน status ไม่ได้ ทำได้แค่ log
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Disposition")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		log.Println("Failed to export cats:", err)
+	}
+}
+
+// ===================== Import Parsing =====================
+
+// readImportBody reads the import from the request body or the multipart field "file",
+// and works out its format from ?format=, the file extension or the Content-Type.
+func readImportBody(c *gin.Context) ([]byte, string, bool) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes+1<<20)
+
+	format := strings.ToLower(c.Query("format"))
+	contentType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+
+	var reader io.Reader = c.Request.Body
+	if contentType == "multipart/form-data" {
+		file, header, err := c.Request.FormFile("file")
+		if err != nil {
+			if tooLarge(err) {
+				c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("import must be at most %d MB", maxImportBytes>>20)})
+				return nil, "", false
+			}
+			c.JSON(http.StatusBadRequest, gin.H{"error": "missing import file", "details": err.Error()})
+			return nil, "", false
+		}
+		defer file.Close()
+
+		reader = file
+		if format == "" {
+			format = strings.TrimPrefix(strings.ToLower(filepath.Ext(header.Filename)), ".")
+		}
+	}
+
+	if format == "" {
+		switch contentType {
+		case "text/csv", "application/csv":
+			format = "csv"
+		case "application/json":
+			format = "json"
+		}
+	}
+
+	data, err := io.ReadAll(io.LimitReader(reader, maxImportBytes+1))
+	if err != nil {
+		if tooLarge(err) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("import must be at most %d MB", maxImportBytes>>20)})
+			return nil, "", false
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read import file", "details": err.Error()})
+		return nil, "", false
+	}
+	if len(data) > maxImportBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("import must be at most %d MB", maxImportBytes>>20)})
+		return nil, "", false
+	}
+
+	return data, format, true
+}
+
+func tooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
+
+// parseImportCSV reads a CSV with a header row. Empty cells are treated as "not given".
+// Cell errors are collected per row; only a malformed file is returned as an error.
+func parseImportCSV(data []byte) ([]infoDB.ImportRow, error) {
+	reader := csv.NewReader(strings.NewReader(strings.TrimPrefix(string(data), "\ufeff")))
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	known := map[string]bool{}
+	for _, col := range catalogCSVColumns {
+		known[col] = true
+	}
+
+	columns := map[string]int{}
+	for i, col := range header {
+		col = strings.ToLower(strings.TrimSpace(col))
+		if !known[col] {
+			return nil, fmt.Errorf("unknown column %q", col)
+		}
+		if _, dup := columns[col]; dup {
+			return nil, fmt.Errorf("duplicate column %q", col)
+		}
+		columns[col] = i
+	}
+	if _, ok := columns["name"]; !ok {
+		return nil, errors.New(`missing column "name"`)
+	}
+
+	rows := []infoDB.ImportRow{}
+	for n := 1; ; n++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		row := infoDB.ImportRow{Row: n}
+		cell := func(col string) string {
+			if i, ok := columns[col]; ok && !catalogReadOnlyColumns[col] {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		fail := func(col string, err error) {
+			row.Errors = append(row.Errors, col+": "+err.Error())
+		}
+
+		row.Slug = cell("slug")
+		row.Cat = infoDB.CreateCatRequest{
+			Name:        cell("name"),
+			Origin:      cell("origin"),
+			Description: cell("description"),
+			Care:        cell("care"),
+			ImageURL:    cell("image_url"),
+		}
+
+		attrs := &row.Cat.Attributes
+		floatFields := []struct {
+			col  string
+			dest **float64
+		}{
+			{"weight_min_kg", &attrs.WeightMinKg},
+			{"weight_max_kg", &attrs.WeightMaxKg},
+		}
+		for _, f := range floatFields {
+			if v := cell(f.col); v != "" {
+				num, err := strconv.ParseFloat(v, 64)
+				if err != nil {
+					fail(f.col, errors.New("must be a number"))
+					continue
+				}
+				*f.dest = &num
+			}
+		}
+
+		intFields := []struct {
+			col  string
+			dest **int
+		}{
+			{"lifespan_min_years", &attrs.LifespanMinYears},
+			{"lifespan_max_years", &attrs.LifespanMaxYears},
+			{"shedding_level", &attrs.SheddingLevel},
+			{"activity_level", &attrs.ActivityLevel},
+			{"grooming_level", &attrs.GroomingLevel},
+			{"child_friendly", &attrs.ChildFriendly},
+			{"pet_friendly", &attrs.PetFriendly},
+		}
+		for _, f := range intFields {
+			if v := cell(f.col); v != "" {
+				num, err := strconv.Atoi(v)
+				if err != nil {
+					fail(f.col, errors.New("must be an integer"))
+					continue
+				}
+				*f.dest = &num
+			}
+		}
+		if v := cell("coat_length"); v != "" {
+			coat := strings.ToLower(v)
+			attrs.CoatLength = &coat
+		}
+		if v := cell("hypoallergenic"); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				fail("hypoallergenic", errors.New("must be true or false"))
+			} else {
+				attrs.Hypoallergenic = &b
+			}
+		}
+		if v := cell("temperament"); v != "" {
+			attrs.Temperament = strings.Split(v, temperamentSeparator)
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// importJSONRow is a CreateCatRequest with the optional slug used for matching,
+// and the cover image of an export
+type importJSONRow struct {
+	infoDB.CreateCatRequest
+	Slug  string             `json:"slug"`
+	Image *infoDB.BreedImage `json:"image"`
+}
+
+// parseImportJSON reads an array of breeds, either bare or as {"cats": [...]}.
+// Each element is decoded on its own so one bad element does not hide the others.
+func parseImportJSON(data []byte) ([]infoDB.ImportRow, error) {
+	var elements []json.RawMessage
+	if err := json.Unmarshal(data, &elements); err != nil {
+		var wrapped struct {
+			Cats []json.RawMessage `json:"cats"`
+		}
+		if err := json.Unmarshal(data, &wrapped); err != nil {
+			return nil, errors.New(`expected a JSON array or {"cats": [...]}`)
+		}
+		elements = wrapped.Cats
+	}
+
+	rows := make([]infoDB.ImportRow, 0, len(elements))
+	for i, element := range elements {
+		row := infoDB.ImportRow{Row: i + 1}
+
+		var item importJSONRow
+		if err := json.Unmarshal(element, &item); err != nil {
+			row.Errors = append(row.Errors, err.Error())
+		} else {
+			row.Cat = item.CreateCatRequest
+			row.Slug = strings.TrimSpace(item.Slug)
+
+			// breed ที่มีรูป cover: image_url มาจาก cover ไม่ได้มาจากไฟล์
+			if item.Image != nil {
+				row.Cat.ImageURL = ""
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// validateImportRow applies the same rules as CreateCatHandler to a parsed row
+func validateImportRow(row *infoDB.ImportRow) {
+	if len(row.Errors) > 0 {
+		return
+	}
+
+	if err := binding.Validator.ValidateStruct(&row.Cat); err != nil {
+		row.Errors = append(row.Errors, strings.Split(err.Error(), "\n")...)
+		return
+	}
+
+	row.Cat.Attributes.Normalize()
+	if err := row.Cat.Attributes.ValidateRanges(); err != nil {
+		row.Errors = append(row.Errors, err.Error())
+	}
+}
+
+// ===================== Export Writers =====================
+
+// exportCatsJSON streams the catalog as a JSON array, one breed at a time
+func exportCatsJSON(c *gin.Context) error {
+	first := true
+
+	err := infoDB.ExportCats(func(cat infoDB.Cat) error {
+		data, err := json.Marshal(cat)
+		if err != nil {
+			return err
+		}
+
+		prefix := ",\n"
+		if first {
+			c.Header("Content-Type", "application/json; charset=utf-8")
+			c.Status(http.StatusOK)
+			prefix = "[\n"
+			first = false
+		}
+
+		if _, err := c.Writer.WriteString(prefix); err != nil {
+			return err
+		}
+		_, err = c.Writer.Write(data)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if first {
+		c.Header("Content-Type", "application/json; charset=utf-8")
+		c.Status(http.StatusOK)
+		_, err = c.Writer.WriteString("[]\n")
+		return err
+	}
+	_, err = c.Writer.WriteString("\n]\n")
+	return err
+}
+
+// exportCatsCSV streams the catalog as CSV in the catalogCSVColumns layout
+func exportCatsCSV(c *gin.Context) error {
+	writer := csv.NewWriter(c.Writer)
+	headerWritten := false
+
+	writeHeader := func() error {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Status(http.StatusOK)
+		headerWritten = true
+		return writer.Write(catalogCSVColumns)
+	}
+
+	n := 0
+	err := infoDB.ExportCats(func(cat infoDB.Cat) error {
+		if !headerWritten {
+			if err := writeHeader(); err != nil {
+				return err
+			}
+		}
+
+		if err := writer.Write(catCSVRecord(cat)); err != nil {
+			return err
+		}
+
+		n++
+		if n%100 == 0 {
+			writer.Flush()
+			return writer.Error()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if !headerWritten {
+		if err := writeHeader(); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func catCSVRecord(cat infoDB.Cat) []string {
+	a := cat.Attributes
+
+	floatCell := func(v *float64) string {
+		if v == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*v, 'f', -1, 64)
+	}
+	intCell := func(v *int) string {
+		if v == nil {
+			return ""
+		}
+		return strconv.Itoa(*v)
+	}
+
+	coat := ""
+	if a.CoatLength != nil {
+		coat = *a.CoatLength
+	}
+	hypoallergenic := ""
+	if a.Hypoallergenic != nil {
+		hypoallergenic = strconv.FormatBool(*a.Hypoallergenic)
+	}
+
+	// image_url ของ breed ที่มีรูป cover มาจาก gallery จึงไม่ export (import กลับไม่ได้)
+	imageURL := cat.ImageURL
+	if cat.ImageID != nil {
+		imageURL = ""
+	}
+
+	return []string{
+		strconv.Itoa(cat.ID), cat.Slug, cat.Name, cat.Origin, cat.Description, cat.Care, imageURL,
+		floatCell(a.WeightMinKg), floatCell(a.WeightMaxKg), intCell(a.LifespanMinYears), intCell(a.LifespanMaxYears),
+		coat, intCell(a.SheddingLevel), intCell(a.ActivityLevel), intCell(a.GroomingLevel),
+		hypoallergenic, intCell(a.ChildFriendly), intCell(a.PetFriendly), strings.Join(a.Temperament, temperamentSeparator),
+		strconv.Itoa(cat.LikeCount), strconv.Itoa(cat.DislikeCount),
+		strconv.Itoa(cat.DiscussionCount), strconv.Itoa(cat.ViewCount),
+		cat.CreatedAt.Format(time.RFC3339), cat.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"backgo/internal/infoDB"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseImportJSONKeepsSlugAndCover(t *testing.T) {
+	data := []byte(`[
+		{"name": "Persian", "slug": " persian-longhair ", "image_url": "/media/cats/1/large.jpg",
+		 "image": {"variants": {"large": {"url": "/media/cats/1/large.jpg"}}}},
+		{"name": "Siamese", "image_url": "https://example.com/siamese.jpg"},
+		{"name": "Sphynx"}
+	]`)
+
+	rows, err := parseImportJSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("%d rows, want 3", len(rows))
+	}
+
+	if rows[0].Slug != "persian-longhair" {
+		t.Errorf("slug = %q, want persian-longhair", rows[0].Slug)
+	}
+	// image_url ของ breed ที่มีรูป cover ไม่เขียนทับ cover เดิม
+	if rows[0].Cat.ImageURL != "" {
+		t.Errorf("image_url = %q, want it dropped for a breed with a cover", rows[0].Cat.ImageURL)
+	}
+	if rows[1].Cat.ImageURL != "https://example.com/siamese.jpg" {
+		t.Errorf("image_url = %q, want https://example.com/siamese.jpg", rows[1].Cat.ImageURL)
+	}
+	if rows[2].Cat.ImageURL != "" || rows[2].Slug != "" {
+		t.Errorf("row without image or slug = %+v", rows[2])
+	}
+}
+
+func TestParseImportCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     string
+		wantErr string
+		check   func(t *testing.T, rows []infoDB.ImportRow)
+	}{
+		{
+			name: "header in any order with BOM, case and spaces",
+			csv:  "\ufeffTemperament , NAME,slug,weight_min_kg,like_count,id\nCalm|playful| curious ,Persian,persian,3.5,99,7\n",
+			check: func(t *testing.T, rows []infoDB.ImportRow) {
+				row := rows[0]
+				if row.Cat.Name != "Persian" || row.Slug != "persian" || len(row.Errors) > 0 {
+					t.Errorf("row = %+v", row)
+				}
+				if !slices.Equal(row.Cat.Attributes.Temperament, []string{"Calm", "playful", " curious"}) {
+					t.Errorf("temperament = %q, want split on |", row.Cat.Attributes.Temperament)
+				}
+				if w := row.Cat.Attributes.WeightMinKg; w == nil || *w != 3.5 {
+					t.Errorf("weight_min_kg = %v, want 3.5", w)
+				}
+			},
+		},
+		{
+			name: "empty cells are not given",
+			csv:  "name,origin,shedding_level,hypoallergenic,temperament\nSphynx,,,,\n",
+			check: func(t *testing.T, rows []infoDB.ImportRow) {
+				a := rows[0].Cat.Attributes
+				if a.SheddingLevel != nil || a.Hypoallergenic != nil || a.Temperament != nil || rows[0].Cat.Origin != "" {
+					t.Errorf("attributes = %+v, want all unset", a)
+				}
+			},
+		},
+		{
+			name: "cell errors are collected per row",
+			csv:  "name,weight_max_kg,lifespan_min_years,hypoallergenic\nA,heavy,old,maybe\nBengal,5,12,true\n",
+			check: func(t *testing.T, rows []infoDB.ImportRow) {
+				want := []string{"weight_max_kg: must be a number", "lifespan_min_years: must be an integer", "hypoallergenic: must be true or false"}
+				if !slices.Equal(rows[0].Errors, want) {
+					t.Errorf("errors = %q, want %q", rows[0].Errors, want)
+				}
+				if rows[0].Row != 1 || rows[1].Row != 2 || len(rows[1].Errors) > 0 {
+					t.Errorf("second row = %+v, want row 2 without errors", rows[1])
+				}
+			},
+		},
+		{name: "unknown column", csv: "name,colour\nA,red\n", wantErr: `unknown column "colour"`},
+		{name: "duplicate column", csv: "name,Name\nA,B\n", wantErr: `duplicate column "name"`},
+		{name: "missing name column", csv: "origin\nThailand\n", wantErr: `missing column "name"`},
+		{name: "wrong number of cells", csv: "name,origin\nA\n", wantErr: "wrong number of fields"},
+		{
+			name: "empty file",
+			csv:  "",
+			check: func(t *testing.T, rows []infoDB.ImportRow) {
+				if len(rows) != 0 {
+					t.Errorf("%d rows, want none", len(rows))
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := parseImportCSV([]byte(tt.csv))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, rows)
+		})
+	}
+}
+
+func TestValidateImportRow(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+	n := func(v int) *int { return &v }
+
+	tests := []struct {
+		name    string
+		row     infoDB.ImportRow
+		wantErr string // ส่วนหนึ่งของ error แรก ("" = ผ่าน)
+	}{
+		{"valid", infoDB.ImportRow{Cat: infoDB.CreateCatRequest{Name: "Persian"}}, ""},
+		{"name too short", infoDB.ImportRow{Cat: infoDB.CreateCatRequest{Name: "P"}}, "'Name' failed on the 'min' tag"},
+		{"missing name", infoDB.ImportRow{}, "'Name' failed on the 'required' tag"},
+		{"level out of range", infoDB.ImportRow{Cat: infoDB.CreateCatRequest{
+			Name: "Bengal", Attributes: infoDB.BreedAttributes{ActivityLevel: n(9)}}}, "'ActivityLevel' failed on the 'max' tag"},
+		{"inverted weight range", infoDB.ImportRow{Cat: infoDB.CreateCatRequest{
+			Name: "Bengal", Attributes: infoDB.BreedAttributes{WeightMinKg: f(6), WeightMaxKg: f(3)}}}, infoDB.ErrWeightRange.Error()},
+		{"image url not http", infoDB.ImportRow{Cat: infoDB.CreateCatRequest{
+			Name: "Bengal", ImageURL: "javascript:alert(1)"}}, "'ImageURL' failed on the 'http_url' tag"},
+		{"parse errors are kept as they are", infoDB.ImportRow{Errors: []string{"weight_min_kg: must be a number"}}, "weight_min_kg: must be a number"},
+	}
+
+	for _, tt := range tests {
+		row := tt.row
+		validateImportRow(&row)
+
+		if tt.wantErr == "" {
+			if len(row.Errors) > 0 {
+				t.Errorf("%s: unexpected errors %q", tt.name, row.Errors)
+			}
+			continue
+		}
+		if len(row.Errors) == 0 || !strings.Contains(row.Errors[0], tt.wantErr) {
+			t.Errorf("%s: errors = %q, want %q", tt.name, row.Errors, tt.wantErr)
+		}
+	}
+
+	// temperament ถูก normalize หลังผ่านการตรวจ
+	row := infoDB.ImportRow{Cat: infoDB.CreateCatRequest{Name: "Persian",
+		Attributes: infoDB.BreedAttributes{Temperament: []string{"Calm", " playful ", "calm"}}}}
+	validateImportRow(&row)
+	if !slices.Equal(row.Cat.Attributes.Temperament, []string{"calm", "playful"}) {
+		t.Errorf("temperament = %q, want [calm playful]", row.Cat.Attributes.Temperament)
+	}
+}
+
+func TestImportCatsHandlerReportsInvalidRows(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("user_id", 1)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/admin/cats/import?dry_run=true",
+		strings.NewReader("name,weight_min_kg\nP,1\nBengal,heavy\n"))
+	c.Request.Header.Set("Content-Type", "text/csv")
+
+	ImportCatsHandler(c)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want 422: %s", w.Code, w.Body)
+	}
+
+	var report struct {
+		DryRun    bool `json:"dry_run"`
+		Committed bool `json:"committed"`
+		Total     int  `json:"total"`
+		Created   int  `json:"created"`
+		Failed    int  `json:"failed"`
+		Rows      []struct {
+			Row    int      `json:"row"`
+			Name   string   `json:"name"`
+			Action string   `json:"action"`
+			Errors []string `json:"errors"`
+		} `json:"rows"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+
+	if !report.DryRun || report.Committed || report.Total != 2 || report.Failed != 2 || report.Created != 0 {
+		t.Errorf("report = %+v, want a dry run with 2 failed rows", report)
+	}
+	if len(report.Rows) != 2 || report.Rows[0].Row != 1 || report.Rows[1].Name != "Bengal" {
+		t.Fatalf("rows = %+v", report.Rows)
+	}
+	if report.Rows[0].Action != "" || len(report.Rows[0].Errors) == 0 {
+		t.Errorf("row 1 = %+v, want errors and no action", report.Rows[0])
+	}
+	if !slices.Equal(report.Rows[1].Errors, []string{"weight_min_kg: must be a number"}) {
+		t.Errorf("row 2 errors = %q", report.Rows[1].Errors)
+	}
+}
@@ -0,0 +1,365 @@
+package handler
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"backgo/internal/infoDB"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ===================== Favorite Handlers =====================
+
+// GetFavoritesHandler handles GET /api/me/favorites
+func GetFavoritesHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset = max(offset, 0)
+
+	favorites, err := infoDB.GetFavoriteCats(userID.(int), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	cats := make([]infoDB.Cat, len(favorites))
+	for i := range favorites {
+		cats[i] = favorites[i].Cat
+	}
+
+	locale := requestLocale(c)
+	if err := infoDB.TranslateCats(cats, locale); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for i := range favorites {
+		favorites[i].Cat = cats[i]
+	}
+
+	c.Header("Content-Language", locale)
+	c.JSON(http.StatusOK, gin.H{
+		"data":   favorites,
+		"count":  len(favorites),
+		"locale": locale,
+	})
+}
+
+// AddFavoriteHandler handles PUT /api/me/favorites/:id
+func AddFavoriteHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	catID, ok := catIDParam(c)
+	if !ok {
+		return
+	}
+
+	err := infoDB.AddFavorite(userID.(int), catID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "cat not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "added to favorites", "is_favorited": true})
+}
+
+// RemoveFavoriteHandler handles DELETE /api/me/favorites/:id
+func RemoveFavoriteHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	catID, ok := catIDParam(c)
+	if !ok {
+		return
+	}
+
+	err := infoDB.RemoveFavorite(userID.(int), catID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "cat is not in favorites"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "removed from favorites", "is_favorited": false})
+}
+
+// ===================== Collection Handlers =====================
+
+// GetPublicCollectionsHandler handles GET /api/collections
+func GetPublicCollectionsHandler(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset = max(offset, 0)
+
+	collections, err := infoDB.GetPublicCollections(limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  collections,
+		"count": len(collections),
+	})
+}
+
+// GetCollectionHandler handles GET /api/collections/:collection_id
+// Public collections are visible to everyone, others to their owner only.
+func GetCollectionHandler(c *gin.Context) {
+	collectionID, err := strconv.Atoi(c.Param("collection_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid collection id"})
+		return
+	}
+
+	var viewerID *int
+	if userID, exists := c.Get("user_id"); exists {
+		uid := userID.(int)
+		viewerID = &uid
+	}
+
+	collection, err := infoDB.GetCollection(collectionID, viewerID)
+	respondCollection(c, collection, err, http.StatusOK, "collection not found")
+}
+
+// GetSharedCollectionHandler handles GET /api/collections/shared/:token (unlisted or public)
+func GetSharedCollectionHandler(c *gin.Context) {
+	var viewerID *int
+	if userID, exists := c.Get("user_id"); exists {
+		uid := userID.(int)
+		viewerID = &uid
+	}
+
+	collection, err := infoDB.GetSharedCollection(c.Param("token"), viewerID)
+	respondCollection(c, collection, err, http.StatusOK, "collection not found")
+}
+
+// GetMyCollectionsHandler handles GET /api/me/collections
+func GetMyCollectionsHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	collections, err := infoDB.GetUserCollections(userID.(int))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  collections,
+		"count": len(collections),
+	})
+}
+
+// CreateCollectionHandler handles POST /api/me/collections
+func CreateCollectionHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req infoDB.CreateCollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	collection, err := infoDB.CreateCollection(userID.(int), req)
+	respondCollection(c, collection, err, http.StatusCreated, "collection not found")
+}
+
+// UpdateCollectionHandler handles PUT /api/me/collections/:collection_id
+func UpdateCollectionHandler(c *gin.Context) {
+	userID, collectionID, ok := ownCollectionParams(c)
+	if !ok {
+		return
+	}
+
+	var req infoDB.UpdateCollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	collection, err := infoDB.UpdateCollection(userID, collectionID, req)
+	respondCollection(c, collection, err, http.StatusOK, "collection not found")
+}
+
+// DeleteCollectionHandler handles DELETE /api/me/collections/:collection_id
+func DeleteCollectionHandler(c *gin.Context) {
+	userID, collectionID, ok := ownCollectionParams(c)
+	if !ok {
+		return
+	}
+
+	err := infoDB.DeleteCollection(userID, collectionID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "collection not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "collection deleted successfully"})
+}
+
+// SetCollectionItemHandler handles PUT /api/me/collections/:collection_id/items/:id
+// Adds the breed to the end of the collection, or updates its note.
+func SetCollectionItemHandler(c *gin.Context) {
+	userID, collectionID, ok := ownCollectionParams(c)
+	if !ok {
+		return
+	}
+
+	catID, ok := catIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req infoDB.CollectionItemRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "invalid request body",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	collection, err := infoDB.SetCollectionItem(userID, collectionID, catID, req.Note)
+	respondCollection(c, collection, err, http.StatusOK, "collection or cat not found")
+}
+
+// RemoveCollectionItemHandler handles DELETE /api/me/collections/:collection_id/items/:id
+func RemoveCollectionItemHandler(c *gin.Context) {
+	userID, collectionID, ok := ownCollectionParams(c)
+	if !ok {
+		return
+	}
+
+	catID, ok := catIDParam(c)
+	if !ok {
+		return
+	}
+
+	err := infoDB.RemoveCollectionItem(userID, collectionID, catID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "collection item not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "removed from collection"})
+}
+
+// ReorderCollectionItemsHandler handles PUT /api/me/collections/:collection_id/items/order
+func ReorderCollectionItemsHandler(c *gin.Context) {
+	userID, collectionID, ok := ownCollectionParams(c)
+	if !ok {
+		return
+	}
+
+	var req infoDB.ReorderCollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	collection, err := infoDB.ReorderCollectionItems(userID, collectionID, req.BreedIDs)
+	respondCollection(c, collection, err, http.StatusOK, "collection not found")
+}
+
+// ownCollectionParams reads the user and :collection_id of the /api/me/collections routes
+func ownCollectionParams(c *gin.Context) (int, int, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return 0, 0, false
+	}
+
+	collectionID, err := strconv.Atoi(c.Param("collection_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid collection id"})
+		return 0, 0, false
+	}
+
+	return userID.(int), collectionID, true
+}
+
+// respondCollection maps collection errors to responses and translates the breeds of a collection
+func respondCollection(c *gin.Context, collection infoDB.Collection, err error, status int, notFound string) {
+	switch err {
+	case nil:
+	case sql.ErrNoRows:
+		c.JSON(http.StatusNotFound, gin.H{"error": notFound})
+		return
+	case infoDB.ErrCollectionNameTaken:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case infoDB.ErrTooManyCollections, infoDB.ErrCollectionFull, infoDB.ErrCollectionSetMismatch:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	cats := make([]infoDB.Cat, len(collection.Items))
+	for i := range collection.Items {
+		cats[i] = collection.Items[i].Cat
+	}
+
+	locale := requestLocale(c)
+	if err := infoDB.TranslateCats(cats, locale); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for i := range collection.Items {
+		collection.Items[i].Cat = cats[i]
+	}
+
+	c.Header("Content-Language", locale)
+	c.JSON(status, collection)
+}
@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"backgo/internal/infoDB"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ===================== Breed Comparison Handlers =====================
+
+// CompareCatsHandler handles GET /api/cats/compare?ids=1,5[&format=csv]
+// ids are 2-4 breed IDs or slugs.
+func CompareCatsHandler(c *gin.Context) {
+	ids, err := parseCompareIDs(c.Query("ids"))
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "cat not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or csv"})
+		return
+	}
+
+	// Get current user ID if authenticated
+	var currentUserID *int
+	if userID, exists := c.Get("user_id"); exists {
+		uid := userID.(int)
+		currentUserID = &uid
+	}
+
+	comparison, err := infoDB.CompareCats(ids, currentUserID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "cat not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	cats := make([]infoDB.Cat, len(comparison.Breeds))
+	for i := range comparison.Breeds {
+		cats[i] = comparison.Breeds[i].Cat
+	}
+
+	locale := requestLocale(c)
+	if err := infoDB.TranslateCats(cats, locale); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for i := range comparison.Breeds {
+		comparison.Breeds[i].Cat = cats[i]
+	}
+
+	c.Header("Content-Language", locale)
+
+	if format == "csv" {
+		writeComparisonCSV(c, comparison)
+		return
+	}
+
+	c.JSON(http.StatusOK, comparison)
+}
+
+// parseCompareIDs resolves the comma-separated ids (IDs or slugs) of a comparison
+func parseCompareIDs(raw string) ([]int, error) {
+	seen := map[int]bool{}
+	ids := []int{}
+
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		id, err := strconv.Atoi(part)
+		if err != nil {
+			id, _, err = infoDB.ResolveCatSlug(part)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) < infoDB.MinCompareBreeds || len(ids) > infoDB.MaxCompareBreeds {
+		return nil, fmt.Errorf("ids must list %d to %d different breeds", infoDB.MinCompareBreeds, infoDB.MaxCompareBreeds)
+	}
+
+	return ids, nil
+}
+
+// writeComparisonCSV writes one row per field and one column per breed
+func writeComparisonCSV(c *gin.Context, comparison infoDB.BreedComparison) {
+	header := []string{"field"}
+	for _, b := range comparison.Breeds {
+		header = append(header, b.Cat.Name)
+	}
+	header = append(header, "differs")
+
+	records := [][]string{header}
+
+	slugs := []string{"slug"}
+	for _, b := range comparison.Breeds {
+		slugs = append(slugs, b.Cat.Slug)
+	}
+	records = append(records, append(slugs, ""))
+
+	for _, f := range comparison.Fields {
+		record := []string{f.Field}
+		for _, v := range f.Values {
+			record = append(record, comparisonCell(v))
+		}
+		records = append(records, append(record, strconv.FormatBool(f.Differs)))
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": "cat-comparison.csv"}))
+	c.Status(http.StatusOK)
+
+	writer := csv.NewWriter(c.Writer)
+	writer.WriteAll(records)
+}
+
+func comparisonCell(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []any:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = comparisonCell(item)
+		}
+		return strings.Join(parts, temperamentSeparator)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,258 @@
+package handler
+
+import (
+	"database/sql"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"backgo/internal/infoDB"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ===================== Health Condition Handlers (Public) =====================
+
+// GetConditionsHandler handles GET /api/conditions[?severity=severe]
+func GetConditionsHandler(c *gin.Context) {
+	severity := c.Query("severity")
+	if severity != "" && !slices.Contains(infoDB.ConditionSeverities, severity) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid severity"})
+		return
+	}
+
+	conditions, err := infoDB.GetConditions(severity)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  conditions,
+		"count": len(conditions),
+	})
+}
+
+// GetConditionHandler handles GET /api/conditions/:condition_id
+func GetConditionHandler(c *gin.Context) {
+	conditionID, ok := conditionIDParam(c)
+	if !ok {
+		return
+	}
+
+	condition, err := infoDB.GetCondition(conditionID)
+	respondCondition(c, condition, err, http.StatusOK)
+}
+
+// GetConditionCatsHandler handles GET /api/conditions/:condition_id/cats
+func GetConditionCatsHandler(c *gin.Context) {
+	conditionID, ok := conditionIDParam(c)
+	if !ok {
+		return
+	}
+
+	condition, err := infoDB.GetCondition(conditionID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "health condition not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Get current user ID if authenticated
+	var currentUserID *int
+	if userID, exists := c.Get("user_id"); exists {
+		uid := userID.(int)
+		currentUserID = &uid
+	}
+
+	breeds, err := infoDB.GetConditionCats(conditionID, currentUserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	cats := make([]infoDB.Cat, len(breeds))
+	for i := range breeds {
+		cats[i] = breeds[i].Cat
+	}
+
+	locale := requestLocale(c)
+	if err := infoDB.TranslateCats(cats, locale); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for i := range breeds {
+		breeds[i].Cat = cats[i]
+	}
+
+	c.Header("Content-Language", locale)
+	c.JSON(http.StatusOK, gin.H{
+		"condition": condition,
+		"data":      breeds,
+		"count":     len(breeds),
+		"locale":    locale,
+	})
+}
+
+// GetCatConditionsHandler handles GET /api/cats/:id/conditions
+func GetCatConditionsHandler(c *gin.Context) {
+	catID, ok := catIDParam(c)
+	if !ok {
+		return
+	}
+
+	conditions, err := infoDB.GetCatConditions(catID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "cat not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  conditions,
+		"count": len(conditions),
+	})
+}
+
+// ===================== Health Condition Handlers (Admin) =====================
+
+// CreateConditionHandler handles POST /api/admin/conditions (Admin only)
+func CreateConditionHandler(c *gin.Context) {
+	var req infoDB.CreateConditionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	condition, err := infoDB.CreateCondition(req)
+	respondCondition(c, condition, err, http.StatusCreated)
+}
+
+// UpdateConditionHandler handles PUT /api/admin/conditions/:condition_id (Admin only)
+func UpdateConditionHandler(c *gin.Context) {
+	conditionID, ok := conditionIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req infoDB.UpdateConditionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	condition, err := infoDB.UpdateCondition(conditionID, req)
+	respondCondition(c, condition, err, http.StatusOK)
+}
+
+// DeleteConditionHandler handles DELETE /api/admin/conditions/:condition_id (Admin only)
+func DeleteConditionHandler(c *gin.Context) {
+	conditionID, ok := conditionIDParam(c)
+	if !ok {
+		return
+	}
+
+	err := infoDB.DeleteCondition(conditionID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "health condition not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "health condition deleted successfully"})
+}
+
+// SetCatConditionHandler handles PUT /api/admin/cats/:id/conditions/:condition_id (Admin only)
+// Links the condition to the breed, or updates its prevalence and notes.
+func SetCatConditionHandler(c *gin.Context) {
+	catID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	conditionID, ok := conditionIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req infoDB.BreedConditionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	condition, err := infoDB.SetBreedCondition(catID, conditionID, req)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "cat or health condition not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, condition)
+}
+
+// RemoveCatConditionHandler handles DELETE /api/admin/cats/:id/conditions/:condition_id (Admin only)
+func RemoveCatConditionHandler(c *gin.Context) {
+	catID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	conditionID, ok := conditionIDParam(c)
+	if !ok {
+		return
+	}
+
+	err = infoDB.RemoveBreedCondition(catID, conditionID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "breed condition not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "health condition removed from breed"})
+}
+
+func conditionIDParam(c *gin.Context) (int, bool) {
+	conditionID, err := strconv.Atoi(c.Param("condition_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid condition id"})
+		return 0, false
+	}
+	return conditionID, true
+}
+
+// respondCondition maps health condition errors to responses
+func respondCondition(c *gin.Context, condition infoDB.HealthCondition, err error, status int) {
+	switch err {
+	case nil:
+		c.JSON(status, condition)
+	case sql.ErrNoRows:
+		c.JSON(http.StatusNotFound, gin.H{"error": "health condition not found"})
+	case infoDB.ErrConditionNameTaken:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
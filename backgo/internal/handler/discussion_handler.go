@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"backgo/internal/infoDB"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ===================== Discussion Thread Handlers =====================
+
+// GetDiscussionThreadHandler handles GET /api/discussions/:id/thread[?depth=3&limit=20]
+// Returns the discussion with its replies nested up to depth levels.
+func GetDiscussionThreadHandler(c *gin.Context) {
+	discussionID, depth, limit, ok := threadParams(c)
+	if !ok {
+		return
+	}
+
+	var currentUserID *int
+	if userID, exists := c.Get("user_id"); exists {
+		uid := userID.(int)
+		currentUserID = &uid
+	}
+
+	thread, err := infoDB.GetDiscussionThread(discussionID, currentUserID, depth, limit)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "discussion not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, thread)
+}
+
+// GetDiscussionRepliesHandler handles GET /api/discussions/:id/replies[?depth=3&limit=20&offset=0]
+// Loads more direct replies of any node in a thread.
+func GetDiscussionRepliesHandler(c *gin.Context) {
+	discussionID, depth, limit, ok := threadParams(c)
+	if !ok {
+		return
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid offset"})
+		return
+	}
+
+	var currentUserID *int
+	if userID, exists := c.Get("user_id"); exists {
+		uid := userID.(int)
+		currentUserID = &uid
+	}
+
+	replies, total, err := infoDB.GetDiscussionReplies(discussionID, currentUserID, depth, limit, offset)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "discussion not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":     replies,
+		"count":    len(replies),
+		"total":    total,
+		"has_more": offset+len(replies) < total,
+	})
+}
+
+// GetDiscussionHistoryHandler handles GET /api/discussions/:id/history
+// Admins only, unless edit history is configured to be public.
+func GetDiscussionHistoryHandler(c *gin.Context) {
+	discussionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid discussion id"})
+		return
+	}
+
+	moderator := isModerator(c)
+	if !moderator && !infoDB.PublicEditHistory() {
+		if _, exists := c.Get("user_id"); !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+		c.JSON(http.StatusForbidden, gin.H{"error": "edit history is only visible to admins"})
+		return
+	}
+
+	history, err := infoDB.GetDiscussionHistory(discussionID, moderator)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "discussion not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// GetMyMentionsHandler handles GET /api/me/mentions[?limit=20&offset=0]
+// Returns the discussions that mention the signed-in user, newest first.
+func GetMyMentionsHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset = max(offset, 0)
+
+	mentions, err := infoDB.GetUserMentions(userID.(int), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  mentions,
+		"count": len(mentions),
+	})
+}
+
+// isModerator reports whether the signed-in user may moderate discussions.
+// There is no separate moderator role; only admins qualify.
+func isModerator(c *gin.Context) bool {
+	roles, _ := c.Get("roles")
+	if roles == nil {
+		return false
+	}
+	for _, role := range roles.([]string) {
+		if role == "admin" {
+			return true
+		}
+	}
+	return false
+}
+
+// threadParams reads :id, depth and limit of the thread routes
+func threadParams(c *gin.Context) (int, int, int, bool) {
+	discussionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid discussion id"})
+		return 0, 0, 0, false
+	}
+
+	maxDepth := infoDB.DiscussionMaxDepth()
+	depth, err := strconv.Atoi(c.DefaultQuery("depth", strconv.Itoa(min(infoDB.DefaultReplyDepth, maxDepth))))
+	if err != nil || depth < 1 || depth > maxDepth {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "depth must be between 1 and " + strconv.Itoa(maxDepth)})
+		return 0, 0, 0, false
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(infoDB.DefaultReplyLimit)))
+	if err != nil || limit < 1 || limit > infoDB.MaxReplyLimit {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and " + strconv.Itoa(infoDB.MaxReplyLimit)})
+		return 0, 0, 0, false
+	}
+
+	return discussionID, depth, limit, true
+}
@@ -0,0 +1,313 @@
+package handler
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"backgo/internal/imaging"
+	"backgo/internal/infoDB"
+	"backgo/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+var blobStore storage.BlobStore
+var maxUploadBytes int64 = 10 << 20
+
+// SetImageStorage configures where uploaded images are stored and the maximum upload size
+func SetImageStorage(store storage.BlobStore, maxBytes int64) {
+	blobStore = store
+	maxUploadBytes = maxBytes
+}
+
+// deleteBlobs removes stored files, logging failures (the DB is already consistent)
+func deleteBlobs(c *gin.Context, keys []string) {
+	for _, key := range keys {
+		if err := blobStore.Delete(c.Request.Context(), key); err != nil {
+			log.Printf("Failed to delete blob %s: %v", key, err)
+		}
+	}
+}
+
+// readImageUpload reads the "image" multipart field, enforcing the upload size limit
+func readImageUpload(c *gin.Context) ([]byte, bool) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxUploadBytes+1<<20)
+
+	file, _, err := c.Request.FormFile("image")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("image must be at most %d MB", maxUploadBytes>>20)})
+			return nil, false
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing image file", "details": err.Error()})
+		return nil, false
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxUploadBytes+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read image", "details": err.Error()})
+		return nil, false
+	}
+	if int64(len(data)) > maxUploadBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("image must be at most %d MB", maxUploadBytes>>20)})
+		return nil, false
+	}
+
+	return data, true
+}
+
+// processAndStoreImage validates and resizes an uploaded image and writes every variant
+// to the blob store under breeds/<catID>/<random>/
+func processAndStoreImage(c *gin.Context, catID int, data []byte) (infoDB.NewBreedImage, []string, bool) {
+	processed, err := imaging.Process(data, imaging.DefaultVariants)
+	if errors.Is(err, imaging.ErrUnsupportedType) {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		return infoDB.NewBreedImage{}, nil, false
+	} else if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid image", "details": err.Error()})
+		return infoDB.NewBreedImage{}, nil, false
+	}
+
+	random := make([]byte, 8)
+	if _, err := rand.Read(random); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return infoDB.NewBreedImage{}, nil, false
+	}
+	prefix := fmt.Sprintf("breeds/%d/%s", catID, hex.EncodeToString(random))
+
+	img := infoDB.NewBreedImage{
+		SourceType: processed.SourceType,
+		Width:      processed.Width,
+		Height:     processed.Height,
+		Blurhash:   processed.Blurhash,
+		Variants:   map[string]infoDB.StoredImageVariant{},
+	}
+
+	var keys []string
+	for _, v := range processed.Variants {
+		key := prefix + "/" + v.Name + "." + v.Ext
+		if err := blobStore.Put(c.Request.Context(), key, v.Data, v.ContentType); err != nil {
+			deleteBlobs(c, keys)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return infoDB.NewBreedImage{}, nil, false
+		}
+		keys = append(keys, key)
+
+		img.Variants[v.Name] = infoDB.StoredImageVariant{
+			Key:         key,
+			Width:       v.Width,
+			Height:      v.Height,
+			ContentType: v.ContentType,
+		}
+	}
+
+	return img, keys, true
+}
+
+// ===================== Breed Gallery Handlers =====================
+
+// GetCatGalleryHandler handles GET /api/cats/:id/images
+func GetCatGalleryHandler(c *gin.Context) {
+	catID, ok := catIDParam(c)
+	if !ok {
+		return
+	}
+
+	gallery, err := infoDB.GetCatGallery(catID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  gallery,
+		"count": len(gallery),
+	})
+}
+
+// UploadCatImageHandler handles POST /api/admin/cats/:id/images (Admin only)
+// multipart fields: image (file), caption, alt_text, license, attribution, cover (bool)
+func UploadCatImageHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	catID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	data, ok := readImageUpload(c)
+	if !ok {
+		return
+	}
+
+	var meta infoDB.ImageMetadataRequest
+	if err := c.ShouldBind(&meta); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+	setCover, _ := strconv.ParseBool(c.PostForm("cover"))
+
+	img, keys, ok := processAndStoreImage(c, catID, data)
+	if !ok {
+		return
+	}
+	img.Metadata = meta
+
+	image, err := infoDB.AddCatImage(catID, userID.(int), img, setCover)
+	if err != nil {
+		deleteBlobs(c, keys)
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "cat not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, image)
+}
+
+// UpdateCatImageHandler handles PUT /api/admin/cats/:id/images/:image_id (Admin only)
+// Body: {"caption": "..."}; fields left out keep their value.
+func UpdateCatImageHandler(c *gin.Context) {
+	catID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	imageID, err := strconv.Atoi(c.Param("image_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid image id"})
+		return
+	}
+
+	var req infoDB.UpdateImageMetadataRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	image, err := infoDB.UpdateCatImage(catID, imageID, req)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "image not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, image)
+}
+
+// ReorderCatImagesHandler handles PUT /api/admin/cats/:id/images/order (Admin only)
+func ReorderCatImagesHandler(c *gin.Context) {
+	catID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req infoDB.ReorderImagesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	gallery, err := infoDB.ReorderCatImages(catID, req.ImageIDs)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "cat not found"})
+		return
+	} else if err == infoDB.ErrImageSetMismatch {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  gallery,
+		"count": len(gallery),
+	})
+}
+
+// SetCatCoverImageHandler handles PUT /api/admin/cats/:id/cover (Admin only)
+func SetCatCoverImageHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	catID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req infoDB.SetCoverImageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	image, err := infoDB.SetCatCoverImage(catID, req.ImageID, userID.(int))
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "image not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, image)
+}
+
+// DeleteCatImageHandler handles DELETE /api/admin/cats/:id/images/:image_id (Admin only)
+func DeleteCatImageHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	catID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	imageID, err := strconv.Atoi(c.Param("image_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid image id"})
+		return
+	}
+
+	keys, err := infoDB.DeleteCatImage(catID, imageID, userID.(int))
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "image not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	deleteBlobs(c, keys)
+
+	c.JSON(http.StatusOK, gin.H{"message": "image deleted successfully"})
+}
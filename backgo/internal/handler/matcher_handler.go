@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"backgo/internal/infoDB"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ===================== Breed Matcher Handlers =====================
+
+// GetMatcherQuestionsHandler handles GET /api/matcher/questions
+func GetMatcherQuestionsHandler(c *gin.Context) {
+	questions := infoDB.GetMatcherQuestions()
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  questions,
+		"count": len(questions),
+	})
+}
+
+// MatchCatsHandler handles POST /api/matcher[?limit=10]
+// The result is saved when the user is signed in.
+func MatchCatsHandler(c *gin.Context) {
+	var answers infoDB.MatcherAnswers
+	if err := c.ShouldBindJSON(&answers); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(infoDB.DefaultMatcherResults)))
+	if limit <= 0 || limit > infoDB.MaxMatcherResults {
+		limit = infoDB.DefaultMatcherResults
+	}
+
+	// Get current user ID if authenticated
+	var currentUserID *int
+	if userID, exists := c.Get("user_id"); exists {
+		uid := userID.(int)
+		currentUserID = &uid
+	}
+
+	result, err := infoDB.MatchCats(answers, currentUserID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	respondMatcherResult(c, result)
+}
+
+// GetMyMatcherResultsHandler handles GET /api/me/matcher/results
+func GetMyMatcherResultsHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset = max(offset, 0)
+
+	results, err := infoDB.GetUserMatcherResults(userID.(int), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  results,
+		"count": len(results),
+	})
+}
+
+// GetMyMatcherResultHandler handles GET /api/me/matcher/results/:result_id
+func GetMyMatcherResultHandler(c *gin.Context) {
+	userID, resultID, ok := ownMatcherResultParams(c)
+	if !ok {
+		return
+	}
+
+	result, err := infoDB.GetUserMatcherResult(userID, resultID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "matcher result not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	respondMatcherResult(c, result)
+}
+
+// DeleteMyMatcherResultHandler handles DELETE /api/me/matcher/results/:result_id
+func DeleteMyMatcherResultHandler(c *gin.Context) {
+	userID, resultID, ok := ownMatcherResultParams(c)
+	if !ok {
+		return
+	}
+
+	err := infoDB.DeleteUserMatcherResult(userID, resultID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "matcher result not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "matcher result deleted successfully"})
+}
+
+// GetMatcherWeightsHandler handles GET /api/admin/matcher/weights (Admin only)
+func GetMatcherWeightsHandler(c *gin.Context) {
+	weights, err := infoDB.GetMatcherWeights()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  weights,
+		"count": len(weights),
+	})
+}
+
+// UpdateMatcherWeightsHandler handles PUT /api/admin/matcher/weights (Admin only)
+func UpdateMatcherWeightsHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req infoDB.UpdateMatcherWeightsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	weights, err := infoDB.UpdateMatcherWeights(userID.(int), req)
+	if errors.Is(err, infoDB.ErrUnknownMatcherCriterion) || err == infoDB.ErrMatcherWeightsZero {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  weights,
+		"count": len(weights),
+	})
+}
+
+// ownMatcherResultParams reads the user and :result_id of the /api/me/matcher/results routes
+func ownMatcherResultParams(c *gin.Context) (int, int, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return 0, 0, false
+	}
+
+	resultID, err := strconv.Atoi(c.Param("result_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid result id"})
+		return 0, 0, false
+	}
+
+	return userID.(int), resultID, true
+}
+
+// respondMatcherResult translates the matched breeds and writes the result
+func respondMatcherResult(c *gin.Context, result infoDB.MatcherResult) {
+	cats := make([]infoDB.Cat, len(result.Matches))
+	for i := range result.Matches {
+		cats[i] = result.Matches[i].Cat
+	}
+
+	locale := requestLocale(c)
+	if err := infoDB.TranslateCats(cats, locale); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for i := range result.Matches {
+		result.Matches[i].Cat = cats[i]
+	}
+
+	c.Header("Content-Language", locale)
+	c.JSON(http.StatusOK, result)
+}
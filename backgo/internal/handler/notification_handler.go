@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"backgo/internal/infoDB"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ===================== Notification Handlers =====================
+
+// GetMyNotificationsHandler handles GET /api/me/notifications[?unread=true&limit=20&offset=0]
+// Returns the notifications with the unread counts per type.
+func GetMyNotificationsHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset = max(offset, 0)
+	unreadOnly := c.Query("unread") == "true"
+
+	notifications, err := infoDB.GetUserNotifications(userID.(int), unreadOnly, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	unread, err := infoDB.GetUnreadNotificationCounts(userID.(int))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":   notifications,
+		"count":  len(notifications),
+		"unread": unread,
+	})
+}
+
+// GetMyUnreadNotificationsHandler handles GET /api/me/notifications/unread-count
+// Lightweight endpoint for polling the notification badge.
+func GetMyUnreadNotificationsHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	unread, err := infoDB.GetUnreadNotificationCounts(userID.(int))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, unread)
+}
+
+// MarkNotificationReadHandler handles POST /api/me/notifications/:notification_id/read
+func MarkNotificationReadHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	notificationID, err := strconv.Atoi(c.Param("notification_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid notification id"})
+		return
+	}
+
+	err = infoDB.MarkNotificationRead(notificationID, userID.(int))
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "notification not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "notification marked as read"})
+}
+
+// MarkAllNotificationsReadHandler handles POST /api/me/notifications/read-all[?type=like]
+func MarkAllNotificationsReadHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	marked, err := infoDB.MarkAllNotificationsRead(userID.(int), c.Query("type"))
+	if err == infoDB.ErrInvalidNotificationType {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "types": infoDB.NotificationTypes})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"marked": marked})
+}
+
+// ===================== Notification Preference Handlers =====================
+
+// GetNotificationPreferencesHandler handles GET /api/me/notifications/preferences
+func GetNotificationPreferencesHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	preferences, err := infoDB.GetNotificationPreferences(userID.(int))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
+
+// UpdateNotificationPreferencesHandler handles PUT /api/me/notifications/preferences
+// Body: {"like": false, "reply": true}; types left out keep their setting.
+func UpdateNotificationPreferencesHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req map[string]bool
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	preferences, err := infoDB.UpdateNotificationPreferences(userID.(int), req)
+	if err == infoDB.ErrInvalidNotificationType {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "types": infoDB.NotificationTypes})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"backgo/internal/infoDB"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ===================== Breed Ranking Handlers =====================
+
+// GetTrendingCatsHandler handles GET /api/cats/trending
+func GetTrendingCatsHandler(c *gin.Context) {
+	respondRanking(c, infoDB.GetTrendingCats)
+}
+
+// GetTopCatsHandler handles GET /api/cats/top
+func GetTopCatsHandler(c *gin.Context) {
+	respondRanking(c, infoDB.GetTopCats)
+}
+
+func respondRanking(c *gin.Context, get func(*int, int, int) ([]infoDB.RankedCat, time.Time, error)) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 100 {
+		limit = 10
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	// Get current user ID if authenticated
+	var currentUserID *int
+	if userID, exists := c.Get("user_id"); exists {
+		uid := userID.(int)
+		currentUserID = &uid
+	}
+
+	ranked, computedAt, err := get(currentUserID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	cats := make([]infoDB.Cat, len(ranked))
+	for i := range ranked {
+		cats[i] = ranked[i].Cat
+	}
+
+	locale := requestLocale(c)
+	if err := infoDB.TranslateCats(cats, locale); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for i := range ranked {
+		ranked[i].Cat = cats[i]
+	}
+
+	c.Header("Content-Language", locale)
+	c.JSON(http.StatusOK, gin.H{
+		"data":        ranked,
+		"count":       len(ranked),
+		"locale":      locale,
+		"computed_at": computedAt,
+	})
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"backgo/internal/infoDB"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ===================== Related Breed Handlers =====================
+
+// GetRelatedCatsHandler handles GET /api/cats/:id/related
+func GetRelatedCatsHandler(c *gin.Context) {
+	catID, ok := catIDParam(c)
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "6"))
+	if limit <= 0 || limit > 20 {
+		limit = 6
+	}
+
+	// Get current user ID if authenticated
+	var currentUserID *int
+	if userID, exists := c.Get("user_id"); exists {
+		uid := userID.(int)
+		currentUserID = &uid
+	}
+
+	related, err := infoDB.GetRelatedCats(catID, currentUserID, limit)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "cat not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	cats := make([]infoDB.Cat, len(related))
+	for i := range related {
+		cats[i] = related[i].Cat
+	}
+
+	locale := requestLocale(c)
+	if err := infoDB.TranslateCats(cats, locale); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for i := range related {
+		related[i].Cat = cats[i]
+	}
+
+	c.Header("Content-Language", locale)
+	c.JSON(http.StatusOK, gin.H{
+		"data":   related,
+		"count":  len(related),
+		"locale": locale,
+	})
+}
+
+// GetRecommendationsHandler handles GET /api/me/recommendations
+func GetRecommendationsHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if limit <= 0 || limit > 50 {
+		limit = 10
+	}
+
+	recommended, source, err := infoDB.GetRecommendations(userID.(int), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	cats := make([]infoDB.Cat, len(recommended))
+	for i := range recommended {
+		cats[i] = recommended[i].Cat
+	}
+
+	locale := requestLocale(c)
+	if err := infoDB.TranslateCats(cats, locale); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for i := range recommended {
+		recommended[i].Cat = cats[i]
+	}
+
+	c.Header("Content-Language", locale)
+	c.JSON(http.StatusOK, gin.H{
+		"data":   recommended,
+		"count":  len(recommended),
+		"locale": locale,
+		"source": source,
+	})
+}
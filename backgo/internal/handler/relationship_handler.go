@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"backgo/internal/infoDB"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ===================== Breed Relationship Handlers =====================
+
+// GetCatLineageHandler handles GET /api/cats/:id/lineage[?depth=3]
+// Returns ancestors and descendants as a nodes/edges graph.
+func GetCatLineageHandler(c *gin.Context) {
+	catID, ok := catIDParam(c)
+	if !ok {
+		return
+	}
+
+	depth, err := strconv.Atoi(c.DefaultQuery("depth", strconv.Itoa(infoDB.DefaultLineageDepth)))
+	if err != nil || depth < 1 || depth > infoDB.MaxLineageDepth {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "depth must be between 1 and " + strconv.Itoa(infoDB.MaxLineageDepth)})
+		return
+	}
+
+	lineage, err := infoDB.GetCatLineage(catID, depth, requestLocale(c))
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "cat not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Language", lineage.Locale)
+	c.JSON(http.StatusOK, lineage)
+}
+
+// CreateCatRelationshipHandler handles POST /api/admin/cats/:id/relationships (Admin only)
+func CreateCatRelationshipHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	catID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req infoDB.CreateRelationshipRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	relationship, err := infoDB.CreateBreedRelationship(catID, userID.(int), req)
+	switch err {
+	case nil:
+		c.JSON(http.StatusCreated, relationship)
+	case sql.ErrNoRows:
+		c.JSON(http.StatusNotFound, gin.H{"error": "cat not found"})
+	case infoDB.ErrRelationshipSelf:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case infoDB.ErrRelationshipExists, infoDB.ErrRelationshipCycle:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// DeleteCatRelationshipHandler handles DELETE /api/admin/cats/:id/relationships/:relationship_id (Admin only)
+func DeleteCatRelationshipHandler(c *gin.Context) {
+	catID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	relationshipID, err := strconv.Atoi(c.Param("relationship_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid relationship id"})
+		return
+	}
+
+	err = infoDB.DeleteBreedRelationship(catID, relationshipID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "relationship not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "relationship deleted successfully"})
+}
@@ -0,0 +1,336 @@
+package handler
+
+import (
+	"database/sql"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"backgo/internal/infoDB"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ===================== Breed Submission Handlers (Users) =====================
+
+// GetMySubmissionsHandler handles GET /api/me/submissions[?status=pending]
+func GetMySubmissionsHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	uid := userID.(int)
+	respondSubmissions(c, &uid, c.Query("status"))
+}
+
+// CreateSubmissionHandler handles POST /api/me/submissions
+// Proposes a new breed, or an edit of an existing one when breed_id is given.
+func CreateSubmissionHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req infoDB.CreateSubmissionRequest
+	if !bindSubmissionProposal(c, &req, &req.Breed) {
+		return
+	}
+
+	submission, err := infoDB.CreateSubmission(userID.(int), req)
+	respondSubmission(c, submission, err, http.StatusCreated, "cat not found")
+}
+
+// GetMySubmissionHandler handles GET /api/me/submissions/:submission_id
+func GetMySubmissionHandler(c *gin.Context) {
+	userID, submissionID, ok := ownSubmissionParams(c)
+	if !ok {
+		return
+	}
+
+	submission, err := infoDB.GetSubmission(submissionID, &userID)
+	respondSubmission(c, submission, err, http.StatusOK, "submission not found")
+}
+
+// UpdateSubmissionHandler handles PUT /api/me/submissions/:submission_id
+// Only drafts and submissions sent back for changes can be edited.
+func UpdateSubmissionHandler(c *gin.Context) {
+	userID, submissionID, ok := ownSubmissionParams(c)
+	if !ok {
+		return
+	}
+
+	var req infoDB.UpdateSubmissionRequest
+	if !bindSubmissionProposal(c, &req, &req.Breed) {
+		return
+	}
+
+	submission, err := infoDB.UpdateSubmission(userID, submissionID, req)
+	respondSubmission(c, submission, err, http.StatusOK, "submission not found")
+}
+
+// SubmitSubmissionHandler handles POST /api/me/submissions/:submission_id/submit
+func SubmitSubmissionHandler(c *gin.Context) {
+	changeSubmissionStatus(c, infoDB.SubmitSubmission)
+}
+
+// WithdrawSubmissionHandler handles POST /api/me/submissions/:submission_id/withdraw
+func WithdrawSubmissionHandler(c *gin.Context) {
+	changeSubmissionStatus(c, infoDB.WithdrawSubmission)
+}
+
+// DeleteSubmissionHandler handles DELETE /api/me/submissions/:submission_id
+func DeleteSubmissionHandler(c *gin.Context) {
+	userID, submissionID, ok := ownSubmissionParams(c)
+	if !ok {
+		return
+	}
+
+	err := infoDB.DeleteSubmission(userID, submissionID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "submission not found"})
+		return
+	} else if err == infoDB.ErrSubmissionState {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "submission deleted successfully"})
+}
+
+// CommentOnMySubmissionHandler handles POST /api/me/submissions/:submission_id/comments
+func CommentOnMySubmissionHandler(c *gin.Context) {
+	userID, submissionID, ok := ownSubmissionParams(c)
+	if !ok {
+		return
+	}
+
+	commentOnSubmission(c, submissionID, userID, &userID)
+}
+
+// GetCatContributorsHandler handles GET /api/cats/:id/contributors
+func GetCatContributorsHandler(c *gin.Context) {
+	catID, ok := catIDParam(c)
+	if !ok {
+		return
+	}
+
+	contributors, err := infoDB.GetCatContributors(catID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  contributors,
+		"count": len(contributors),
+	})
+}
+
+// ===================== Breed Submission Handlers (Admin) =====================
+
+// GetSubmissionsHandler handles GET /api/admin/submissions[?status=pending|all] (Admin only)
+// Defaults to the review queue (pending, oldest first).
+func GetSubmissionsHandler(c *gin.Context) {
+	status := c.DefaultQuery("status", "pending")
+	if status == "all" {
+		status = ""
+	}
+
+	respondSubmissions(c, nil, status)
+}
+
+// GetSubmissionHandler handles GET /api/admin/submissions/:submission_id (Admin only)
+func GetSubmissionHandler(c *gin.Context) {
+	submissionID, ok := submissionIDParam(c)
+	if !ok {
+		return
+	}
+
+	submission, err := infoDB.GetSubmission(submissionID, nil)
+	respondSubmission(c, submission, err, http.StatusOK, "submission not found")
+}
+
+// ReviewSubmissionHandler handles POST /api/admin/submissions/:submission_id/review (Admin only)
+// Approving applies the proposal to the catalog, credited to the submitter.
+func ReviewSubmissionHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	submissionID, ok := submissionIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req infoDB.ReviewSubmissionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	submission, err := infoDB.ReviewSubmission(userID.(int), submissionID, req)
+	respondSubmission(c, submission, err, http.StatusOK, "submission not found")
+}
+
+// CommentOnSubmissionHandler handles POST /api/admin/submissions/:submission_id/comments (Admin only)
+func CommentOnSubmissionHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	submissionID, ok := submissionIDParam(c)
+	if !ok {
+		return
+	}
+
+	commentOnSubmission(c, submissionID, userID.(int), nil)
+}
+
+// ===================== Breed Submission Helpers =====================
+
+// bindSubmissionProposal binds the request body and validates the proposed breed like
+// UpdateCatHandler; the rest (name of new breeds, ranges of edits) is checked in infoDB
+func bindSubmissionProposal(c *gin.Context, req any, breed *infoDB.UpdateCatRequest) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request body",
+			"details": err.Error(),
+		})
+		return false
+	}
+
+	breed.Attributes.Normalize()
+	if err := breed.Attributes.ValidateRanges(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request body",
+			"details": err.Error(),
+		})
+		return false
+	}
+
+	return true
+}
+
+func changeSubmissionStatus(c *gin.Context, change func(int, int, string) (infoDB.BreedSubmission, error)) {
+	userID, submissionID, ok := ownSubmissionParams(c)
+	if !ok {
+		return
+	}
+
+	var req infoDB.SubmissionStatusRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "invalid request body",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	submission, err := change(userID, submissionID, req.Message)
+	respondSubmission(c, submission, err, http.StatusOK, "submission not found")
+}
+
+func commentOnSubmission(c *gin.Context, submissionID, userID int, ownerID *int) {
+	var req infoDB.SubmissionCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	comment, err := infoDB.CommentOnSubmission(submissionID, userID, ownerID, req.Message)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "submission not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, comment)
+}
+
+func respondSubmissions(c *gin.Context, ownerID *int, status string) {
+	if status != "" && !slices.Contains(infoDB.SubmissionStatuses, status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset = max(offset, 0)
+
+	submissions, err := infoDB.GetSubmissions(ownerID, status, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  submissions,
+		"count": len(submissions),
+	})
+}
+
+// respondSubmission maps submission errors to responses
+func respondSubmission(c *gin.Context, submission infoDB.BreedSubmission, err error, status int, notFound string) {
+	switch err {
+	case nil:
+		c.JSON(status, submission)
+	case sql.ErrNoRows:
+		c.JSON(http.StatusNotFound, gin.H{"error": notFound})
+	case infoDB.ErrSubmissionState, infoDB.ErrSubmissionBreedUnavailable, infoDB.ErrSlugTaken:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case infoDB.ErrTooManySubmissions, infoDB.ErrReviewCommentRequired:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case infoDB.ErrProposalNameRequired, infoDB.ErrWeightRange, infoDB.ErrLifespanRange, infoDB.ErrInvalidImageURL:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid proposal", "details": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// ownSubmissionParams reads the user and :submission_id of the /api/me/submissions routes
+func ownSubmissionParams(c *gin.Context) (int, int, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return 0, 0, false
+	}
+
+	submissionID, ok := submissionIDParam(c)
+	if !ok {
+		return 0, 0, false
+	}
+
+	return userID.(int), submissionID, true
+}
+
+func submissionIDParam(c *gin.Context) (int, bool) {
+	submissionID, err := strconv.Atoi(c.Param("submission_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid submission id"})
+		return 0, false
+	}
+	return submissionID, true
+}
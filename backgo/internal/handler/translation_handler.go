@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"backgo/internal/infoDB"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestLocale returns the locale negotiated by LocaleMiddleware
+func requestLocale(c *gin.Context) string {
+	if locale := c.GetString("locale"); locale != "" {
+		return locale
+	}
+	return infoDB.DefaultLocale()
+}
+
+// translationLocaleParam validates the :locale route parameter of the admin translation routes
+func translationLocaleParam(c *gin.Context) (string, bool) {
+	locale := strings.ToLower(c.Param("locale"))
+
+	if !infoDB.IsSupportedLocale(locale) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":             "unsupported locale",
+			"supported_locales": infoDB.SupportedLocales(),
+		})
+		return "", false
+	}
+
+	if locale == infoDB.DefaultLocale() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "default locale content is edited via PUT /api/admin/cats/:id"})
+		return "", false
+	}
+
+	return locale, true
+}
+
+// ===================== Breed Translation Handlers =====================
+
+// GetCatTranslationsHandler handles GET /api/admin/cats/:id/translations (Admin only)
+func GetCatTranslationsHandler(c *gin.Context) {
+	catID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	translations, err := infoDB.GetBreedTranslations(catID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":              translations,
+		"count":             len(translations),
+		"default_locale":    infoDB.DefaultLocale(),
+		"supported_locales": infoDB.SupportedLocales(),
+	})
+}
+
+// UpsertCatTranslationHandler handles PUT /api/admin/cats/:id/translations/:locale (Admin only)
+func UpsertCatTranslationHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	catID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	locale, ok := translationLocaleParam(c)
+	if !ok {
+		return
+	}
+
+	var req infoDB.UpsertTranslationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body", "details": err.Error()})
+		return
+	}
+
+	translation, err := infoDB.UpsertBreedTranslation(catID, locale, userID.(int), req)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "cat not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, translation)
+}
+
+// DeleteCatTranslationHandler handles DELETE /api/admin/cats/:id/translations/:locale (Admin only)
+func DeleteCatTranslationHandler(c *gin.Context) {
+	catID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	locale, ok := translationLocaleParam(c)
+	if !ok {
+		return
+	}
+
+	err = infoDB.DeleteBreedTranslation(catID, locale)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "translation not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "translation deleted successfully"})
+}
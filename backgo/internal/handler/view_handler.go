@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strconv"
+
+	"backgo/internal/infoDB"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ===================== Breed View Tracking =====================
+
+// botUserAgent matches crawlers, link previewers, monitoring and scripted clients
+var botUserAgent = regexp.MustCompile(`(?i)bot|crawl|spider|slurp|archiver|facebookexternalhit|embedly|preview|` +
+	`lighthouse|pingdom|uptime|monitor|headless|phantomjs|curl|wget|httpie|python-requests|python-urllib|` +
+	`go-http-client|okhttp|axios|node-fetch|java/|libwww|scrapy`)
+
+// isBotUserAgent reports whether a request comes from a known bot (or sends no User-Agent)
+func isBotUserAgent(userAgent string) bool {
+	return userAgent == "" || botUserAgent.MatchString(userAgent)
+}
+
+// viewerKey identifies the viewer for de-duplication: the user ID when signed in,
+// otherwise a hash of client IP and User-Agent (the raw IP is never kept).
+func viewerKey(c *gin.Context) string {
+	if userID, exists := c.Get("user_id"); exists {
+		return "u:" + strconv.Itoa(userID.(int))
+	}
+
+	sum := sha256.Sum256([]byte(c.ClientIP() + "|" + c.Request.UserAgent()))
+	return "a:" + hex.EncodeToString(sum[:16])
+}
+
+// recordCatView counts a view of a breed page, skipping bots
+func recordCatView(c *gin.Context, catID int) {
+	if isBotUserAgent(c.Request.UserAgent()) {
+		return
+	}
+	infoDB.RecordCatView(catID, viewerKey(c))
+}
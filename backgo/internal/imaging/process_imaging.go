@@ -0,0 +1,170 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/webp"
+)
+
+// ===================== Image Processing =====================
+
+var (
+	ErrUnsupportedType = errors.New("unsupported image type (allowed: jpeg, png, webp)")
+	ErrImageTooLarge   = errors.New("image dimensions too large")
+)
+
+// MaxPixels ป้องกัน decompression bomb (ไฟล์เล็กแต่ขนาดภาพใหญ่มาก)
+const MaxPixels = 40_000_000
+
+// VariantSpec describes one resized rendition. Square variants are center-cropped.
+type VariantSpec struct {
+	Name   string
+	Size   int // longest edge (or edge length when Square)
+	Square bool
+}
+
+// DefaultVariants are the renditions generated for every uploaded breed photo
+var DefaultVariants = []VariantSpec{
+	{Name: "thumb", Size: 160, Square: true},
+	{Name: "small", Size: 480},
+	{Name: "medium", Size: 960},
+	{Name: "large", Size: 1600},
+	{Name: "original", Size: 2560},
+}
+
+type Variant struct {
+	Name        string
+	Data        []byte
+	ContentType string
+	Ext         string
+	Width       int
+	Height      int
+}
+
+type Processed struct {
+	SourceType string // content type detected from the bytes, not the client header
+	Width      int
+	Height     int
+	Blurhash   string
+	Variants   []Variant
+}
+
+// DetectType sniffs the real content type of data and reports whether it is allowed
+func DetectType(data []byte) (string, bool) {
+	contentType := http.DetectContentType(data)
+	switch contentType {
+	case "image/jpeg", "image/png", "image/webp":
+		return contentType, true
+	}
+	return contentType, false
+}
+
+// Process validates and decodes an uploaded image, applies its EXIF orientation and
+// re-encodes it into the given variants. Re-encoding drops all metadata (EXIF, GPS, ...).
+func Process(data []byte, specs []VariantSpec) (Processed, error) {
+	contentType, ok := DetectType(data)
+	if !ok {
+		return Processed{}, ErrUnsupportedType
+	}
+
+	decodeConfig, decode := decoderFor(contentType)
+	config, err := decodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Processed{}, fmt.Errorf("invalid %s image: %w", contentType, err)
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > MaxPixels {
+		return Processed{}, ErrImageTooLarge
+	}
+
+	src, err := decode(bytes.NewReader(data))
+	if err != nil {
+		return Processed{}, fmt.Errorf("invalid %s image: %w", contentType, err)
+	}
+
+	if contentType == "image/jpeg" {
+		src = applyOrientation(src, jpegOrientation(data))
+	}
+
+	bounds := src.Bounds()
+	result := Processed{
+		SourceType: contentType,
+		Width:      bounds.Dx(),
+		Height:     bounds.Dy(),
+		Blurhash:   Blurhash(src, 4, 3),
+	}
+
+	// PNG ที่มี transparency คงเป็น PNG ส่วนอื่นแปลงเป็น JPEG
+	keepPNG := contentType == "image/png" && !isOpaque(src)
+
+	for _, spec := range specs {
+		resized := resize(src, spec)
+
+		var buf bytes.Buffer
+		variant := Variant{Name: spec.Name, Width: resized.Bounds().Dx(), Height: resized.Bounds().Dy()}
+		if keepPNG {
+			err = png.Encode(&buf, resized)
+			variant.ContentType, variant.Ext = "image/png", "png"
+		} else {
+			err = jpeg.Encode(&buf, resized, &jpeg.Options{Quality: 85})
+			variant.ContentType, variant.Ext = "image/jpeg", "jpg"
+		}
+		if err != nil {
+			return Processed{}, err
+		}
+
+		variant.Data = buf.Bytes()
+		result.Variants = append(result.Variants, variant)
+	}
+
+	return result, nil
+}
+
+func decoderFor(contentType string) (func(r *bytes.Reader) (image.Config, error), func(r *bytes.Reader) (image.Image, error)) {
+	switch contentType {
+	case "image/png":
+		return func(r *bytes.Reader) (image.Config, error) { return png.DecodeConfig(r) },
+			func(r *bytes.Reader) (image.Image, error) { return png.Decode(r) }
+	case "image/webp":
+		return func(r *bytes.Reader) (image.Config, error) { return webp.DecodeConfig(r) },
+			func(r *bytes.Reader) (image.Image, error) { return webp.Decode(r) }
+	default:
+		return func(r *bytes.Reader) (image.Config, error) { return jpeg.DecodeConfig(r) },
+			func(r *bytes.Reader) (image.Image, error) { return jpeg.Decode(r) }
+	}
+}
+
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return false
+}
+
+// resize scales src to fit spec without upscaling
+func resize(src image.Image, spec VariantSpec) image.Image {
+	bounds := src.Bounds()
+
+	if spec.Square {
+		side := min(bounds.Dx(), bounds.Dy())
+		x0 := bounds.Min.X + (bounds.Dx()-side)/2
+		y0 := bounds.Min.Y + (bounds.Dy()-side)/2
+		bounds = image.Rect(x0, y0, x0+side, y0+side)
+	}
+
+	w, h := bounds.Dx(), bounds.Dy()
+	if longest := max(w, h); longest > spec.Size {
+		w = max(1, w*spec.Size/longest)
+		h = max(1, h*spec.Size/longest)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Src, nil)
+	return dst
+}
@@ -0,0 +1,161 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func testImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{uint8(x * 255 / w), uint8(y * 255 / h), 128, 255})
+		}
+	}
+	return img
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// withEXIF inserts an APP1 EXIF segment with the given orientation (and some
+// text standing in for GPS/camera data) right after the JPEG SOI marker
+func withEXIF(data []byte, orientation uint16) []byte {
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08")
+	tiff = binary.BigEndian.AppendUint16(tiff, 1) // 1 entry
+	tiff = binary.BigEndian.AppendUint16(tiff, 0x0112)
+	tiff = binary.BigEndian.AppendUint16(tiff, 3) // SHORT
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0) // padding + next IFD
+	tiff = append(tiff, "GPS 13.7563N 100.5018E"...)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := []byte{0xFF, 0xE1}
+	app1 = binary.BigEndian.AppendUint16(app1, uint16(len(segment)+2))
+	app1 = append(app1, segment...)
+
+	out := append([]byte{}, data[:2]...)
+	out = append(out, app1...)
+	return append(out, data[2:]...)
+}
+
+func TestProcessRejectsNonImages(t *testing.T) {
+	var gifData bytes.Buffer
+	if err := gif.Encode(&gifData, testImage(8, 8), nil); err != nil {
+		t.Fatal(err)
+	}
+	jpegData := encodeJPEG(t, testImage(32, 32))
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"text", []byte("just some text, not a cat")},
+		{"html", []byte("<html><script>alert(1)</script></html>")},
+		{"svg", []byte(`<svg xmlns="http://www.w3.org/2000/svg" onload="alert(1)"></svg>`)},
+		{"pdf", []byte("%PDF-1.7\n%...")},
+		{"gif", gifData.Bytes()},
+		{"empty", nil},
+		{"truncated jpeg", jpegData[:len(jpegData)/3]},
+		{"jpeg header only", []byte{0xFF, 0xD8, 0xFF, 0xE0, 0x00, 0x10, 'J', 'F', 'I', 'F', 0}},
+	}
+
+	for _, tt := range tests {
+		if _, err := Process(tt.data, DefaultVariants); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+
+	if _, err := Process([]byte("plain text"), DefaultVariants); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("text: err = %v, want ErrUnsupportedType", err)
+	}
+}
+
+func TestProcessRejectsHugeDimensions(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, testImage(4, 4)); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	// แก้ขนาดใน IHDR เป็น 10000x10000 (ไฟล์ยังเล็กอยู่) แล้วคำนวณ CRC ใหม่
+	ihdr := data[8+8 : 8+8+13]
+	binary.BigEndian.PutUint32(ihdr[0:], 10000)
+	binary.BigEndian.PutUint32(ihdr[4:], 10000)
+	binary.BigEndian.PutUint32(data[8+8+13:], crc32.ChecksumIEEE(data[8+4:8+8+13]))
+
+	if _, err := Process(data, DefaultVariants); !errors.Is(err, ErrImageTooLarge) {
+		t.Errorf("err = %v, want ErrImageTooLarge", err)
+	}
+}
+
+func TestProcessStripsEXIF(t *testing.T) {
+	// 40x20 ที่มี orientation 6 (หมุน 90°) ต้องออกมาเป็น 20x40
+	data := withEXIF(encodeJPEG(t, testImage(40, 20)), 6)
+	if got := jpegOrientation(data); got != 6 {
+		t.Fatalf("test fixture orientation = %d, want 6", got)
+	}
+
+	processed, err := Process(data, DefaultVariants)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if processed.Width != 20 || processed.Height != 40 {
+		t.Errorf("size = %dx%d, want 20x40", processed.Width, processed.Height)
+	}
+	if len(processed.Variants) != len(DefaultVariants) {
+		t.Fatalf("%d variants, want %d", len(processed.Variants), len(DefaultVariants))
+	}
+
+	for _, v := range processed.Variants {
+		if bytes.Contains(v.Data, []byte("Exif")) || bytes.Contains(v.Data, []byte("GPS")) {
+			t.Errorf("variant %s still contains EXIF data", v.Name)
+		}
+		if jpegOrientation(v.Data) != 1 {
+			t.Errorf("variant %s has an orientation tag", v.Name)
+		}
+		if _, err := jpeg.Decode(bytes.NewReader(v.Data)); err != nil {
+			t.Errorf("variant %s: %v", v.Name, err)
+		}
+	}
+
+	thumb := processed.Variants[0]
+	if thumb.Width != thumb.Height {
+		t.Errorf("thumb is %dx%d, want square", thumb.Width, thumb.Height)
+	}
+}
+
+func TestProcessKeepsTransparentPNG(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 16, 16))
+	img.Set(1, 1, color.NRGBA{255, 0, 0, 128})
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+
+	processed, err := Process(buf.Bytes(), []VariantSpec{{Name: "small", Size: 480}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := processed.Variants[0]; v.ContentType != "image/png" || v.Width != 16 {
+		t.Errorf("variant = %s %dx%d, want image/png 16x16 (no upscaling)", v.ContentType, v.Width, v.Height)
+	}
+	if len(processed.Blurhash) != 28 { // 4x3 components
+		t.Errorf("blurhash %q has length %d, want 28", processed.Blurhash, len(processed.Blurhash))
+	}
+}
@@ -0,0 +1,228 @@
+package infoDB
+
+import (
+	"time"
+)
+
+// ===================== Engagement Analytics Models =====================
+
+// breed_daily_stats keeps one row per breed per day (UTC). Reactions and discussions
+// are maintained by triggers, views by the view recorder flush.
+
+type EngagementCounts struct {
+	Views       int `json:"views"`
+	Likes       int `json:"likes"`
+	Dislikes    int `json:"dislikes"`
+	Discussions int `json:"discussions"`
+}
+
+// EngagementBucket is the engagement of one day, week (starting Monday) or month
+type EngagementBucket struct {
+	Start string `json:"start"`
+	EngagementCounts
+}
+
+// AnalyticsRange selects the days (inclusive) and bucket size of a report
+type AnalyticsRange struct {
+	From        time.Time
+	To          time.Time
+	Granularity string // "day", "week" or "month"
+}
+
+type BreedAnalytics struct {
+	BreedID     int                `json:"breed_id"`
+	Name        string             `json:"name"`
+	Slug        string             `json:"slug"`
+	From        string             `json:"from"`
+	To          string             `json:"to"`
+	Granularity string             `json:"granularity"`
+	Buckets     []EngagementBucket `json:"buckets"`
+	Totals      EngagementCounts   `json:"totals"`
+}
+
+type BreedEngagement struct {
+	BreedID int    `json:"breed_id"`
+	Name    string `json:"name"`
+	Slug    string `json:"slug"`
+	EngagementCounts
+}
+
+type CatalogAnalytics struct {
+	From        string             `json:"from"`
+	To          string             `json:"to"`
+	Granularity string             `json:"granularity"`
+	Buckets     []EngagementBucket `json:"buckets"`
+	Totals      EngagementCounts   `json:"totals"`
+	TopBreeds   []BreedEngagement  `json:"top_breeds"`
+}
+
+const analyticsDateLayout = "2006-01-02"
+
+// ===================== Engagement Analytics Functions =====================
+
+// GetBreedAnalytics reports the engagement of one active breed over a date range
+func GetBreedAnalytics(catID int, r AnalyticsRange) (BreedAnalytics, error) {
+	report := BreedAnalytics{
+		BreedID:     catID,
+		From:        r.From.Format(analyticsDateLayout),
+		To:          r.To.Format(analyticsDateLayout),
+		Granularity: r.Granularity,
+	}
+
+	err := db.QueryRow(`
+		SELECT name, slug FROM cat_breeds WHERE id = $1 AND deleted_at IS NULL
+	`, catID).Scan(&report.Name, &report.Slug)
+	if err != nil {
+		return BreedAnalytics{}, err
+	}
+
+	report.Buckets, report.Totals, err = engagementBuckets(&catID, r)
+	if err != nil {
+		return BreedAnalytics{}, err
+	}
+
+	return report, nil
+}
+
+// GetCatalogAnalytics reports the engagement of all active breeds over a date range,
+// with the top breeds by views in that range
+func GetCatalogAnalytics(r AnalyticsRange, topLimit int) (CatalogAnalytics, error) {
+	report := CatalogAnalytics{
+		From:        r.From.Format(analyticsDateLayout),
+		To:          r.To.Format(analyticsDateLayout),
+		Granularity: r.Granularity,
+	}
+
+	var err error
+	report.Buckets, report.Totals, err = engagementBuckets(nil, r)
+	if err != nil {
+		return CatalogAnalytics{}, err
+	}
+
+	rows, err := db.Query(`
+		SELECT cb.id, cb.name, cb.slug,
+		       SUM(d.views), SUM(d.likes), SUM(d.dislikes), SUM(d.discussions)
+		FROM breed_daily_stats d
+		JOIN cat_breeds cb ON d.breed_id = cb.id AND cb.deleted_at IS NULL
+		WHERE d.day BETWEEN $1 AND $2
+		GROUP BY cb.id
+		ORDER BY SUM(d.views) DESC, SUM(d.likes) DESC, cb.id
+		LIMIT $3
+	`, report.From, report.To, topLimit)
+	if err != nil {
+		return CatalogAnalytics{}, err
+	}
+	defer rows.Close()
+
+	report.TopBreeds = []BreedEngagement{}
+	for rows.Next() {
+		var b BreedEngagement
+		err := rows.Scan(&b.BreedID, &b.Name, &b.Slug, &b.Views, &b.Likes, &b.Dislikes, &b.Discussions)
+		if err != nil {
+			return CatalogAnalytics{}, err
+		}
+		report.TopBreeds = append(report.TopBreeds, b)
+	}
+
+	return report, rows.Err()
+}
+
+// engagementBuckets sums the daily stats into buckets, including empty ones.
+// A nil catID sums over all active breeds.
+func engagementBuckets(catID *int, r AnalyticsRange) ([]EngagementBucket, EngagementCounts, error) {
+	breedFilter := `AND d.breed_id IN (SELECT id FROM cat_breeds WHERE deleted_at IS NULL)`
+	args := []any{r.Granularity, r.From.Format(analyticsDateLayout), r.To.Format(analyticsDateLayout)}
+	if catID != nil {
+		breedFilter = `AND d.breed_id = $4`
+		args = append(args, *catID)
+	}
+
+	rows, err := db.Query(`
+		SELECT s.start::date,
+		       COALESCE(SUM(d.views), 0), COALESCE(SUM(d.likes), 0),
+		       COALESCE(SUM(d.dislikes), 0), COALESCE(SUM(d.discussions), 0)
+		FROM generate_series(
+		         date_trunc($1, $2::date::timestamp), $3::date::timestamp, ('1 ' || $1)::interval
+		     ) AS s(start)
+		LEFT JOIN breed_daily_stats d
+		       ON date_trunc($1, d.day::timestamp) = s.start
+		      AND d.day BETWEEN $2::date AND $3::date
+		      `+breedFilter+`
+		GROUP BY s.start
+		ORDER BY s.start
+	`, args...)
+	if err != nil {
+		return nil, EngagementCounts{}, err
+	}
+	defer rows.Close()
+
+	buckets := []EngagementBucket{}
+	var totals EngagementCounts
+	for rows.Next() {
+		var start time.Time
+		var b EngagementBucket
+		if err := rows.Scan(&start, &b.Views, &b.Likes, &b.Dislikes, &b.Discussions); err != nil {
+			return nil, EngagementCounts{}, err
+		}
+		b.Start = start.Format(analyticsDateLayout)
+		buckets = append(buckets, b)
+
+		totals.Views += b.Views
+		totals.Likes += b.Likes
+		totals.Dislikes += b.Dislikes
+		totals.Discussions += b.Discussions
+	}
+
+	return buckets, totals, rows.Err()
+}
+
+// RebuildBreedDailyStats recomputes the likes, dislikes and discussions buckets from
+// breed_reactions and discussions (e.g. to backfill history). Views have no source
+// history and are kept as they are.
+func RebuildBreedDailyStats() error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// กัน trigger เขียนซ้อนระหว่างคำนวณใหม่
+	if _, err := tx.Exec(`LOCK TABLE breed_reactions, discussions IN SHARE MODE`); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE breed_daily_stats SET likes = 0, dislikes = 0, discussions = 0
+		WHERE likes <> 0 OR dislikes <> 0 OR discussions <> 0
+	`)
+	if err != nil {
+		return err
+	}
+
+	// reaction นับในวันที่ตั้งค่าปัจจุบัน (updated_at) เหมือนที่ trigger บันทึกไว้
+	_, err = tx.Exec(`
+		INSERT INTO breed_daily_stats (breed_id, day, likes, dislikes, discussions)
+		SELECT breed_id, day, SUM(likes), SUM(dislikes), SUM(discussions)
+		FROM (
+			SELECT breed_id,
+			       breed_stats_day(COALESCE(updated_at, created_at, CURRENT_TIMESTAMP)) AS day,
+			       (reaction_type = 'like')::int AS likes,
+			       (reaction_type = 'dislike')::int AS dislikes,
+			       0 AS discussions
+			FROM breed_reactions
+			UNION ALL
+			SELECT breed_id, breed_stats_day(COALESCE(created_at, CURRENT_TIMESTAMP)), 0, 0, 1
+			FROM discussions
+		) events
+		GROUP BY breed_id, day
+		ON CONFLICT (breed_id, day) DO UPDATE
+		SET likes = EXCLUDED.likes,
+		    dislikes = EXCLUDED.dislikes,
+		    discussions = EXCLUDED.discussions
+	`)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,184 @@
+package infoDB
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// ===================== Breed Attribute Models =====================
+
+// BreedAttributes are the structured, filterable properties of a breed.
+// A nil field means "unknown"; in update requests it means "leave unchanged".
+type BreedAttributes struct {
+	WeightMinKg      *float64 `json:"weight_min_kg" binding:"omitempty,gt=0,lte=50"`
+	WeightMaxKg      *float64 `json:"weight_max_kg" binding:"omitempty,gt=0,lte=50"`
+	LifespanMinYears *int     `json:"lifespan_min_years" binding:"omitempty,min=1,max=40"`
+	LifespanMaxYears *int     `json:"lifespan_max_years" binding:"omitempty,min=1,max=40"`
+	CoatLength       *string  `json:"coat_length" binding:"omitempty,oneof=hairless short medium long"`
+
+	// ระดับ 1-5 (1 = น้อยที่สุด, 5 = มากที่สุด)
+	SheddingLevel *int `json:"shedding_level" binding:"omitempty,min=1,max=5"`
+	ActivityLevel *int `json:"activity_level" binding:"omitempty,min=1,max=5"`
+	GroomingLevel *int `json:"grooming_level" binding:"omitempty,min=1,max=5"`
+	ChildFriendly *int `json:"child_friendly" binding:"omitempty,min=1,max=5"`
+	PetFriendly   *int `json:"pet_friendly" binding:"omitempty,min=1,max=5"`
+
+	Hypoallergenic *bool    `json:"hypoallergenic"`
+	Temperament    []string `json:"temperament" binding:"omitempty,max=20,dive,min=2,max=30"`
+}
+
+// CatFilter narrows GET /api/cats by breed attributes. Zero values mean "no filter".
+type CatFilter struct {
+	CoatLengths      []string
+	Hypoallergenic   *bool
+	MinShedding      *int
+	MaxShedding      *int
+	MinActivity      *int
+	MaxActivity      *int
+	MinGrooming      *int
+	MaxGrooming      *int
+	MinChildFriendly *int
+	MinPetFriendly   *int
+	MaxWeightKg      *float64
+	MinLifespanYears *int
+	// breed ต้องมี temperament ครบทุก tag ที่ระบุ
+	Temperament []string
+}
+
+// ===================== Breed Attribute Functions =====================
+
+// Normalize lowercases and de-duplicates temperament tags
+func (a *BreedAttributes) Normalize() {
+	if a.Temperament == nil {
+		return
+	}
+
+	seen := map[string]bool{}
+	tags := []string{}
+	for _, tag := range a.Temperament {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	a.Temperament = tags
+}
+
+var (
+	ErrWeightRange   = errors.New("weight_min_kg must not be greater than weight_max_kg")
+	ErrLifespanRange = errors.New("lifespan_min_years must not be greater than lifespan_max_years")
+)
+
+// IsRangeError reports whether err is one of the ValidateRanges errors
+func IsRangeError(err error) bool {
+	return errors.Is(err, ErrWeightRange) || errors.Is(err, ErrLifespanRange)
+}
+
+// ValidateRanges checks that min/max pairs given together are in order
+func (a BreedAttributes) ValidateRanges() error {
+	if a.WeightMinKg != nil && a.WeightMaxKg != nil && *a.WeightMinKg > *a.WeightMaxKg {
+		return ErrWeightRange
+	}
+	if a.LifespanMinYears != nil && a.LifespanMaxYears != nil && *a.LifespanMinYears > *a.LifespanMaxYears {
+		return ErrLifespanRange
+	}
+	return nil
+}
+
+// mergeRanges fills the min/max fields a partial update leaves out with the
+// stored values, the way updateCatTx merges them with COALESCE
+func (a BreedAttributes) mergeRanges(stored BreedAttributes) BreedAttributes {
+	merged := stored
+	if a.WeightMinKg != nil {
+		merged.WeightMinKg = a.WeightMinKg
+	}
+	if a.WeightMaxKg != nil {
+		merged.WeightMaxKg = a.WeightMaxKg
+	}
+	if a.LifespanMinYears != nil {
+		merged.LifespanMinYears = a.LifespanMinYears
+	}
+	if a.LifespanMaxYears != nil {
+		merged.LifespanMaxYears = a.LifespanMaxYears
+	}
+	return merged
+}
+
+// validateStoredRanges checks an update's ranges against the breed it is applied
+// to, so that {"weight_min_kg": 9} on a breed with max 6 is rejected instead of
+// failing the table CHECK. The row stays locked until tx ends.
+func validateStoredRanges(tx *sql.Tx, catID int, attrs BreedAttributes) error {
+	var stored BreedAttributes
+	err := tx.QueryRow(`
+		SELECT weight_min_kg, weight_max_kg, lifespan_min_years, lifespan_max_years
+		FROM cat_breeds
+		WHERE id = $1 AND deleted_at IS NULL
+		FOR UPDATE
+	`, catID).Scan(&stored.WeightMinKg, &stored.WeightMaxKg, &stored.LifespanMinYears, &stored.LifespanMaxYears)
+	if err != nil {
+		return err
+	}
+
+	return attrs.mergeRanges(stored).ValidateRanges()
+}
+
+// whereClause builds the extra "AND ..." conditions for the filter, appending its
+// parameters to args. Breeds with an unknown (NULL) value never match a filter on it.
+func (f CatFilter) whereClause(args *[]any) string {
+	var conds []string
+	add := func(cond string, value any) {
+		*args = append(*args, value)
+		conds = append(conds, strings.ReplaceAll(cond, "?", "$"+strconv.Itoa(len(*args))))
+	}
+
+	if len(f.CoatLengths) > 0 {
+		add("cb.coat_length::text = ANY(?)", pq.Array(f.CoatLengths))
+	}
+	if f.Hypoallergenic != nil {
+		add("cb.hypoallergenic = ?", *f.Hypoallergenic)
+	}
+	if f.MinShedding != nil {
+		add("cb.shedding_level >= ?", *f.MinShedding)
+	}
+	if f.MaxShedding != nil {
+		add("cb.shedding_level <= ?", *f.MaxShedding)
+	}
+	if f.MinActivity != nil {
+		add("cb.activity_level >= ?", *f.MinActivity)
+	}
+	if f.MaxActivity != nil {
+		add("cb.activity_level <= ?", *f.MaxActivity)
+	}
+	if f.MinGrooming != nil {
+		add("cb.grooming_level >= ?", *f.MinGrooming)
+	}
+	if f.MaxGrooming != nil {
+		add("cb.grooming_level <= ?", *f.MaxGrooming)
+	}
+	if f.MinChildFriendly != nil {
+		add("cb.child_friendly >= ?", *f.MinChildFriendly)
+	}
+	if f.MinPetFriendly != nil {
+		add("cb.pet_friendly >= ?", *f.MinPetFriendly)
+	}
+	if f.MaxWeightKg != nil {
+		add("cb.weight_max_kg <= ?", *f.MaxWeightKg)
+	}
+	if f.MinLifespanYears != nil {
+		add("cb.lifespan_max_years >= ?", *f.MinLifespanYears)
+	}
+	if len(f.Temperament) > 0 {
+		add("cb.temperament @> ?", pq.Array(f.Temperament))
+	}
+
+	if len(conds) == 0 {
+		return ""
+	}
+	return "\n\t\t  AND " + strings.Join(conds, "\n\t\t  AND ")
+}
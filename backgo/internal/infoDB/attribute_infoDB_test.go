@@ -0,0 +1,38 @@
+package infoDB
+
+import "testing"
+
+func TestMergeRangesPartialUpdate(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+	n := func(v int) *int { return &v }
+
+	stored := BreedAttributes{WeightMinKg: f(3), WeightMaxKg: f(6), LifespanMinYears: n(12), LifespanMaxYears: n(15)}
+
+	tests := []struct {
+		name   string
+		update BreedAttributes
+		want   error
+	}{
+		{"min above stored max", BreedAttributes{WeightMinKg: f(9)}, ErrWeightRange},
+		{"max below stored min", BreedAttributes{WeightMaxKg: f(2)}, ErrWeightRange},
+		{"lifespan min above stored max", BreedAttributes{LifespanMinYears: n(16)}, ErrLifespanRange},
+		{"both sides moved together", BreedAttributes{WeightMinKg: f(9), WeightMaxKg: f(10)}, nil},
+		{"within stored range", BreedAttributes{WeightMinKg: f(4), LifespanMaxYears: n(20)}, nil},
+		{"untouched", BreedAttributes{}, nil},
+	}
+
+	for _, tt := range tests {
+		// ตรวจทีละ request ผ่าน แต่ค่าที่ merge กับข้อมูลเดิมต้องไม่ผ่าน
+		if err := tt.update.ValidateRanges(); err != nil {
+			t.Fatalf("%s: request alone should pass, got %v", tt.name, err)
+		}
+		if got := tt.update.mergeRanges(stored).ValidateRanges(); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// breed ที่ยังไม่รู้ค่า (NULL) ไม่มีอะไรให้ชน
+	if err := (BreedAttributes{WeightMinKg: f(9)}).mergeRanges(BreedAttributes{}).ValidateRanges(); err != nil {
+		t.Errorf("unknown stored range: %v", err)
+	}
+}
@@ -3,6 +3,11 @@ package infoDB
 import (
 	"time"
 	"database/sql"
+	"strconv"
+
+	"backgo/internal/markdown"
+
+	"github.com/lib/pq"
 )
 
 // ===================== Cat Breed Models =====================
@@ -10,11 +15,24 @@ import (
 type Cat struct {
 	ID          int       `json:"id"`
 	Name        string    `json:"name"`
+	Slug        string    `json:"slug"`
 	Origin      string    `json:"origin"`
 	Description string    `json:"description"`
 	Care        string    `json:"care"`
 	ImageURL    string    `json:"image_url"`
 	
+	// รูป cover ที่ upload ผ่าน API (variant หลายขนาด + blurhash)
+	ImageID *int        `json:"-"`
+	Image   *BreedImage `json:"image,omitempty"`
+	// แกลเลอรีรูปทั้งหมด (เฉพาะ GET /cats/:id)
+	Gallery []BreedImage `json:"gallery,omitempty"`
+	
+	// Locale ของ name, description และ care ที่ส่งกลับไป
+	Locale string `json:"locale,omitempty"`
+	
+	// Structured attributes (size, coat, temperament, care levels)
+	Attributes BreedAttributes `json:"attributes"`
+	
 	// Engagement metrics
 	LikeCount       int `json:"like_count"`
 	DislikeCount    int `json:"dislike_count"`
@@ -23,26 +41,39 @@ type Cat struct {
 	
 	// User's interaction (if logged in)
 	UserReaction *string `json:"user_reaction,omitempty"` // "like" or "dislike"
+	IsFavorited  *bool   `json:"is_favorited,omitempty"`
 	
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 	CreatedBy *int      `json:"created_by,omitempty"`
 }
 
+// DeletedCat is a breed in the trash (admin only)
+type DeletedCat struct {
+	Cat
+	DeletedAt         time.Time `json:"deleted_at"`
+	DeletedBy         *int      `json:"deleted_by,omitempty"`
+	DeletedByUsername *string   `json:"deleted_by_username,omitempty"`
+}
+
 type CreateCatRequest struct {
-	Name        string `json:"name" binding:"required,min=2,max=255"`
-	Origin      string `json:"origin"`
-	Description string `json:"description"`
-	Care        string `json:"care"`
-	ImageURL    string `json:"image_url"`
+	Name        string          `json:"name" binding:"required,min=2,max=255"`
+	Origin      string          `json:"origin"`
+	Description string          `json:"description"`
+	Care        string          `json:"care"`
+	ImageURL    string          `json:"image_url" binding:"omitempty,http_url"`
+	Attributes  BreedAttributes `json:"attributes"`
 }
 
+// UpdateCatRequest is a partial update: empty strings and null attributes keep their value.
+// ImageURL is ignored while the breed has an uploaded cover image.
 type UpdateCatRequest struct {
-	Name        string `json:"name" binding:"min=2,max=255"`
-	Origin      string `json:"origin"`
-	Description string `json:"description"`
-	Care        string `json:"care"`
-	ImageURL    string `json:"image_url"`
+	Name        string          `json:"name" binding:"omitempty,min=2,max=255"`
+	Origin      string          `json:"origin"`
+	Description string          `json:"description"`
+	Care        string          `json:"care"`
+	ImageURL    string          `json:"image_url" binding:"omitempty,http_url"`
+	Attributes  BreedAttributes `json:"attributes"`
 }
 
 // ===================== Discussion Models =====================
@@ -52,18 +83,23 @@ type Discussion struct {
 	BreedID      int       `json:"breed_id"`
 	UserID       int       `json:"user_id"`
 	Username     string    `json:"username"`
-	Message      string    `json:"message"`
+	Message      string    `json:"message"`      // markdown source
+	MessageHTML  string    `json:"message_html"` // rendered and sanitized
 	ParentID     *int      `json:"parent_id,omitempty"`
+	Depth        int       `json:"depth"`
 	
 	LikeCount    int       `json:"like_count"`
 	DislikeCount int       `json:"dislike_count"`
 	ReplyCount   int       `json:"reply_count"`
 	
-	UserReaction *string      `json:"user_reaction,omitempty"`
-	IsDeleted    bool         `json:"is_deleted"`
-	CreatedAt    time.Time    `json:"created_at"`
-	UpdatedAt    time.Time    `json:"updated_at"`
-	Replies      []Discussion `json:"replies,omitempty"`
+	UserReaction *string         `json:"user_reaction,omitempty"`
+	IsDeleted    bool            `json:"is_deleted"`
+	EditedAt     *time.Time      `json:"edited_at"`
+	EditCount    int             `json:"edit_count"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+	Mentions     []MentionEntity `json:"mentions"`
+	Replies      []Discussion    `json:"replies,omitempty"`
 }
 
 type CreateDiscussionRequest struct {
@@ -90,24 +126,56 @@ func SetDB(d *sql.DB) {
 	db = d
 }
 
+// catColumns คือคอลัมน์ของ cat_breeds (alias cb) ตามลำดับที่ scanCat อ่าน
+const catColumns = `
+	cb.id, cb.name, cb.slug, cb.origin, cb.description, cb.care_instructions, cb.image_url,
+	cb.like_count, cb.dislike_count, cb.discussion_count, cb.view_count,
+	cb.created_at, cb.updated_at, cb.created_by,
+	cb.weight_min_kg, cb.weight_max_kg, cb.lifespan_min_years, cb.lifespan_max_years,
+	cb.coat_length, cb.shedding_level, cb.activity_level, cb.grooming_level,
+	cb.hypoallergenic, cb.child_friendly, cb.pet_friendly, cb.temperament,
+	cb.image_id`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanCat scans the catColumns of a row into cat, followed by any extra columns
+func scanCat(scanner rowScanner, cat *Cat, extra ...any) error {
+	attrs := &cat.Attributes
+	dest := []any{
+		&cat.ID, &cat.Name, &cat.Slug, &cat.Origin, &cat.Description,
+		&cat.Care, &cat.ImageURL,
+		&cat.LikeCount, &cat.DislikeCount, &cat.DiscussionCount, &cat.ViewCount,
+		&cat.CreatedAt, &cat.UpdatedAt, &cat.CreatedBy,
+		&attrs.WeightMinKg, &attrs.WeightMaxKg, &attrs.LifespanMinYears, &attrs.LifespanMaxYears,
+		&attrs.CoatLength, &attrs.SheddingLevel, &attrs.ActivityLevel, &attrs.GroomingLevel,
+		&attrs.Hypoallergenic, &attrs.ChildFriendly, &attrs.PetFriendly, pq.Array(&attrs.Temperament),
+		&cat.ImageID,
+	}
+
+	return scanner.Scan(append(dest, extra...)...)
+}
+
 // GET /cats
-func GetAllCats(currentUserID *int, limit, offset int) ([]Cat, error) {
+func GetAllCats(currentUserID *int, filter CatFilter, limit, offset int) ([]Cat, error) {
 	var userID int
 	if currentUserID != nil {
 		userID = *currentUserID
 	}
 
+	args := []any{userID}
+	where := filter.whereClause(&args)
+	args = append(args, limit, offset)
+
 	rows, err := db.Query(`
-		SELECT 
-			cb.id, cb.name, cb.origin, cb.description, cb.care_instructions, cb.image_url,
-			cb.like_count, cb.dislike_count, cb.discussion_count, cb.view_count,
-			cb.created_at, cb.updated_at, cb.created_by,
+		SELECT `+catColumns+`,
 			br.reaction_type as user_reaction
 		FROM cat_breeds cb
 		LEFT JOIN breed_reactions br ON cb.id = br.breed_id AND br.user_id = $1
+		WHERE cb.deleted_at IS NULL`+where+`
 		ORDER BY cb.created_at DESC
-		LIMIT $2 OFFSET $3
-	`, userID, limit, offset)
+		LIMIT $`+strconv.Itoa(len(args)-1)+` OFFSET $`+strconv.Itoa(len(args)), args...)
 
 	if err != nil {
 		return nil, err
@@ -117,31 +185,65 @@ func GetAllCats(currentUserID *int, limit, offset int) ([]Cat, error) {
 	var cats []Cat
 	for rows.Next() {
 		var cat Cat
-		var userReaction sql.NullString
-		var createdBy sql.NullInt64
-
-		err := rows.Scan(
-			&cat.ID, &cat.Name, &cat.Origin, &cat.Description,
-			&cat.Care, &cat.ImageURL,
-			&cat.LikeCount, &cat.DislikeCount, &cat.DiscussionCount, &cat.ViewCount,
-			&cat.CreatedAt, &cat.UpdatedAt, &createdBy,
-			&userReaction,
-		)
-		if err != nil {
+
+		if err := scanCat(rows, &cat, &cat.UserReaction); err != nil {
 			return nil, err
 		}
 
-		if userReaction.Valid {
-			cat.UserReaction = &userReaction.String
-		}
+		cats = append(cats, cat)
+	}
 
-		if createdBy.Valid {
-			cb := int(createdBy.Int64)
-			cat.CreatedBy = &cb
-		}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := attachCatImages(cats); err != nil {
+		return nil, err
+	}
+
+	return cats, nil
+}
 
+// GetCatsByIDs gets active breeds in the order of ids; missing or deleted ids are skipped
+func GetCatsByIDs(ids []int, currentUserID *int) ([]Cat, error) {
+	var userID int
+	if currentUserID != nil {
+		userID = *currentUserID
+	}
+
+	breedIDs := make([]int64, len(ids))
+	for i, id := range ids {
+		breedIDs[i] = int64(id)
+	}
+
+	rows, err := db.Query(`
+		SELECT `+catColumns+`,
+			br.reaction_type as user_reaction
+		FROM cat_breeds cb
+		LEFT JOIN breed_reactions br ON cb.id = br.breed_id AND br.user_id = $1
+		WHERE cb.id = ANY($2) AND cb.deleted_at IS NULL
+		ORDER BY array_position($2, cb.id)
+	`, userID, pq.Array(breedIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cats := []Cat{}
+	for rows.Next() {
+		var cat Cat
+		if err := scanCat(rows, &cat, &cat.UserReaction); err != nil {
+			return nil, err
+		}
 		cats = append(cats, cat)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := attachCatImages(cats); err != nil {
+		return nil, err
+	}
 
 	return cats, nil
 }
@@ -155,119 +257,192 @@ func GetCat(id int, currentUserID *int) (Cat, error) {
 	}
 
 	var cat Cat
-	var userReaction sql.NullString
-	var createdBy sql.NullInt64
+	var favorited bool
 
 	row := db.QueryRow(`
-		SELECT 
-			cb.id, cb.name, cb.origin, cb.description, cb.care_instructions, cb.image_url,
-			cb.like_count, cb.dislike_count, cb.discussion_count, cb.view_count,
-			cb.created_at, cb.updated_at, cb.created_by,
-			br.reaction_type as user_reaction
+		SELECT `+catColumns+`,
+			br.reaction_type as user_reaction,
+			EXISTS (SELECT 1 FROM user_favorites uf WHERE uf.user_id = $1 AND uf.breed_id = cb.id) as is_favorited
 		FROM cat_breeds cb
 		LEFT JOIN breed_reactions br ON cb.id = br.breed_id AND br.user_id = $1
-		WHERE cb.id = $2
+		WHERE cb.id = $2 AND cb.deleted_at IS NULL
 	`, userID, id)
 
-	err := row.Scan(
-		&cat.ID, &cat.Name, &cat.Origin, &cat.Description,
-		&cat.Care, &cat.ImageURL,
-		&cat.LikeCount, &cat.DislikeCount, &cat.DiscussionCount, &cat.ViewCount,
-		&cat.CreatedAt, &cat.UpdatedAt, &createdBy,
-		&userReaction,
-	)
+	if err := scanCat(row, &cat, &cat.UserReaction, &favorited); err != nil {
+		return Cat{}, err
+	}
+
+	if currentUserID != nil {
+		cat.IsFavorited = &favorited
+	}
 
+	gallery, err := GetCatGallery(cat.ID)
 	if err != nil {
 		return Cat{}, err
 	}
+	cat.Gallery = gallery
+	for i := range gallery {
+		if gallery[i].IsCover {
+			cat.Image = &gallery[i]
+		}
+	}
 
-	if userReaction.Valid {
-		cat.UserReaction = &userReaction.String
+	return cat, nil
+}
+
+// GREATE /cat
+func CreateCat(userID int, req CreateCatRequest) (Cat, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return Cat{}, err
 	}
+	defer tx.Rollback()
 
-	if createdBy.Valid {
-		cb := int(createdBy.Int64)
-		cat.CreatedBy = &cb
+	cat, err := createCatTx(tx, userID, req, "")
+	if err != nil {
+		return Cat{}, err
 	}
 
-	// Increment view count
-	db.Exec("UPDATE cat_breeds SET view_count = view_count + 1 WHERE id = $1", id)
+	if err := tx.Commit(); err != nil {
+		return Cat{}, err
+	}
 
 	return cat, nil
 }
 
-// GREATE /cat
-func CreateCat(userID int, req CreateCatRequest) (Cat, error) {
-	var cat Cat
-	var createdBy sql.NullInt64
+// createCatTx inserts a new breed and its first revision inside tx. The slug is
+// generated from the name unless slug asks for another one.
+func createCatTx(tx *sql.Tx, userID int, req CreateCatRequest, slug string) (Cat, error) {
+	if err := validateImageURL(req.ImageURL); err != nil {
+		return Cat{}, err
+	}
 
-	row := db.QueryRow(`
-		INSERT INTO cat_breeds (name, origin, description, care_instructions, image_url, created_by)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING id, name, origin, description, care_instructions, image_url,
-		          like_count, dislike_count, discussion_count, view_count,
-		          created_at, updated_at, created_by
-	`, req.Name, req.Origin, req.Description, req.Care, req.ImageURL, userID)
-
-	err := row.Scan(
-		&cat.ID, &cat.Name, &cat.Origin, &cat.Description,
-		&cat.Care, &cat.ImageURL,
-		&cat.LikeCount, &cat.DislikeCount, &cat.DiscussionCount, &cat.ViewCount,
-		&cat.CreatedAt, &cat.UpdatedAt, &createdBy,
+	if slug == "" {
+		slug = req.Name
+	}
+	slug, err := uniqueSlug(tx, slug, 0)
+	if err != nil {
+		return Cat{}, err
+	}
+
+	var cat Cat
+	attrs := req.Attributes
+
+	row := tx.QueryRow(`
+		INSERT INTO cat_breeds AS cb (
+			name, origin, description, care_instructions, image_url, created_by,
+			weight_min_kg, weight_max_kg, lifespan_min_years, lifespan_max_years,
+			coat_length, shedding_level, activity_level, grooming_level,
+			hypoallergenic, child_friendly, pet_friendly, temperament, slug
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14,
+		        COALESCE($15, FALSE), $16, $17, COALESCE($18::text[], '{}'), $19)
+		RETURNING `+catColumns,
+		req.Name, req.Origin, req.Description, req.Care, req.ImageURL, userID,
+		attrs.WeightMinKg, attrs.WeightMaxKg, attrs.LifespanMinYears, attrs.LifespanMaxYears,
+		attrs.CoatLength, attrs.SheddingLevel, attrs.ActivityLevel, attrs.GroomingLevel,
+		attrs.Hypoallergenic, attrs.ChildFriendly, attrs.PetFriendly, pq.Array(attrs.Temperament), slug,
 	)
 
+	if err := scanCat(row, &cat); err != nil {
+		return Cat{}, slugConflict(err)
+	}
+
+	if err := recordBreedRevision(tx, cat, "create", nil, userID); err != nil {
+		return Cat{}, err
+	}
+
+	return cat, nil
+}
+
+// UPDATE /cat
+// attribute ที่ไม่ได้ส่งมา (null) จะคงค่าเดิมไว้
+func UpdateCat(catID, userID int, req UpdateCatRequest) (Cat, error) {
+	tx, err := db.Begin()
 	if err != nil {
 		return Cat{}, err
 	}
+	defer tx.Rollback()
 
-	if createdBy.Valid {
-		cb := int(createdBy.Int64)
-		cat.CreatedBy = &cb
+	cat, err := updateCatTx(tx, catID, userID, req)
+	if err != nil {
+		return Cat{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Cat{}, err
 	}
 
 	return cat, nil
 }
 
-// UPDATE /cat
-func UpdateCat(catID int, req UpdateCatRequest) (Cat, error) {
+// updateCatTx applies a partial update and records the revision inside tx
+func updateCatTx(tx *sql.Tx, catID, userID int, req UpdateCatRequest) (Cat, error) {
 	var cat Cat
-	var createdBy sql.NullInt64
+	attrs := req.Attributes
 
-	row := db.QueryRow(`
-		UPDATE cat_breeds 
+	if err := validateImageURL(req.ImageURL); err != nil {
+		return Cat{}, err
+	}
+	if err := validateStoredRanges(tx, catID, attrs); err != nil {
+		return Cat{}, err
+	}
+
+	var previousName string
+	if err := tx.QueryRow(`SELECT name FROM cat_breeds WHERE id = $1`, catID).Scan(&previousName); err != nil {
+		return Cat{}, err
+	}
+
+	row := tx.QueryRow(`
+		UPDATE cat_breeds AS cb
 		SET name = COALESCE(NULLIF($1, ''), name),
 		    origin = COALESCE(NULLIF($2, ''), origin),
 		    description = COALESCE(NULLIF($3, ''), description),
 		    care_instructions = COALESCE(NULLIF($4, ''), care_instructions),
-		    image_url = COALESCE(NULLIF($5, ''), image_url),
+		    image_url = CASE WHEN image_id IS NULL THEN COALESCE(NULLIF($5, ''), image_url) ELSE image_url END,
+		    weight_min_kg = COALESCE($7, weight_min_kg),
+		    weight_max_kg = COALESCE($8, weight_max_kg),
+		    lifespan_min_years = COALESCE($9, lifespan_min_years),
+		    lifespan_max_years = COALESCE($10, lifespan_max_years),
+		    coat_length = COALESCE($11, coat_length),
+		    shedding_level = COALESCE($12, shedding_level),
+		    activity_level = COALESCE($13, activity_level),
+		    grooming_level = COALESCE($14, grooming_level),
+		    hypoallergenic = COALESCE($15, hypoallergenic),
+		    child_friendly = COALESCE($16, child_friendly),
+		    pet_friendly = COALESCE($17, pet_friendly),
+		    temperament = COALESCE($18, temperament),
 		    updated_at = CURRENT_TIMESTAMP
-		WHERE id = $6
-		RETURNING id, name, origin, description, care_instructions, image_url,
-		          like_count, dislike_count, discussion_count, view_count,
-		          created_at, updated_at, created_by
-	`, req.Name, req.Origin, req.Description, req.Care, req.ImageURL, catID)
-
-	err := row.Scan(
-		&cat.ID, &cat.Name, &cat.Origin, &cat.Description,
-		&cat.Care, &cat.ImageURL,
-		&cat.LikeCount, &cat.DislikeCount, &cat.DiscussionCount, &cat.ViewCount,
-		&cat.CreatedAt, &cat.UpdatedAt, &createdBy,
+		WHERE id = $6 AND deleted_at IS NULL
+		RETURNING `+catColumns,
+		req.Name, req.Origin, req.Description, req.Care, req.ImageURL, catID,
+		attrs.WeightMinKg, attrs.WeightMaxKg, attrs.LifespanMinYears, attrs.LifespanMaxYears,
+		attrs.CoatLength, attrs.SheddingLevel, attrs.ActivityLevel, attrs.GroomingLevel,
+		attrs.Hypoallergenic, attrs.ChildFriendly, attrs.PetFriendly, pq.Array(attrs.Temperament),
 	)
 
-	if err != nil {
+	if err := scanCat(row, &cat); err != nil {
 		return Cat{}, err
 	}
 
-	if createdBy.Valid {
-		cb := int(createdBy.Int64)
-		cat.CreatedBy = &cb
+	if err := syncCatSlug(tx, &cat, previousName); err != nil {
+		return Cat{}, err
+	}
+
+	if err := recordBreedRevision(tx, cat, "update", nil, userID); err != nil {
+		return Cat{}, err
 	}
 
 	return cat, nil
 }
 // DELETE /cat
-func DeleteCat(catID int) error {
-	result, err := db.Exec(`DELETE FROM cat_breeds WHERE id = $1`, catID)
+// ย้าย breed ไปถังขยะ (soft delete) reaction และ discussion ยังอยู่ครบจนกว่าจะถูก purge
+func DeleteCat(catID, userID int) error {
+	result, err := db.Exec(`
+		UPDATE cat_breeds
+		SET deleted_at = CURRENT_TIMESTAMP, deleted_by = $2
+		WHERE id = $1 AND deleted_at IS NULL
+	`, catID, userID)
 	if err != nil {
 		return err
 	}
@@ -279,6 +454,140 @@ func DeleteCat(catID int) error {
 
 	return nil
 }
+
+// GetDeletedCats lists breeds in the trash, most recently deleted first
+func GetDeletedCats(limit, offset int) ([]DeletedCat, error) {
+	rows, err := db.Query(`
+		SELECT `+catColumns+`,
+			cb.deleted_at, cb.deleted_by, u.username
+		FROM cat_breeds cb
+		LEFT JOIN users u ON cb.deleted_by = u.id
+		WHERE cb.deleted_at IS NOT NULL
+		ORDER BY cb.deleted_at DESC
+		LIMIT $1 OFFSET $2
+	`, limit, offset)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cats := []DeletedCat{}
+	for rows.Next() {
+		var cat DeletedCat
+
+		err := scanCat(rows, &cat.Cat, &cat.DeletedAt, &cat.DeletedBy, &cat.DeletedByUsername)
+		if err != nil {
+			return nil, err
+		}
+
+		cats = append(cats, cat)
+	}
+
+	return cats, rows.Err()
+}
+
+// RestoreCat moves a breed out of the trash. The restore is recorded in audit_logs
+// together with who deleted the breed and when, since the row itself forgets both.
+func RestoreCat(catID, userID int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		INSERT INTO audit_logs (user_id, action, resource, resource_id, details)
+		SELECT $2, 'restore', 'cat', id::text,
+		       jsonb_build_object('deleted_at', deleted_at, 'deleted_by', deleted_by)
+		FROM cat_breeds
+		WHERE id = $1 AND deleted_at IS NOT NULL
+	`, catID, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	result, err = tx.Exec(`
+		UPDATE cat_breeds
+		SET deleted_at = NULL, deleted_by = NULL
+		WHERE id = $1 AND deleted_at IS NOT NULL
+	`, catID)
+	if err != nil {
+		return err
+	}
+
+	// restore พร้อมกันสองครั้ง: ครั้งหลังไม่เจอแถวใน trash แล้ว
+	rows, _ = result.RowsAffected()
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return tx.Commit()
+}
+
+// PurgeDeletedCats permanently deletes breeds that have been in the trash longer than retention.
+// Reactions and discussions of the purged breeds are removed by ON DELETE CASCADE.
+// It returns the blob keys of the purged breeds' images so the caller can delete the files.
+func PurgeDeletedCats(retention time.Duration) (int64, []string, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, nil, err
+	}
+	defer tx.Rollback()
+
+	cutoff := time.Now().Add(-retention)
+
+	keys, err := deleteBreedImageRows(tx, `breed_id IN (
+		SELECT id FROM cat_breeds WHERE deleted_at IS NOT NULL AND deleted_at < $1
+	)`, cutoff)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	result, err := tx.Exec(`
+		DELETE FROM cat_breeds
+		WHERE deleted_at IS NOT NULL AND deleted_at < $1
+	`, cutoff)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return purged, keys, tx.Commit()
+}
+
+// isCatActive reports whether the breed exists and is not in the trash
+func isCatActive(catID int) (bool, error) {
+	var active bool
+	err := db.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM cat_breeds WHERE id = $1 AND deleted_at IS NULL)
+	`, catID).Scan(&active)
+
+	return active, err
+}
+
+// isDiscussionCatActive reports whether the discussion exists and its breed is not in the trash
+func isDiscussionCatActive(discussionID int) (bool, error) {
+	var active bool
+	err := db.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM discussions d
+			JOIN cat_breeds cb ON cb.id = d.breed_id AND cb.deleted_at IS NULL
+			WHERE d.id = $1
+		)
+	`, discussionID).Scan(&active)
+
+	return active, err
+}
 // ===================== Breed Reaction Functions =====================
 
 // ToggleCatReaction toggles like/dislike on a cat breed
@@ -287,8 +596,16 @@ func ToggleCatReaction(catID, userID int, reactionType string) (ReactionResponse
 		return ReactionResponse{}, sql.ErrNoRows
 	}
 
+	active, err := isCatActive(catID)
+	if err != nil {
+		return ReactionResponse{}, err
+	}
+	if !active {
+		return ReactionResponse{}, sql.ErrNoRows
+	}
+
 	var existingReaction sql.NullString
-	err := db.QueryRow(`
+	err = db.QueryRow(`
 		SELECT reaction_type 
 		FROM breed_reactions 
 		WHERE breed_id = $1 AND user_id = $2
@@ -336,7 +653,7 @@ func ToggleCatReaction(catID, userID int, reactionType string) (ReactionResponse
 			br.reaction_type
 		FROM cat_breeds cb
 		LEFT JOIN breed_reactions br ON cb.id = br.breed_id AND br.user_id = $1
-		WHERE cb.id = $2
+		WHERE cb.id = $2 AND cb.deleted_at IS NULL
 	`, userID, catID).Scan(
 		&response.LikeCount,
 		&response.DislikeCount,
@@ -367,7 +684,7 @@ func GetCatReactionStats(catID int, currentUserID *int) (ReactionResponse, error
 			br.reaction_type
 		FROM cat_breeds cb
 		LEFT JOIN breed_reactions br ON cb.id = br.breed_id AND br.user_id = $1
-		WHERE cb.id = $2
+		WHERE cb.id = $2 AND cb.deleted_at IS NULL
 	`, userID, catID).Scan(
 		&response.LikeCount,
 		&response.DislikeCount,
@@ -383,138 +700,133 @@ func GetCatReactionStats(catID int, currentUserID *int) (ReactionResponse, error
 
 // ===================== Discussion Functions =====================
 
-// GetCatDiscussions retrieves all discussions for a cat breed
-func GetCatDiscussions(catID int, currentUserID *int, limit, offset int) ([]Discussion, error) {
+// GetCatDiscussions retrieves a page of top-level discussions for a cat breed in
+// the given sort, each with its reply tree. Pages continue from cursor (or offset);
+// the returned cursor is empty on the last page.
+func GetCatDiscussions(catID int, currentUserID *int, sort, cursor string, limit, offset int) ([]Discussion, string, error) {
 	var userID int
 	if currentUserID != nil {
 		userID = *currentUserID
 	}
 
-	rows, err := db.Query(`
-		SELECT 
-			d.id, d.breed_id, d.user_id, u.username, d.parent_id,
-			d.message, d.like_count, d.dislike_count, d.reply_count,
-			d.is_deleted, d.created_at, d.updated_at,
-			dr.reaction_type as user_reaction
-		FROM discussions d
-		JOIN users u ON d.user_id = u.id
-		LEFT JOIN discussion_reactions dr ON d.id = dr.discussion_id AND dr.user_id = $1
-		WHERE d.breed_id = $2 AND d.parent_id IS NULL
-		ORDER BY d.created_at DESC
-		LIMIT $3 OFFSET $4
-	`, userID, catID, limit, offset)
-
-	if err != nil {
-		return nil, err
+	order, ok := discussionSortColumns[sort]
+	if !ok {
+		return nil, "", ErrInvalidDiscussionSort
+	}
+	direction, comparison := "DESC", "<"
+	if order.ascending {
+		direction, comparison = "ASC", ">"
 	}
-	defer rows.Close()
 
-	var discussions []Discussion
-	for rows.Next() {
-		var discussion Discussion
-		var parentID sql.NullInt64
-		var userReaction sql.NullString
-
-		err := rows.Scan(
-			&discussion.ID, &discussion.BreedID, &discussion.UserID, &discussion.Username,
-			&parentID, &discussion.Message, &discussion.LikeCount, &discussion.DislikeCount,
-			&discussion.ReplyCount, &discussion.IsDeleted, &discussion.CreatedAt, &discussion.UpdatedAt,
-			&userReaction,
-		)
+	// ดึงเกิน 1 แถวเพื่อรู้ว่ามีหน้าถัดไปไหม
+	args := []any{userID, catID, limit + 1, offset}
+	keyset := ""
+	if cursor != "" {
+		key, id, err := parseDiscussionCursor(sort, cursor)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
-
-		if parentID.Valid {
-			pid := int(parentID.Int64)
-			discussion.ParentID = &pid
+		if err := validateCursorKey(order.cast, key); err != nil {
+			return nil, "", err
 		}
-
-		if userReaction.Valid {
-			discussion.UserReaction = &userReaction.String
-		}
-
-		// Get replies
-		replies, _ := GetDiscussionReplies(discussion.ID, currentUserID, 100, 0)
-		discussion.Replies = replies
-
-		discussions = append(discussions, discussion)
-	}
-
-	return discussions, nil
-}
-
-// GetDiscussionReplies gets replies to a discussion
-func GetDiscussionReplies(parentID int, currentUserID *int, limit, offset int) ([]Discussion, error) {
-	var userID int
-	if currentUserID != nil {
-		userID = *currentUserID
+		keyset = `
+		  AND (` + order.column + `, d.id) ` + comparison + ` ($5::` + order.cast + `, $6)`
+		args = append(args, key, id)
 	}
 
 	rows, err := db.Query(`
-		SELECT 
-			d.id, d.breed_id, d.user_id, u.username, d.parent_id,
-			d.message, d.like_count, d.dislike_count, d.reply_count,
-			d.is_deleted, d.created_at, d.updated_at,
-			dr.reaction_type as user_reaction
+		SELECT `+discussionColumns+`, `+order.column+` AS sort_key
 		FROM discussions d
 		JOIN users u ON d.user_id = u.id
 		LEFT JOIN discussion_reactions dr ON d.id = dr.discussion_id AND dr.user_id = $1
-		WHERE d.parent_id = $2
-		ORDER BY d.created_at ASC
+		JOIN cat_breeds cb ON d.breed_id = cb.id AND cb.deleted_at IS NULL
+		WHERE d.breed_id = $2 AND d.parent_id IS NULL`+keyset+`
+		ORDER BY `+order.column+` `+direction+`, d.id `+direction+`
 		LIMIT $3 OFFSET $4
-	`, userID, parentID, limit, offset)
+	`, args...)
 
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer rows.Close()
 
 	var discussions []Discussion
+	var ids []int
+	var keys []any
 	for rows.Next() {
 		var discussion Discussion
-		var parentIDVal sql.NullInt64
-		var userReaction sql.NullString
-
-		err := rows.Scan(
-			&discussion.ID, &discussion.BreedID, &discussion.UserID, &discussion.Username,
-			&parentIDVal, &discussion.Message, &discussion.LikeCount, &discussion.DislikeCount,
-			&discussion.ReplyCount, &discussion.IsDeleted, &discussion.CreatedAt, &discussion.UpdatedAt,
-			&userReaction,
-		)
-		if err != nil {
-			return nil, err
+		var key any
+		if err := scanDiscussion(rows, &discussion, &key); err != nil {
+			return nil, "", err
 		}
+		discussions = append(discussions, discussion)
+		ids = append(ids, discussion.ID)
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+	rows.Close()
 
-		if parentIDVal.Valid {
-			pid := int(parentIDVal.Int64)
-			discussion.ParentID = &pid
-		}
+	nextCursor := ""
+	if len(discussions) > limit {
+		discussions, ids = discussions[:limit], ids[:limit]
+		nextCursor = discussionCursor(sort, formatCursorKey(keys[limit-1]), ids[limit-1])
+	}
 
-		if userReaction.Valid {
-			discussion.UserReaction = &userReaction.String
-		}
+	if len(ids) == 0 {
+		return discussions, nextCursor, nil
+	}
 
-		discussions = append(discussions, discussion)
+	// Get replies of the whole page in one query
+	trees, err := loadReplyTrees(ids, currentUserID, min(DefaultReplyDepth, discussionConfig.MaxDepth), DefaultReplyLimit, 0)
+	if err != nil {
+		return nil, "", err
+	}
+	for i := range discussions {
+		discussions[i].Replies = trees[discussions[i].ID]
 	}
 
-	return discussions, nil
+	if err := attachDiscussionMentions(discussions); err != nil {
+		return nil, "", err
+	}
+
+	return discussions, nextCursor, nil
 }
 
 // CreateDiscussion creates a new discussion/comment
 func CreateDiscussion(userID int, req CreateDiscussionRequest) (Discussion, error) {
+	active, err := isCatActive(req.BreedID)
+	if err != nil {
+		return Discussion{}, err
+	}
+	if !active {
+		return Discussion{}, sql.ErrNoRows
+	}
+
+	depth, err := replyDepth(req.BreedID, req.ParentID)
+	if err != nil {
+		return Discussion{}, err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return Discussion{}, err
+	}
+	defer tx.Rollback()
+
 	var discussion Discussion
 	var parentID sql.NullInt64
 
-	row := db.QueryRow(`
-		INSERT INTO discussions (breed_id, user_id, parent_id, message)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, breed_id, user_id, parent_id, message, 
+	row := tx.QueryRow(`
+		INSERT INTO discussions (breed_id, user_id, parent_id, depth, message)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, breed_id, user_id, parent_id, depth, message, 
 		          like_count, dislike_count, reply_count, is_deleted, created_at, updated_at
-	`, req.BreedID, userID, req.ParentID, req.Message)
+	`, req.BreedID, userID, req.ParentID, depth, req.Message)
 
-	err := row.Scan(
-		&discussion.ID, &discussion.BreedID, &discussion.UserID, &parentID,
+	err = row.Scan(
+		&discussion.ID, &discussion.BreedID, &discussion.UserID, &parentID, &discussion.Depth,
 		&discussion.Message, &discussion.LikeCount, &discussion.DislikeCount,
 		&discussion.ReplyCount, &discussion.IsDeleted, &discussion.CreatedAt, &discussion.UpdatedAt,
 	)
@@ -523,48 +835,134 @@ func CreateDiscussion(userID int, req CreateDiscussionRequest) (Discussion, erro
 		return Discussion{}, err
 	}
 
+	mentioned, err := saveMentions(tx, discussion.ID, discussion.Message)
+	if err != nil {
+		return Discussion{}, err
+	}
+
+	// เจ้าของ comment ที่ถูกตอบได้แจ้งเตือน reply แทน mention
+	parentAuthorID := 0
+	if req.ParentID != nil {
+		if parentAuthorID, err = notifyReply(tx, *req.ParentID, discussion.ID, userID); err != nil {
+			return Discussion{}, err
+		}
+	}
+	if err := notifyMentions(tx, discussion.ID, userID, mentioned, parentAuthorID); err != nil {
+		return Discussion{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Discussion{}, err
+	}
+
 	if parentID.Valid {
 		pid := int(parentID.Int64)
 		discussion.ParentID = &pid
 	}
+	discussion.MessageHTML = markdown.Render(discussion.Message)
 
 	// Get username
 	db.QueryRow("SELECT username FROM users WHERE id = $1", userID).Scan(&discussion.Username)
 
-	return discussion, nil
+	list := []Discussion{discussion}
+	if err := attachDiscussionMentions(list); err != nil {
+		return Discussion{}, err
+	}
+
+	return list[0], nil
 }
 
-// UpdateDiscussion updates a discussion
+// UpdateDiscussion updates a discussion. Unless the edit is within the grace window
+// after posting, the previous message is kept in discussion_edits.
 func UpdateDiscussion(discussionID, userID int, req UpdateDiscussionRequest) (Discussion, error) {
+	active, err := isDiscussionCatActive(discussionID)
+	if err != nil {
+		return Discussion{}, err
+	}
+	if !active {
+		return Discussion{}, sql.ErrNoRows
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return Discussion{}, err
+	}
+	defer tx.Rollback()
+
+	var previous string
+	var inGrace bool
+	err = tx.QueryRow(`
+		SELECT message, created_at > CURRENT_TIMESTAMP - make_interval(secs => $3)
+		FROM discussions
+		WHERE id = $1 AND user_id = $2 AND is_deleted = FALSE
+		FOR UPDATE
+	`, discussionID, userID, discussionConfig.EditGrace.Seconds()).Scan(&previous, &inGrace)
+	if err != nil {
+		return Discussion{}, err
+	}
+
+	record := !inGrace && previous != req.Message
+	if record {
+		_, err = tx.Exec(`
+			INSERT INTO discussion_edits (discussion_id, message, edited_by)
+			VALUES ($1, $2, $3)
+		`, discussionID, previous, userID)
+		if err != nil {
+			return Discussion{}, err
+		}
+	}
+
 	var discussion Discussion
 	var parentID sql.NullInt64
 
-	row := db.QueryRow(`
+	row := tx.QueryRow(`
 		UPDATE discussions 
-		SET message = $1, updated_at = CURRENT_TIMESTAMP
+		SET message = $1, updated_at = CURRENT_TIMESTAMP,
+		    edited_at = CASE WHEN $4::boolean THEN CURRENT_TIMESTAMP ELSE edited_at END,
+		    edit_count = edit_count + CASE WHEN $4::boolean THEN 1 ELSE 0 END
 		WHERE id = $2 AND user_id = $3
-		RETURNING id, breed_id, user_id, parent_id, message, 
-		          like_count, dislike_count, reply_count, is_deleted, created_at, updated_at
-	`, req.Message, discussionID, userID)
+		RETURNING id, breed_id, user_id, parent_id, depth, message, 
+		          like_count, dislike_count, reply_count, is_deleted, edited_at, edit_count,
+		          created_at, updated_at
+	`, req.Message, discussionID, userID, record)
 
-	err := row.Scan(
-		&discussion.ID, &discussion.BreedID, &discussion.UserID, &parentID,
+	err = row.Scan(
+		&discussion.ID, &discussion.BreedID, &discussion.UserID, &parentID, &discussion.Depth,
 		&discussion.Message, &discussion.LikeCount, &discussion.DislikeCount,
-		&discussion.ReplyCount, &discussion.IsDeleted, &discussion.CreatedAt, &discussion.UpdatedAt,
+		&discussion.ReplyCount, &discussion.IsDeleted, &discussion.EditedAt, &discussion.EditCount,
+		&discussion.CreatedAt, &discussion.UpdatedAt,
 	)
 
 	if err != nil {
 		return Discussion{}, err
 	}
 
+	mentioned, err := saveMentions(tx, discussion.ID, discussion.Message)
+	if err != nil {
+		return Discussion{}, err
+	}
+	if err := notifyMentions(tx, discussion.ID, userID, mentioned, 0); err != nil {
+		return Discussion{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Discussion{}, err
+	}
+
 	if parentID.Valid {
 		pid := int(parentID.Int64)
 		discussion.ParentID = &pid
 	}
+	discussion.MessageHTML = markdown.Render(discussion.Message)
 
 	db.QueryRow("SELECT username FROM users WHERE id = $1", userID).Scan(&discussion.Username)
 
-	return discussion, nil
+	list := []Discussion{discussion}
+	if err := attachDiscussionMentions(list); err != nil {
+		return Discussion{}, err
+	}
+
+	return list[0], nil
 }
 
 // DeleteDiscussion soft deletes a discussion
@@ -604,8 +1002,22 @@ func ToggleDiscussionReaction(discussionID, userID int, reactionType string) (Re
 		return ReactionResponse{}, sql.ErrNoRows
 	}
 
+	active, err := isDiscussionCatActive(discussionID)
+	if err != nil {
+		return ReactionResponse{}, err
+	}
+	if !active {
+		return ReactionResponse{}, sql.ErrNoRows
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return ReactionResponse{}, err
+	}
+	defer tx.Rollback()
+
 	var existingReaction sql.NullString
-	err := db.QueryRow(`
+	err = tx.QueryRow(`
 		SELECT reaction_type 
 		FROM discussion_reactions 
 		WHERE discussion_id = $1 AND user_id = $2
@@ -615,30 +1027,47 @@ func ToggleDiscussionReaction(discussionID, userID int, reactionType string) (Re
 		return ReactionResponse{}, err
 	}
 
+	liked := false
 	if existingReaction.Valid {
 		if existingReaction.String == reactionType {
-			_, err = db.Exec(`
+			_, err = tx.Exec(`
 				DELETE FROM discussion_reactions 
 				WHERE discussion_id = $1 AND user_id = $2
 			`, discussionID, userID)
 		} else {
-			_, err = db.Exec(`
+			_, err = tx.Exec(`
 				UPDATE discussion_reactions 
 				SET reaction_type = $1 
 				WHERE discussion_id = $2 AND user_id = $3
 			`, reactionType, discussionID, userID)
+			liked = reactionType == "like"
 		}
 	} else {
-		_, err = db.Exec(`
+		_, err = tx.Exec(`
 			INSERT INTO discussion_reactions (discussion_id, user_id, reaction_type) 
 			VALUES ($1, $2, $3)
 		`, discussionID, userID, reactionType)
+		liked = reactionType == "like"
+	}
+
+	if err != nil {
+		return ReactionResponse{}, err
 	}
 
+	// แจ้งเจ้าของ comment เมื่อถูก like และถอนออกเมื่อเลิก like
+	if liked {
+		err = notifyLike(tx, discussionID, userID)
+	} else if existingReaction.String == "like" {
+		err = retractLike(tx, discussionID, userID)
+	}
 	if err != nil {
 		return ReactionResponse{}, err
 	}
 
+	if err := tx.Commit(); err != nil {
+		return ReactionResponse{}, err
+	}
+
 	var response ReactionResponse
 	var userReaction sql.NullString
 
@@ -0,0 +1,258 @@
+package infoDB
+
+import (
+	"database/sql"
+	"reflect"
+	"slices"
+	"strconv"
+)
+
+// ===================== Catalog Import/Export Models =====================
+
+// ImportRow is one breed of a bulk import. Errors holds problems found while
+// parsing or validating the row; such rows are reported but never written.
+type ImportRow struct {
+	Row    int
+	Slug   string // optional: match an existing breed by (current or old) slug, or the slug of a new one
+	Cat    CreateCatRequest
+	Errors []string
+}
+
+type ImportRowResult struct {
+	Row    int      `json:"row"`
+	Name   string   `json:"name"`
+	Action string   `json:"action,omitempty"` // "create", "update" or "unchanged"
+	CatID  *int     `json:"cat_id,omitempty"`
+	Slug   string   `json:"slug,omitempty"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+type ImportReport struct {
+	DryRun    bool              `json:"dry_run"`
+	Committed bool              `json:"committed"`
+	Total     int               `json:"total"`
+	Created   int               `json:"created"`
+	Updated   int               `json:"updated"`
+	Unchanged int               `json:"unchanged"`
+	Failed    int               `json:"failed"`
+	Rows      []ImportRowResult `json:"rows"`
+}
+
+// ===================== Catalog Import/Export Functions =====================
+
+// ImportCats upserts breeds in a single transaction. A row updates the breed with
+// the same slug (or, without a slug, the same name) and creates a new breed otherwise;
+// a new breed gets the row's slug when it is free (made unique like any other slug).
+// Empty fields of an update keep their current value, as in UpdateCat.
+// Nothing is committed in a dry run or when any row fails.
+func ImportCats(userID int, rows []ImportRow, dryRun bool) (ImportReport, error) {
+	report := ImportReport{
+		DryRun: dryRun,
+		Total:  len(rows),
+		Rows:   make([]ImportRowResult, 0, len(rows)),
+	}
+
+	// ถ้าทุกแถวมีปัญหาตั้งแต่ตอน parse/validate ก็ไม่ต้องเปิด transaction
+	var tx *sql.Tx
+	if slices.ContainsFunc(rows, func(row ImportRow) bool { return len(row.Errors) == 0 }) {
+		var err error
+		if tx, err = db.Begin(); err != nil {
+			return ImportReport{}, err
+		}
+		defer tx.Rollback()
+	}
+
+	// breed id -> แถวแรกที่อ้างถึง (กันไฟล์ที่มี breed ซ้ำกัน)
+	seen := map[int]int{}
+
+	for _, row := range rows {
+		result := ImportRowResult{Row: row.Row, Name: row.Cat.Name, Errors: row.Errors}
+
+		if len(result.Errors) == 0 {
+			if err := importCatRow(tx, userID, row, seen, &result); err != nil {
+				return ImportReport{}, err
+			}
+		}
+
+		switch {
+		case len(result.Errors) > 0:
+			report.Failed++
+		case result.Action == "create":
+			report.Created++
+		case result.Action == "update":
+			report.Updated++
+		default:
+			report.Unchanged++
+		}
+		report.Rows = append(report.Rows, result)
+	}
+
+	if dryRun || report.Failed > 0 || tx == nil {
+		return report, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return ImportReport{}, err
+	}
+	report.Committed = true
+
+	return report, nil
+}
+
+// importCatRow writes one row inside a savepoint, so a failing row is recorded in
+// result and rolled back without aborting the rest of the import. The returned
+// error is only for failures that make the whole transaction unusable.
+func importCatRow(tx *sql.Tx, userID int, row ImportRow, seen map[int]int, result *ImportRowResult) error {
+	savepoint := "import_row_" + strconv.Itoa(row.Row)
+	if _, err := tx.Exec("SAVEPOINT " + savepoint); err != nil {
+		return err
+	}
+
+	fail := func(msg string) error {
+		result.Errors = append(result.Errors, msg)
+		result.Action = ""
+		result.CatID = nil
+		result.Slug = ""
+		_, err := tx.Exec("ROLLBACK TO SAVEPOINT " + savepoint)
+		return err
+	}
+
+	catID, err := matchImportRow(tx, row)
+	if err != nil {
+		return fail(err.Error())
+	}
+
+	var cat Cat
+	if catID == 0 {
+		result.Action = "create"
+		cat, err = createCatTx(tx, userID, row.Cat, row.Slug)
+		if err != nil {
+			return fail(err.Error())
+		}
+	} else {
+		if first, ok := seen[catID]; ok {
+			return fail("same breed as row " + strconv.Itoa(first))
+		}
+
+		var before Cat
+		err = scanCat(tx.QueryRow(`
+			SELECT `+catColumns+`
+			FROM cat_breeds cb
+			WHERE cb.id = $1
+			FOR UPDATE
+		`, catID), &before)
+		if err != nil {
+			return fail(err.Error())
+		}
+
+		result.Action = "update"
+		cat, err = updateCatTx(tx, catID, userID, UpdateCatRequest(row.Cat))
+		if err != nil {
+			return fail(err.Error())
+		}
+
+		// ไม่มีอะไรเปลี่ยน: ไม่ต้องแตะ updated_at และไม่ต้องสร้าง revision ใหม่
+		if sameCatContent(before, cat) {
+			if _, err := tx.Exec("ROLLBACK TO SAVEPOINT " + savepoint); err != nil {
+				return err
+			}
+			result.Action = "unchanged"
+			cat = before
+		}
+	}
+
+	seen[cat.ID] = row.Row
+	result.CatID = &cat.ID
+	result.Slug = cat.Slug
+
+	_, err = tx.Exec("RELEASE SAVEPOINT " + savepoint)
+	return err
+}
+
+// matchImportRow finds the active breed an import row refers to (0 for a new breed)
+func matchImportRow(tx *sql.Tx, row ImportRow) (int, error) {
+	var catID int
+	var err error
+
+	if row.Slug != "" {
+		err = tx.QueryRow(`
+			SELECT cb.id FROM cat_breeds cb
+			WHERE cb.deleted_at IS NULL
+			  AND (cb.slug = $1 OR cb.id = (SELECT breed_id FROM breed_slug_history WHERE slug = $1))
+		`, row.Slug).Scan(&catID)
+	} else {
+		err = tx.QueryRow(`
+			SELECT cb.id FROM cat_breeds cb
+			WHERE cb.deleted_at IS NULL
+			  AND (cb.slug = $1 OR lower(cb.name) = lower($2))
+			ORDER BY cb.slug = $1 DESC, cb.id
+			LIMIT 1
+		`, Slugify(row.Cat.Name), row.Cat.Name).Scan(&catID)
+	}
+
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return catID, err
+}
+
+// sameCatContent reports whether two snapshots of a breed have the same editable content
+func sameCatContent(a, b Cat) bool {
+	return a.Name == b.Name &&
+		a.Origin == b.Origin &&
+		a.Description == b.Description &&
+		a.Care == b.Care &&
+		a.ImageURL == b.ImageURL &&
+		reflect.DeepEqual(a.Attributes, b.Attributes)
+}
+
+// exportBatchSize is how many breeds ExportCats loads cover images for at a time
+const exportBatchSize = 100
+
+// ExportCats streams every active breed, oldest first, with its cover image to fn.
+// Rows are read from the database in small batches, so the catalog is never held in memory.
+func ExportCats(fn func(Cat) error) error {
+	rows, err := db.Query(`
+		SELECT ` + catColumns + `
+		FROM cat_breeds cb
+		WHERE cb.deleted_at IS NULL
+		ORDER BY cb.id
+	`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	batch := make([]Cat, 0, exportBatchSize)
+	emit := func() error {
+		if err := attachCatImages(batch); err != nil {
+			return err
+		}
+		for _, cat := range batch {
+			if err := fn(cat); err != nil {
+				return err
+			}
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	for rows.Next() {
+		var cat Cat
+		if err := scanCat(rows, &cat); err != nil {
+			return err
+		}
+
+		batch = append(batch, cat)
+		if len(batch) == exportBatchSize {
+			if err := emit(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	return emit()
+}
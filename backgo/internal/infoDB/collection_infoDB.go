@@ -0,0 +1,593 @@
+package infoDB
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ===================== Favorite & Collection Models =====================
+
+type FavoriteCat struct {
+	Cat
+	FavoritedAt time.Time `json:"favorited_at"`
+}
+
+type Collection struct {
+	ID            int    `json:"id"`
+	UserID        int    `json:"user_id"`
+	OwnerUsername string `json:"owner_username"`
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	Visibility    string `json:"visibility"` // "private", "unlisted" or "public"
+	// ใช้เปิด collection แบบ unlisted (แสดงเฉพาะเจ้าของ)
+	ShareToken string           `json:"share_token,omitempty"`
+	ItemCount  int              `json:"item_count"`
+	Items      []CollectionItem `json:"items,omitempty"`
+	CreatedAt  time.Time        `json:"created_at"`
+	UpdatedAt  time.Time        `json:"updated_at"`
+}
+
+type CollectionItem struct {
+	Position int       `json:"position"`
+	Note     string    `json:"note"`
+	AddedAt  time.Time `json:"added_at"`
+	Cat      Cat       `json:"cat"`
+}
+
+type CreateCollectionRequest struct {
+	Name        string `json:"name" binding:"required,min=1,max=100"`
+	Description string `json:"description" binding:"max=2000"`
+	Visibility  string `json:"visibility" binding:"omitempty,oneof=private unlisted public"`
+}
+
+// UpdateCollectionRequest changes the given fields only
+type UpdateCollectionRequest struct {
+	Name        string  `json:"name" binding:"omitempty,min=1,max=100"`
+	Description *string `json:"description" binding:"omitempty,max=2000"`
+	Visibility  string  `json:"visibility" binding:"omitempty,oneof=private unlisted public"`
+}
+
+type CollectionItemRequest struct {
+	Note string `json:"note" binding:"max=1000"`
+}
+
+type ReorderCollectionRequest struct {
+	BreedIDs []int `json:"breed_ids" binding:"required,min=1"`
+}
+
+const maxCollectionsPerUser = 100
+const maxCollectionItems = 500
+
+var (
+	ErrCollectionNameTaken   = errors.New("you already have a collection with this name")
+	ErrTooManyCollections    = errors.New("collection limit reached")
+	ErrCollectionFull        = errors.New("collection item limit reached")
+	ErrCollectionSetMismatch = errors.New("breed_ids must list every breed of the collection exactly once")
+)
+
+const collectionColumns = `
+	c.id, c.user_id, u.username, c.name, COALESCE(c.description, ''), c.visibility, c.share_token,
+	(SELECT COUNT(*) FROM collection_items ci
+	 JOIN cat_breeds cb ON ci.breed_id = cb.id AND cb.deleted_at IS NULL
+	 WHERE ci.collection_id = c.id),
+	c.created_at, c.updated_at`
+
+// ===================== Favorite Functions =====================
+
+// AddFavorite saves a breed to the user's favorites (no-op if already saved)
+func AddFavorite(userID, catID int) error {
+	active, err := isCatActive(catID)
+	if err != nil {
+		return err
+	}
+	if !active {
+		return sql.ErrNoRows
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO user_favorites (user_id, breed_id) VALUES ($1, $2)
+		ON CONFLICT (user_id, breed_id) DO NOTHING
+	`, userID, catID)
+
+	return err
+}
+
+// RemoveFavorite removes a breed from the user's favorites
+func RemoveFavorite(userID, catID int) error {
+	result, err := db.Exec(`
+		DELETE FROM user_favorites WHERE user_id = $1 AND breed_id = $2
+	`, userID, catID)
+	if err != nil {
+		return err
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// GetFavoriteCats lists the user's favorite breeds, most recently saved first
+func GetFavoriteCats(userID, limit, offset int) ([]FavoriteCat, error) {
+	rows, err := db.Query(`
+		SELECT `+catColumns+`,
+			br.reaction_type as user_reaction,
+			uf.created_at
+		FROM user_favorites uf
+		JOIN cat_breeds cb ON uf.breed_id = cb.id AND cb.deleted_at IS NULL
+		LEFT JOIN breed_reactions br ON cb.id = br.breed_id AND br.user_id = $1
+		WHERE uf.user_id = $1
+		ORDER BY uf.created_at DESC, cb.id
+		LIMIT $2 OFFSET $3
+	`, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	favorites := []FavoriteCat{}
+	for rows.Next() {
+		var fav FavoriteCat
+		if err := scanCat(rows, &fav.Cat, &fav.UserReaction, &fav.FavoritedAt); err != nil {
+			return nil, err
+		}
+		favorited := true
+		fav.IsFavorited = &favorited
+		favorites = append(favorites, fav)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	cats := make([]Cat, len(favorites))
+	for i := range favorites {
+		cats[i] = favorites[i].Cat
+	}
+	if err := attachCatImages(cats); err != nil {
+		return nil, err
+	}
+	for i := range favorites {
+		favorites[i].Cat = cats[i]
+	}
+
+	return favorites, nil
+}
+
+// ===================== Collection Functions =====================
+
+// CreateCollection creates an empty collection owned by userID
+func CreateCollection(userID int, req CreateCollectionRequest) (Collection, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return Collection{}, err
+	}
+	defer tx.Rollback()
+
+	var count int
+	err = tx.QueryRow(`SELECT COUNT(*) FROM collections WHERE user_id = $1`, userID).Scan(&count)
+	if err != nil {
+		return Collection{}, err
+	}
+	if count >= maxCollectionsPerUser {
+		return Collection{}, ErrTooManyCollections
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		return Collection{}, err
+	}
+
+	visibility := req.Visibility
+	if visibility == "" {
+		visibility = "private"
+	}
+
+	var collectionID int
+	err = tx.QueryRow(`
+		INSERT INTO collections (user_id, name, description, visibility, share_token)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5)
+		RETURNING id
+	`, userID, req.Name, req.Description, visibility, token).Scan(&collectionID)
+	if isCollectionNameTaken(err) {
+		return Collection{}, ErrCollectionNameTaken
+	} else if err != nil {
+		return Collection{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Collection{}, err
+	}
+
+	return GetCollection(collectionID, &userID)
+}
+
+// isCollectionNameTaken reports whether err is a violation of the per-user unique
+// name index; concurrent creates can both pass any pre-check, so the index decides
+func isCollectionNameTaken(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == "idx_collections_user_name"
+}
+
+// UpdateCollection changes the name, description or visibility of the user's collection
+func UpdateCollection(userID, collectionID int, req UpdateCollectionRequest) (Collection, error) {
+	var description sql.NullString
+	if req.Description != nil {
+		description = sql.NullString{String: *req.Description, Valid: true}
+	}
+
+	result, err := db.Exec(`
+		UPDATE collections
+		SET name = COALESCE(NULLIF($3, ''), name),
+		    description = CASE WHEN $4::text IS NULL THEN description ELSE NULLIF($4, '') END,
+		    visibility = COALESCE(NULLIF($5, '')::collection_visibility_enum, visibility),
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND user_id = $2
+	`, collectionID, userID, req.Name, description, req.Visibility)
+	if isCollectionNameTaken(err) {
+		return Collection{}, ErrCollectionNameTaken
+	} else if err != nil {
+		return Collection{}, err
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return Collection{}, sql.ErrNoRows
+	}
+
+	return GetCollection(collectionID, &userID)
+}
+
+// DeleteCollection deletes the user's collection and its entries
+func DeleteCollection(userID, collectionID int) error {
+	result, err := db.Exec(`
+		DELETE FROM collections WHERE id = $1 AND user_id = $2
+	`, collectionID, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// GetUserCollections lists all collections of a user (without items)
+func GetUserCollections(userID int) ([]Collection, error) {
+	rows, err := db.Query(`
+		SELECT `+collectionColumns+`
+		FROM collections c
+		JOIN users u ON c.user_id = u.id
+		WHERE c.user_id = $1
+		ORDER BY c.updated_at DESC, c.id
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanCollections(rows, &userID)
+}
+
+// GetPublicCollections lists public collections, most recently updated first
+func GetPublicCollections(limit, offset int) ([]Collection, error) {
+	rows, err := db.Query(`
+		SELECT `+collectionColumns+`
+		FROM collections c
+		JOIN users u ON c.user_id = u.id
+		WHERE c.visibility = 'public'
+		ORDER BY c.updated_at DESC, c.id
+		LIMIT $1 OFFSET $2
+	`, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanCollections(rows, nil)
+}
+
+// GetCollection gets a collection with its items. Only public collections and the
+// viewer's own collections are found.
+func GetCollection(collectionID int, viewerID *int) (Collection, error) {
+	var userID int
+	if viewerID != nil {
+		userID = *viewerID
+	}
+
+	row := db.QueryRow(`
+		SELECT `+collectionColumns+`
+		FROM collections c
+		JOIN users u ON c.user_id = u.id
+		WHERE c.id = $1 AND (c.visibility = 'public' OR c.user_id = $2)
+	`, collectionID, userID)
+
+	return loadCollection(row, viewerID)
+}
+
+// GetSharedCollection gets an unlisted or public collection by its share token
+func GetSharedCollection(token string, viewerID *int) (Collection, error) {
+	row := db.QueryRow(`
+		SELECT `+collectionColumns+`
+		FROM collections c
+		JOIN users u ON c.user_id = u.id
+		WHERE c.share_token = $1 AND c.visibility IN ('unlisted', 'public')
+	`, token)
+
+	return loadCollection(row, viewerID)
+}
+
+// SetCollectionItem adds a breed to the end of the user's collection, or updates
+// its note if it is already there
+func SetCollectionItem(userID, collectionID, catID int, note string) (Collection, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return Collection{}, err
+	}
+	defer tx.Rollback()
+
+	if err := lockOwnCollection(tx, userID, collectionID); err != nil {
+		return Collection{}, err
+	}
+
+	active, err := isCatActive(catID)
+	if err != nil {
+		return Collection{}, err
+	}
+	if !active {
+		return Collection{}, sql.ErrNoRows
+	}
+
+	var count int
+	var exists bool
+	err = tx.QueryRow(`
+		SELECT COUNT(*), COALESCE(BOOL_OR(breed_id = $2), FALSE)
+		FROM collection_items WHERE collection_id = $1
+	`, collectionID, catID).Scan(&count, &exists)
+	if err != nil {
+		return Collection{}, err
+	}
+	if !exists && count >= maxCollectionItems {
+		return Collection{}, ErrCollectionFull
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO collection_items (collection_id, breed_id, position, note)
+		SELECT $1, $2, COALESCE(MAX(position) + 1, 0), NULLIF($3, '')
+		FROM collection_items WHERE collection_id = $1
+		ON CONFLICT (collection_id, breed_id) DO UPDATE SET note = EXCLUDED.note
+	`, collectionID, catID, note)
+	if err != nil {
+		return Collection{}, err
+	}
+
+	if err := touchCollection(tx, collectionID); err != nil {
+		return Collection{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Collection{}, err
+	}
+
+	return GetCollection(collectionID, &userID)
+}
+
+// RemoveCollectionItem removes a breed from the user's collection
+func RemoveCollectionItem(userID, collectionID, catID int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockOwnCollection(tx, userID, collectionID); err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(`
+		DELETE FROM collection_items WHERE collection_id = $1 AND breed_id = $2
+	`, collectionID, catID)
+	if err != nil {
+		return err
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	if err := touchCollection(tx, collectionID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ReorderCollectionItems sets the order of a collection; breedIDs must list every
+// (non-deleted) breed in it once
+func ReorderCollectionItems(userID, collectionID int, breedIDs []int) (Collection, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return Collection{}, err
+	}
+	defer tx.Rollback()
+
+	if err := lockOwnCollection(tx, userID, collectionID); err != nil {
+		return Collection{}, err
+	}
+
+	ids := make([]int64, len(breedIDs))
+	for i, id := range breedIDs {
+		ids[i] = int64(id)
+	}
+
+	// breed ที่ถูกลบ (อยู่ในถังขยะ) ไม่ต้องส่งมา และคงตำแหน่งเดิมไว้
+	var total, matched, distinct int
+	err = tx.QueryRow(`
+		SELECT
+			(SELECT COUNT(*) FROM collection_items ci
+			 JOIN cat_breeds cb ON ci.breed_id = cb.id AND cb.deleted_at IS NULL
+			 WHERE ci.collection_id = $1),
+			(SELECT COUNT(*) FROM collection_items ci
+			 JOIN cat_breeds cb ON ci.breed_id = cb.id AND cb.deleted_at IS NULL
+			 WHERE ci.collection_id = $1 AND ci.breed_id = ANY($2)),
+			(SELECT COUNT(DISTINCT x) FROM unnest($2::int[]) AS x)
+	`, collectionID, pq.Array(ids)).Scan(&total, &matched, &distinct)
+	if err != nil {
+		return Collection{}, err
+	}
+	if total != len(ids) || matched != len(ids) || distinct != len(ids) {
+		return Collection{}, ErrCollectionSetMismatch
+	}
+
+	_, err = tx.Exec(`
+		UPDATE collection_items ci
+		SET position = o.position - 1
+		FROM unnest($2::int[]) WITH ORDINALITY AS o(breed_id, position)
+		WHERE ci.breed_id = o.breed_id AND ci.collection_id = $1
+	`, collectionID, pq.Array(ids))
+	if err != nil {
+		return Collection{}, err
+	}
+
+	if err := touchCollection(tx, collectionID); err != nil {
+		return Collection{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Collection{}, err
+	}
+
+	return GetCollection(collectionID, &userID)
+}
+
+// lockOwnCollection locks the collection row, returning sql.ErrNoRows unless userID owns it
+func lockOwnCollection(tx *sql.Tx, userID, collectionID int) error {
+	var id int
+	return tx.QueryRow(`
+		SELECT id FROM collections WHERE id = $1 AND user_id = $2 FOR UPDATE
+	`, collectionID, userID).Scan(&id)
+}
+
+func touchCollection(tx *sql.Tx, collectionID int) error {
+	_, err := tx.Exec(`UPDATE collections SET updated_at = CURRENT_TIMESTAMP WHERE id = $1`, collectionID)
+	return err
+}
+
+func newShareToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// loadCollection scans a collection row and loads its items
+func loadCollection(row rowScanner, viewerID *int) (Collection, error) {
+	collection, err := scanCollection(row, viewerID)
+	if err != nil {
+		return Collection{}, err
+	}
+
+	collection.Items, err = getCollectionItems(collection.ID, viewerID)
+	if err != nil {
+		return Collection{}, err
+	}
+
+	return collection, nil
+}
+
+// getCollectionItems loads the entries of a collection in order, skipping deleted breeds
+func getCollectionItems(collectionID int, viewerID *int) ([]CollectionItem, error) {
+	rows, err := db.Query(`
+		SELECT ci.breed_id, ci.position, COALESCE(ci.note, ''), ci.added_at
+		FROM collection_items ci
+		JOIN cat_breeds cb ON ci.breed_id = cb.id AND cb.deleted_at IS NULL
+		WHERE ci.collection_id = $1
+		ORDER BY ci.position, ci.added_at, ci.breed_id
+	`, collectionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	items := []CollectionItem{}
+	for rows.Next() {
+		var item CollectionItem
+		var breedID int
+		if err := rows.Scan(&breedID, &item.Position, &item.Note, &item.AddedAt); err != nil {
+			return nil, err
+		}
+		ids = append(ids, breedID)
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return items, nil
+	}
+
+	cats, err := GetCatsByIDs(ids, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := map[int]Cat{}
+	for _, cat := range cats {
+		byID[cat.ID] = cat
+	}
+
+	result := []CollectionItem{}
+	for i, item := range items {
+		cat, ok := byID[ids[i]]
+		if !ok {
+			continue
+		}
+		item.Cat = cat
+		result = append(result, item)
+	}
+
+	return result, nil
+}
+
+func scanCollections(rows *sql.Rows, viewerID *int) ([]Collection, error) {
+	collections := []Collection{}
+	for rows.Next() {
+		collection, err := scanCollection(rows, viewerID)
+		if err != nil {
+			return nil, err
+		}
+		collections = append(collections, collection)
+	}
+
+	return collections, rows.Err()
+}
+
+// scanCollection scans collectionColumns; the share token is kept for the owner only
+func scanCollection(scanner rowScanner, viewerID *int) (Collection, error) {
+	var c Collection
+	err := scanner.Scan(
+		&c.ID, &c.UserID, &c.OwnerUsername, &c.Name, &c.Description, &c.Visibility, &c.ShareToken,
+		&c.ItemCount, &c.CreatedAt, &c.UpdatedAt,
+	)
+	if err != nil {
+		return Collection{}, err
+	}
+
+	if viewerID == nil || *viewerID != c.UserID {
+		c.ShareToken = ""
+	}
+
+	return c, nil
+}
@@ -0,0 +1,29 @@
+package infoDB
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestIsCollectionNameTaken(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&pq.Error{Code: "23505", Constraint: "idx_collections_user_name"}, true},
+		{fmt.Errorf("insert: %w", &pq.Error{Code: "23505", Constraint: "idx_collections_user_name"}), true},
+		{&pq.Error{Code: "23505", Constraint: "collections_share_token_key"}, false},
+		{&pq.Error{Code: "23503", Constraint: "idx_collections_user_name"}, false},
+		{errors.New("connection refused"), false},
+		{nil, false},
+	}
+
+	for _, tt := range tests {
+		if got := isCollectionNameTaken(tt.err); got != tt.want {
+			t.Errorf("isCollectionNameTaken(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package infoDB
+
+import (
+	"database/sql"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ===================== Breed Comparison Models =====================
+
+type DiscussionSnippet struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"user_id"`
+	Username  string    `json:"username"`
+	Snippet   string    `json:"snippet"`
+	Truncated bool      `json:"truncated"`
+	LikeCount int       `json:"like_count"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ComparedBreed struct {
+	Cat Cat `json:"cat"`
+	// likes / (likes + dislikes); null เมื่อยังไม่มีใครกด
+	LikeRatio     *float64           `json:"like_ratio"`
+	TopDiscussion *DiscussionSnippet `json:"top_discussion"`
+}
+
+// ComparisonField holds one field of every compared breed, in the order of Breeds
+type ComparisonField struct {
+	Field   string `json:"field"`
+	Values  []any  `json:"values"`
+	Differs bool   `json:"differs"`
+}
+
+type BreedComparison struct {
+	Breeds      []ComparedBreed   `json:"breeds"`
+	Fields      []ComparisonField `json:"fields"`
+	Differences []string          `json:"differences"`
+}
+
+const MinCompareBreeds = 2
+const MaxCompareBreeds = 4
+
+const snippetLength = 200
+
+// ===================== Breed Comparison Functions =====================
+
+// CompareCats puts active breeds side by side. It returns sql.ErrNoRows if any
+// of the ids is not an active breed.
+func CompareCats(ids []int, currentUserID *int) (BreedComparison, error) {
+	cats, err := GetCatsByIDs(ids, currentUserID)
+	if err != nil {
+		return BreedComparison{}, err
+	}
+	if len(cats) != len(ids) {
+		return BreedComparison{}, sql.ErrNoRows
+	}
+
+	snippets, err := topDiscussionSnippets(ids)
+	if err != nil {
+		return BreedComparison{}, err
+	}
+
+	comparison := BreedComparison{
+		Breeds:      make([]ComparedBreed, len(cats)),
+		Differences: []string{},
+	}
+	for i, cat := range cats {
+		entry := ComparedBreed{Cat: cat, LikeRatio: likeRatio(cat.LikeCount, cat.DislikeCount)}
+		if snippet, ok := snippets[cat.ID]; ok {
+			entry.TopDiscussion = &snippet
+		}
+		comparison.Breeds[i] = entry
+	}
+
+	comparison.Fields, err = comparisonFields(comparison.Breeds)
+	if err != nil {
+		return BreedComparison{}, err
+	}
+
+	for _, f := range comparison.Fields {
+		if f.Differs {
+			comparison.Differences = append(comparison.Differences, f.Field)
+		}
+	}
+
+	return comparison, nil
+}
+
+// comparisonFields lists origin, every attribute and the engagement numbers of each breed
+func comparisonFields(breeds []ComparedBreed) ([]ComparisonField, error) {
+	attrs := make([]map[string]any, len(breeds))
+	for i, b := range breeds {
+		m, err := attributeMap(b.Cat.Attributes)
+		if err != nil {
+			return nil, err
+		}
+		attrs[i] = m
+	}
+
+	var fields []ComparisonField
+	add := func(name string, value func(ComparedBreed, int) any) {
+		f := ComparisonField{Field: name, Values: make([]any, len(breeds))}
+		for i, b := range breeds {
+			f.Values[i] = value(b, i)
+		}
+		for _, v := range f.Values[1:] {
+			if !reflect.DeepEqual(v, f.Values[0]) {
+				f.Differs = true
+				break
+			}
+		}
+		fields = append(fields, f)
+	}
+
+	add("origin", func(b ComparedBreed, _ int) any { return b.Cat.Origin })
+
+	for _, key := range attributeFieldNames() {
+		add("attributes."+key, func(_ ComparedBreed, i int) any { return attrs[i][key] })
+	}
+
+	add("like_count", func(b ComparedBreed, _ int) any { return b.Cat.LikeCount })
+	add("dislike_count", func(b ComparedBreed, _ int) any { return b.Cat.DislikeCount })
+	add("discussion_count", func(b ComparedBreed, _ int) any { return b.Cat.DiscussionCount })
+	add("view_count", func(b ComparedBreed, _ int) any { return b.Cat.ViewCount })
+	add("like_ratio", func(b ComparedBreed, _ int) any {
+		if b.LikeRatio == nil {
+			return nil
+		}
+		return *b.LikeRatio
+	})
+
+	return fields, nil
+}
+
+// attributeFieldNames returns the JSON names of BreedAttributes in declaration order
+func attributeFieldNames() []string {
+	t := reflect.TypeOf(BreedAttributes{})
+	names := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		names = append(names, name)
+	}
+	return names
+}
+
+func likeRatio(likes, dislikes int) *float64 {
+	if likes+dislikes == 0 {
+		return nil
+	}
+	ratio := math.Round(float64(likes)/float64(likes+dislikes)*10000) / 10000
+	return &ratio
+}
+
+// topDiscussionSnippets gets the most-liked visible discussion of each breed
+func topDiscussionSnippets(ids []int) (map[int]DiscussionSnippet, error) {
+	breedIDs := make([]int64, len(ids))
+	for i, id := range ids {
+		breedIDs[i] = int64(id)
+	}
+
+	rows, err := db.Query(`
+		SELECT DISTINCT ON (d.breed_id)
+			d.breed_id, d.id, d.user_id, u.username, d.message, d.like_count, d.created_at
+		FROM discussions d
+		JOIN users u ON d.user_id = u.id
+		WHERE d.breed_id = ANY($1) AND d.is_deleted = FALSE
+		ORDER BY d.breed_id, d.like_count DESC, d.created_at
+	`, pq.Array(breedIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	snippets := map[int]DiscussionSnippet{}
+	for rows.Next() {
+		var breedID int
+		var message string
+		var s DiscussionSnippet
+		if err := rows.Scan(&breedID, &s.ID, &s.UserID, &s.Username, &message, &s.LikeCount, &s.CreatedAt); err != nil {
+			return nil, err
+		}
+		s.Snippet, s.Truncated = snippet(message, snippetLength)
+		snippets[breedID] = s
+	}
+
+	return snippets, rows.Err()
+}
+
+// snippet shortens text to at most n runes, cutting at a word boundary where possible
+func snippet(text string, n int) (string, bool) {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text, false
+	}
+
+	cut := string(runes[:n])
+	if i := strings.LastIndex(cut, " "); i > len(cut)/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,.;:") + "…", true
+}
@@ -0,0 +1,370 @@
+package infoDB
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ===================== Health Condition Models =====================
+
+type ConditionReference struct {
+	Title string `json:"title" binding:"required,max=255"`
+	URL   string `json:"url" binding:"required,url,max=2000"`
+}
+
+type HealthCondition struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	Abbreviation string `json:"abbreviation"`
+	Description  string `json:"description"`
+	// "mild", "moderate", "severe" or "life_threatening"
+	Severity       string               `json:"severity"`
+	Genetic        bool                 `json:"genetic"`
+	ScreeningTests []string             `json:"screening_tests"`
+	References     []ConditionReference `json:"references"`
+	// จำนวนสายพันธุ์ (ที่ยังไม่ถูกลบ) ที่ผูกกับโรคนี้
+	BreedCount int       `json:"breed_count"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// BreedCondition is a condition as it affects one breed
+type BreedCondition struct {
+	HealthCondition
+	// "rare", "occasional", "common" or "very_common"
+	Prevalence string `json:"prevalence"`
+	Notes      string `json:"notes"`
+}
+
+// ConditionBreed is a breed affected by one condition
+type ConditionBreed struct {
+	Prevalence string `json:"prevalence"`
+	Notes      string `json:"notes"`
+	Cat        Cat    `json:"cat"`
+}
+
+type CreateConditionRequest struct {
+	Name           string               `json:"name" binding:"required,min=2,max=255"`
+	Abbreviation   string               `json:"abbreviation" binding:"max=20"`
+	Description    string               `json:"description" binding:"max=10000"`
+	Severity       string               `json:"severity" binding:"required,oneof=mild moderate severe life_threatening"`
+	Genetic        bool                 `json:"genetic"`
+	ScreeningTests []string             `json:"screening_tests" binding:"omitempty,max=20,dive,min=2,max=255"`
+	References     []ConditionReference `json:"references" binding:"omitempty,max=20,dive"`
+}
+
+// UpdateConditionRequest changes the given fields only
+type UpdateConditionRequest struct {
+	Name           string               `json:"name" binding:"omitempty,min=2,max=255"`
+	Abbreviation   *string              `json:"abbreviation" binding:"omitempty,max=20"`
+	Description    *string              `json:"description" binding:"omitempty,max=10000"`
+	Severity       string               `json:"severity" binding:"omitempty,oneof=mild moderate severe life_threatening"`
+	Genetic        *bool                `json:"genetic"`
+	ScreeningTests []string             `json:"screening_tests" binding:"omitempty,max=20,dive,min=2,max=255"`
+	References     []ConditionReference `json:"references" binding:"omitempty,max=20,dive"`
+}
+
+type BreedConditionRequest struct {
+	Prevalence string `json:"prevalence" binding:"required,oneof=rare occasional common very_common"`
+	Notes      string `json:"notes" binding:"max=1000"`
+}
+
+var ConditionSeverities = []string{"mild", "moderate", "severe", "life_threatening"}
+
+var ErrConditionNameTaken = errors.New("a health condition with this name already exists")
+
+const conditionColumns = `
+	hc.id, hc.name, hc.abbreviation, hc.description, hc.severity, hc.genetic,
+	hc.screening_tests, hc.reference_links,
+	(SELECT COUNT(*) FROM breed_health_conditions bhc
+	 JOIN cat_breeds cb ON bhc.breed_id = cb.id AND cb.deleted_at IS NULL
+	 WHERE bhc.condition_id = hc.id),
+	hc.created_at, hc.updated_at`
+
+// ===================== Health Condition Functions =====================
+
+// GetConditions lists health conditions by name, optionally of one severity
+func GetConditions(severity string) ([]HealthCondition, error) {
+	rows, err := db.Query(`
+		SELECT `+conditionColumns+`
+		FROM health_conditions hc
+		WHERE $1 = '' OR hc.severity::text = $1
+		ORDER BY lower(hc.name)
+	`, severity)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	conditions := []HealthCondition{}
+	for rows.Next() {
+		condition, err := scanCondition(rows)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, condition)
+	}
+
+	return conditions, rows.Err()
+}
+
+// GetCondition gets a health condition by id
+func GetCondition(conditionID int) (HealthCondition, error) {
+	return scanCondition(db.QueryRow(`
+		SELECT `+conditionColumns+`
+		FROM health_conditions hc
+		WHERE hc.id = $1
+	`, conditionID))
+}
+
+// CreateCondition adds a health condition to the registry
+func CreateCondition(req CreateConditionRequest) (HealthCondition, error) {
+	references, err := json.Marshal(nonNilReferences(req.References))
+	if err != nil {
+		return HealthCondition{}, err
+	}
+
+	var id int
+	err = db.QueryRow(`
+		INSERT INTO health_conditions
+			(name, abbreviation, description, severity, genetic, screening_tests, reference_links)
+		VALUES ($1, $2, $3, $4, $5, COALESCE($6::text[], '{}'), $7)
+		ON CONFLICT ((lower(name))) DO NOTHING
+		RETURNING id
+	`, strings.TrimSpace(req.Name), req.Abbreviation, req.Description, req.Severity, req.Genetic,
+		pq.Array(req.ScreeningTests), references).Scan(&id)
+	if err == sql.ErrNoRows {
+		return HealthCondition{}, ErrConditionNameTaken
+	} else if err != nil {
+		return HealthCondition{}, err
+	}
+
+	return GetCondition(id)
+}
+
+// UpdateCondition changes the given fields of a health condition. A name that another
+// condition already has is left to idx_health_conditions_name, so concurrent renames
+// cannot both pass.
+func UpdateCondition(conditionID int, req UpdateConditionRequest) (HealthCondition, error) {
+	var references []byte
+	if req.References != nil {
+		var err error
+		if references, err = json.Marshal(req.References); err != nil {
+			return HealthCondition{}, err
+		}
+	}
+
+	result, err := db.Exec(`
+		UPDATE health_conditions
+		SET name = COALESCE(NULLIF($2, ''), name),
+		    abbreviation = COALESCE($3, abbreviation),
+		    description = COALESCE($4, description),
+		    severity = COALESCE(NULLIF($5, '')::condition_severity_enum, severity),
+		    genetic = COALESCE($6, genetic),
+		    screening_tests = COALESCE($7::text[], screening_tests),
+		    reference_links = COALESCE($8::jsonb, reference_links)
+		WHERE id = $1
+	`, conditionID, strings.TrimSpace(req.Name), req.Abbreviation, req.Description, req.Severity, req.Genetic,
+		pq.Array(req.ScreeningTests), references)
+	if isConditionNameTaken(err) {
+		return HealthCondition{}, ErrConditionNameTaken
+	} else if err != nil {
+		return HealthCondition{}, err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return HealthCondition{}, err
+	}
+	if n == 0 {
+		return HealthCondition{}, sql.ErrNoRows
+	}
+
+	return GetCondition(conditionID)
+}
+
+// isConditionNameTaken reports whether err is a violation of the unique name index
+func isConditionNameTaken(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == "idx_health_conditions_name"
+}
+
+// DeleteCondition removes a health condition and its breed links
+func DeleteCondition(conditionID int) error {
+	result, err := db.Exec(`DELETE FROM health_conditions WHERE id = $1`, conditionID)
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// SetBreedCondition links a condition to an active breed, or updates the link
+func SetBreedCondition(catID, conditionID int, req BreedConditionRequest) (BreedCondition, error) {
+	_, err := db.Exec(`
+		INSERT INTO breed_health_conditions (breed_id, condition_id, prevalence, notes)
+		SELECT cb.id, hc.id, $3::condition_prevalence_enum, $4
+		FROM cat_breeds cb, health_conditions hc
+		WHERE cb.id = $1 AND cb.deleted_at IS NULL AND hc.id = $2
+		ON CONFLICT (breed_id, condition_id) DO UPDATE
+		SET prevalence = EXCLUDED.prevalence, notes = EXCLUDED.notes
+	`, catID, conditionID, req.Prevalence, req.Notes)
+	if err != nil {
+		return BreedCondition{}, err
+	}
+
+	conditions, err := GetCatConditions(catID)
+	if err != nil {
+		return BreedCondition{}, err
+	}
+	for _, c := range conditions {
+		if c.ID == conditionID {
+			return c, nil
+		}
+	}
+
+	// breed ถูกลบไปแล้วหรือไม่มีโรคนี้ จึงไม่มีแถวถูกเพิ่ม
+	return BreedCondition{}, sql.ErrNoRows
+}
+
+// RemoveBreedCondition unlinks a condition from a breed
+func RemoveBreedCondition(catID, conditionID int) error {
+	result, err := db.Exec(`
+		DELETE FROM breed_health_conditions WHERE breed_id = $1 AND condition_id = $2
+	`, catID, conditionID)
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// GetCatConditions lists the conditions of an active breed, most prevalent and severe first
+func GetCatConditions(catID int) ([]BreedCondition, error) {
+	active, err := isCatActive(catID)
+	if err != nil {
+		return nil, err
+	}
+	if !active {
+		return nil, sql.ErrNoRows
+	}
+
+	rows, err := db.Query(`
+		SELECT `+conditionColumns+`, bhc.prevalence, bhc.notes
+		FROM breed_health_conditions bhc
+		JOIN health_conditions hc ON hc.id = bhc.condition_id
+		WHERE bhc.breed_id = $1
+		ORDER BY bhc.prevalence DESC, hc.severity DESC, lower(hc.name)
+	`, catID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	conditions := []BreedCondition{}
+	for rows.Next() {
+		var c BreedCondition
+		c.HealthCondition, err = scanCondition(rows, &c.Prevalence, &c.Notes)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, c)
+	}
+
+	return conditions, rows.Err()
+}
+
+// GetConditionCats lists the active breeds affected by a condition, most prevalent first
+func GetConditionCats(conditionID int, currentUserID *int) ([]ConditionBreed, error) {
+	rows, err := db.Query(`
+		SELECT bhc.breed_id, bhc.prevalence, bhc.notes
+		FROM breed_health_conditions bhc
+		JOIN cat_breeds cb ON cb.id = bhc.breed_id AND cb.deleted_at IS NULL
+		WHERE bhc.condition_id = $1
+		ORDER BY bhc.prevalence DESC, cb.name
+	`, conditionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	links := map[int]ConditionBreed{}
+	for rows.Next() {
+		var id int
+		var link ConditionBreed
+		if err := rows.Scan(&id, &link.Prevalence, &link.Notes); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+		links[id] = link
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	cats, err := GetCatsByIDs(ids, currentUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	breeds := make([]ConditionBreed, len(cats))
+	for i, cat := range cats {
+		link := links[cat.ID]
+		link.Cat = cat
+		breeds[i] = link
+	}
+
+	return breeds, nil
+}
+
+func scanCondition(scanner rowScanner, extra ...any) (HealthCondition, error) {
+	var c HealthCondition
+	var references []byte
+
+	dest := []any{
+		&c.ID, &c.Name, &c.Abbreviation, &c.Description, &c.Severity, &c.Genetic,
+		pq.Array(&c.ScreeningTests), &references, &c.BreedCount, &c.CreatedAt, &c.UpdatedAt,
+	}
+	if err := scanner.Scan(append(dest, extra...)...); err != nil {
+		return HealthCondition{}, err
+	}
+
+	if err := json.Unmarshal(references, &c.References); err != nil {
+		return HealthCondition{}, err
+	}
+	if c.ScreeningTests == nil {
+		c.ScreeningTests = []string{}
+	}
+	c.References = nonNilReferences(c.References)
+
+	return c, nil
+}
+
+func nonNilReferences(references []ConditionReference) []ConditionReference {
+	if references == nil {
+		return []ConditionReference{}
+	}
+	return references
+}
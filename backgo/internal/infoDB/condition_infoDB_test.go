@@ -0,0 +1,27 @@
+package infoDB
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestIsConditionNameTaken(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&pq.Error{Code: "23505", Constraint: "idx_health_conditions_name"}, true},
+		{&pq.Error{Code: "23505", Constraint: "idx_collections_user_name"}, false},
+		{&pq.Error{Code: "23514", Constraint: "idx_health_conditions_name"}, false},
+		{errors.New("connection refused"), false},
+		{nil, false},
+	}
+
+	for _, tt := range tests {
+		if got := isConditionNameTaken(tt.err); got != tt.want {
+			t.Errorf("isConditionNameTaken(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,409 @@
+package infoDB
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"backgo/internal/markdown"
+
+	"github.com/lib/pq"
+)
+
+// ===================== Discussion Thread & Sort Config =====================
+
+type DiscussionConfig struct {
+	// reply ลึกได้ไม่เกิน MaxDepth ชั้นใต้ comment ระดับบนสุด
+	MaxDepth int
+	// แก้ไขภายใน EditGrace หลังโพสต์จะไม่ถูกบันทึกใน history
+	EditGrace time.Duration
+	// false = เฉพาะ admin ดู edit history ได้
+	PublicEditHistory bool
+}
+
+var discussionConfig = DiscussionConfig{MaxDepth: 8, EditGrace: 5 * time.Minute}
+
+const DefaultReplyDepth = 3
+const DefaultReplyLimit = 20
+const MaxReplyLimit = 100
+
+// DiscussionSorts are the orders of top-level discussions (?sort=); replies stay oldest first
+var DiscussionSorts = []string{"newest", "oldest", "top", "controversial", "best"}
+
+// discussionSort คือคอลัมน์ที่ใช้เรียง (มี index ของ comment ระดับบนสุดรองรับ) และ type ของค่าใน cursor
+type discussionSort struct {
+	column    string
+	cast      string
+	ascending bool
+}
+
+var discussionSortColumns = map[string]discussionSort{
+	"newest":        {"d.created_at", "timestamptz", false},
+	"oldest":        {"d.created_at", "timestamptz", true},
+	"top":           {"d.net_score", "int", false},
+	"controversial": {"d.controversy_score", "float8", false},
+	"best":          {"d.best_score", "float8", false},
+}
+
+var (
+	ErrInvalidDiscussionSort    = errors.New("invalid sort")
+	ErrInvalidDiscussionCursor  = errors.New("invalid cursor")
+	ErrDiscussionParentNotFound = errors.New("parent discussion not found")
+	ErrDiscussionParentMismatch = errors.New("parent discussion belongs to another breed")
+	ErrDiscussionTooDeep        = errors.New("reply would exceed the maximum thread depth")
+)
+
+// SetDiscussionConfig configures thread depth and edit history
+func SetDiscussionConfig(cfg DiscussionConfig) {
+	discussionConfig = cfg
+}
+
+// DiscussionMaxDepth returns the maximum number of reply levels
+func DiscussionMaxDepth() int {
+	return discussionConfig.MaxDepth
+}
+
+// PublicEditHistory reports whether everyone may see discussion edit history
+func PublicEditHistory() bool {
+	return discussionConfig.PublicEditHistory
+}
+
+// discussionColumns คือคอลัมน์ของ discussions (alias d) + users (u) + discussion_reactions (dr)
+// ตามลำดับที่ scanDiscussion อ่าน
+const discussionColumns = `
+	d.id, d.breed_id, d.user_id, u.username, d.parent_id, d.depth,
+	d.message, d.like_count, d.dislike_count, d.reply_count,
+	d.is_deleted, d.edited_at, d.edit_count, d.created_at, d.updated_at,
+	dr.reaction_type`
+
+// scanDiscussion scans the discussionColumns of a row into discussion, followed by any extra columns
+func scanDiscussion(scanner rowScanner, discussion *Discussion, extra ...any) error {
+	var parentID sql.NullInt64
+	var userReaction sql.NullString
+
+	dest := []any{
+		&discussion.ID, &discussion.BreedID, &discussion.UserID, &discussion.Username,
+		&parentID, &discussion.Depth, &discussion.Message, &discussion.LikeCount, &discussion.DislikeCount,
+		&discussion.ReplyCount, &discussion.IsDeleted, &discussion.EditedAt, &discussion.EditCount,
+		&discussion.CreatedAt, &discussion.UpdatedAt, &userReaction,
+	}
+
+	if err := scanner.Scan(append(dest, extra...)...); err != nil {
+		return err
+	}
+
+	if parentID.Valid {
+		pid := int(parentID.Int64)
+		discussion.ParentID = &pid
+	}
+
+	if userReaction.Valid {
+		discussion.UserReaction = &userReaction.String
+	}
+
+	discussion.MessageHTML = markdown.Render(discussion.Message)
+
+	return nil
+}
+
+// ===================== Discussion Thread Functions =====================
+
+// GetDiscussionThread gets a discussion with its replies nested up to depth
+// levels below it, at most limit replies per node (oldest first)
+func GetDiscussionThread(discussionID int, currentUserID *int, depth, limit int) (Discussion, error) {
+	discussion, err := getDiscussion(discussionID, currentUserID)
+	if err != nil {
+		return Discussion{}, err
+	}
+
+	trees, err := loadReplyTrees([]int{discussion.ID}, currentUserID, depth, limit, 0)
+	if err != nil {
+		return Discussion{}, err
+	}
+	discussion.Replies = trees[discussion.ID]
+
+	list := []Discussion{discussion}
+	if err := attachDiscussionMentions(list); err != nil {
+		return Discussion{}, err
+	}
+
+	return list[0], nil
+}
+
+// GetDiscussionReplies gets a page of direct replies to a discussion ("load more"),
+// each with its own replies nested up to depth levels below the parent.
+// Also returns the parent's total number of direct replies.
+func GetDiscussionReplies(parentID int, currentUserID *int, depth, limit, offset int) ([]Discussion, int, error) {
+	parent, err := getDiscussion(parentID, currentUserID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	trees, err := loadReplyTrees([]int{parent.ID}, currentUserID, depth, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	replies := trees[parent.ID]
+	if replies == nil {
+		replies = []Discussion{}
+	}
+	if err := attachDiscussionMentions(replies); err != nil {
+		return nil, 0, err
+	}
+
+	return replies, parent.ReplyCount, nil
+}
+
+// getDiscussion gets a single discussion of an active breed
+func getDiscussion(discussionID int, currentUserID *int) (Discussion, error) {
+	var userID int
+	if currentUserID != nil {
+		userID = *currentUserID
+	}
+
+	var discussion Discussion
+	err := scanDiscussion(db.QueryRow(`
+		SELECT `+discussionColumns+`
+		FROM discussions d
+		JOIN users u ON d.user_id = u.id
+		LEFT JOIN discussion_reactions dr ON d.id = dr.discussion_id AND dr.user_id = $1
+		JOIN cat_breeds cb ON d.breed_id = cb.id AND cb.deleted_at IS NULL
+		WHERE d.id = $2
+	`, userID, discussionID), &discussion)
+
+	return discussion, err
+}
+
+// loadReplyTrees loads the replies below every parent in one query and returns
+// them nested, keyed by parent id. The first level is paged with limit/offset
+// per parent; every deeper node keeps its first limit replies.
+func loadReplyTrees(parentIDs []int, currentUserID *int, depth, limit, offset int) (map[int][]Discussion, error) {
+	var userID int
+	if currentUserID != nil {
+		userID = *currentUserID
+	}
+
+	ids := make([]int64, len(parentIDs))
+	for i, id := range parentIDs {
+		ids[i] = int64(id)
+	}
+
+	// LATERAL ... LIMIT ตัดจำนวน reply ต่อ node ระหว่าง recursion เลย ไม่ต้องดึงทั้ง subtree มาก่อน
+	rows, err := db.Query(`
+		WITH RECURSIVE tree AS (
+			SELECT page.id, 1 AS level
+			FROM unnest($2::int[]) AS parent(id)
+			CROSS JOIN LATERAL (
+				SELECT id FROM discussions
+				WHERE parent_id = parent.id
+				ORDER BY created_at, id
+				LIMIT $4 OFFSET $5
+			) page
+			UNION ALL
+			SELECT child.id, tree.level + 1
+			FROM tree
+			CROSS JOIN LATERAL (
+				SELECT id FROM discussions
+				WHERE parent_id = tree.id
+				ORDER BY created_at, id
+				LIMIT $4
+			) child
+			WHERE tree.level < $3
+		)
+		SELECT `+discussionColumns+`
+		FROM tree
+		JOIN discussions d ON d.id = tree.id
+		JOIN users u ON d.user_id = u.id
+		LEFT JOIN discussion_reactions dr ON d.id = dr.discussion_id AND dr.user_id = $1
+		ORDER BY tree.level, d.created_at, d.id
+	`, userID, pq.Array(ids), depth, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	children := map[int][]Discussion{}
+	for rows.Next() {
+		var discussion Discussion
+		if err := scanDiscussion(rows, &discussion); err != nil {
+			return nil, err
+		}
+		children[*discussion.ParentID] = append(children[*discussion.ParentID], discussion)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	trees := make(map[int][]Discussion, len(parentIDs))
+	for _, id := range parentIDs {
+		trees[id] = buildReplyTree(children, id)
+	}
+
+	return trees, nil
+}
+
+// buildReplyTree nests the replies of parentID (and theirs, recursively)
+func buildReplyTree(children map[int][]Discussion, parentID int) []Discussion {
+	replies := children[parentID]
+	for i := range replies {
+		replies[i].Replies = buildReplyTree(children, replies[i].ID)
+	}
+	return replies
+}
+
+// replyDepth validates the parent of a new discussion and returns the depth of the reply
+func replyDepth(breedID int, parentID *int) (int, error) {
+	if parentID == nil {
+		return 0, nil
+	}
+
+	var parentBreedID, depth int
+	err := db.QueryRow(`
+		SELECT breed_id, depth + 1 FROM discussions WHERE id = $1
+	`, *parentID).Scan(&parentBreedID, &depth)
+	if err == sql.ErrNoRows {
+		return 0, ErrDiscussionParentNotFound
+	} else if err != nil {
+		return 0, err
+	}
+
+	if parentBreedID != breedID {
+		return 0, ErrDiscussionParentMismatch
+	}
+	if depth > discussionConfig.MaxDepth {
+		return 0, ErrDiscussionTooDeep
+	}
+
+	return depth, nil
+}
+
+// ===================== Discussion Edit History =====================
+
+// DiscussionEdit is a previous version of a discussion message
+type DiscussionEdit struct {
+	ID       int       `json:"id"`
+	Message  string    `json:"message"`
+	EditedBy *int      `json:"edited_by"`
+	EditedAt time.Time `json:"edited_at"` // เวลาที่ข้อความนี้ถูกแทนที่
+}
+
+type DiscussionHistory struct {
+	DiscussionID int              `json:"discussion_id"`
+	Message      string           `json:"message"`
+	EditCount    int              `json:"edit_count"`
+	EditedAt     *time.Time       `json:"edited_at"`
+	Edits        []DiscussionEdit `json:"edits"` // ใหม่สุดก่อน
+}
+
+// GetDiscussionHistory gets the recorded edits of a discussion. History of a
+// deleted discussion is only returned to moderators.
+func GetDiscussionHistory(discussionID int, moderator bool) (DiscussionHistory, error) {
+	history := DiscussionHistory{DiscussionID: discussionID, Edits: []DiscussionEdit{}}
+
+	var isDeleted bool
+	err := db.QueryRow(`
+		SELECT d.message, d.edit_count, d.edited_at, d.is_deleted
+		FROM discussions d
+		JOIN cat_breeds cb ON d.breed_id = cb.id AND cb.deleted_at IS NULL
+		WHERE d.id = $1
+	`, discussionID).Scan(&history.Message, &history.EditCount, &history.EditedAt, &isDeleted)
+	if err != nil {
+		return DiscussionHistory{}, err
+	}
+	if isDeleted && !moderator {
+		return DiscussionHistory{}, sql.ErrNoRows
+	}
+
+	rows, err := db.Query(`
+		SELECT id, message, edited_by, created_at
+		FROM discussion_edits
+		WHERE discussion_id = $1
+		ORDER BY created_at DESC, id DESC
+	`, discussionID)
+	if err != nil {
+		return DiscussionHistory{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var edit DiscussionEdit
+		if err := rows.Scan(&edit.ID, &edit.Message, &edit.EditedBy, &edit.EditedAt); err != nil {
+			return DiscussionHistory{}, err
+		}
+		history.Edits = append(history.Edits, edit)
+	}
+	if err := rows.Err(); err != nil {
+		return DiscussionHistory{}, err
+	}
+
+	return history, nil
+}
+
+// ===================== Discussion Cursors =====================
+
+// discussionCursor encodes the position after a row: sort, sort key and id
+func discussionCursor(sort, key string, id int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(sort + "|" + key + "|" + strconv.Itoa(id)))
+}
+
+// parseDiscussionCursor decodes a cursor made by discussionCursor for the same sort
+func parseDiscussionCursor(sort, cursor string) (string, int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", 0, ErrInvalidDiscussionCursor
+	}
+
+	parts := strings.Split(string(raw), "|")
+	if len(parts) != 3 || parts[0] != sort {
+		return "", 0, ErrInvalidDiscussionCursor
+	}
+
+	id, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return "", 0, ErrInvalidDiscussionCursor
+	}
+
+	return parts[1], id, nil
+}
+
+// formatCursorKey turns a scanned sort key into its cursor form (exact for floats)
+func formatCursorKey(key any) string {
+	switch v := key.(type) {
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	return ""
+}
+
+// decimalFloat is the float syntax formatCursorKey produces; ParseFloat alone also
+// accepts hex floats, underscores and inf/nan, which Postgres would not cast
+var decimalFloat = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)([eE][+-]?\d+)?$`)
+
+// validateCursorKey rejects cursor keys that Postgres could not cast
+func validateCursorKey(cast, key string) error {
+	var err error
+	switch cast {
+	case "timestamptz":
+		_, err = time.Parse(time.RFC3339Nano, key)
+	case "int":
+		// int ของ Postgres คือ 32 bit
+		_, err = strconv.ParseInt(key, 10, 32)
+	case "float8":
+		if !decimalFloat.MatchString(key) {
+			return ErrInvalidDiscussionCursor
+		}
+		_, err = strconv.ParseFloat(key, 64)
+	}
+	if err != nil {
+		return ErrInvalidDiscussionCursor
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package infoDB
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// ===================== Fake Driver =====================
+
+// countingConnector is a database/sql driver that counts queries and answers
+// the discussion listing with generated rows: a page of top-level comments,
+// then repliesPerParent replies for every parent asked for.
+type countingConnector struct {
+	queries          atomic.Int64
+	repliesPerParent int
+}
+
+func (c *countingConnector) Connect(context.Context) (driver.Conn, error) {
+	return countingConn{c}, nil
+}
+func (c *countingConnector) Driver() driver.Driver { return nil }
+
+type countingConn struct{ c *countingConnector }
+
+func (countingConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (countingConn) Close() error                        { return nil }
+func (countingConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (conn countingConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	conn.c.queries.Add(1)
+
+	now := time.Now()
+	row := func(id int64, parentID any, depth int64) []driver.Value {
+		return []driver.Value{id, int64(1), int64(1), "user", parentID, depth,
+			"message", int64(0), int64(0), int64(0), false, nil, int64(0), now, now, nil}
+	}
+
+	var rows [][]driver.Value
+	if strings.Contains(query, "FROM discussion_mentions") {
+		// ไม่มี mention
+	} else if strings.Contains(query, "WITH RECURSIVE") {
+		for _, parent := range strings.Split(strings.Trim(args[1].Value.(string), "{}"), ",") {
+			parentID, err := strconv.ParseInt(parent, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			for i := 1; i <= conn.c.repliesPerParent; i++ {
+				rows = append(rows, row(parentID*1000+int64(i), parentID, 1))
+			}
+		}
+	} else {
+		// comment ระดับบนสุด + sort_key
+		for id := int64(1); id <= args[2].Value.(int64); id++ {
+			rows = append(rows, append(row(id, nil, 0), now))
+		}
+	}
+
+	return &fakeRows{rows: rows}, nil
+}
+
+type fakeRows struct{ rows [][]driver.Value }
+
+func (r *fakeRows) Columns() []string {
+	columns := strings.Split("id,breed_id,user_id,username,parent_id,depth,message,like_count,dislike_count,reply_count,is_deleted,edited_at,edit_count,created_at,updated_at,reaction_type,sort_key", ",")
+	if len(r.rows) > 0 {
+		return columns[:len(r.rows[0])]
+	}
+	return columns
+}
+func (r *fakeRows) Close() error { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func useCountingDB(tb testing.TB, repliesPerParent int) *countingConnector {
+	connector := &countingConnector{repliesPerParent: repliesPerParent}
+	previous := db
+	db = sql.OpenDB(connector)
+	tb.Cleanup(func() {
+		db.Close()
+		db = previous
+	})
+	return connector
+}
+
+// ===================== Tests =====================
+
+func TestGetCatDiscussionsQueryCount(t *testing.T) {
+	for _, pageSize := range []int{1, 20, 100} {
+		connector := useCountingDB(t, 3)
+
+		discussions, nextCursor, err := GetCatDiscussions(1, nil, "newest", "", pageSize, 0)
+		if err != nil {
+			t.Fatalf("page size %d: %v", pageSize, err)
+		}
+		if len(discussions) != pageSize {
+			t.Fatalf("page size %d: got %d discussions", pageSize, len(discussions))
+		}
+		if nextCursor == "" {
+			t.Fatalf("page size %d: missing next cursor", pageSize)
+		}
+		for _, d := range discussions {
+			if len(d.Replies) != 3 {
+				t.Fatalf("page size %d: discussion %d has %d replies, want 3", pageSize, d.ID, len(d.Replies))
+			}
+		}
+
+		// หน้าละ 1 query สำหรับ comment ระดับบนสุด + 1 query สำหรับ reply ทั้งหน้า
+		// + 1 query สำหรับ mention ทั้งหน้า
+		if n := connector.queries.Load(); n != 3 {
+			t.Errorf("page size %d: %d queries, want 3", pageSize, n)
+		}
+	}
+}
+
+func TestDiscussionCursor(t *testing.T) {
+	score := 0.1 + 0.2 // ต้องได้ค่าเดิมทุก bit หลัง encode/decode
+	cursor := discussionCursor("best", formatCursorKey(score), 42)
+
+	key, id, err := parseDiscussionCursor("best", cursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 42 {
+		t.Errorf("id = %d, want 42", id)
+	}
+	if err := validateCursorKey("float8", key); err != nil {
+		t.Fatal(err)
+	}
+	if parsed, _ := strconv.ParseFloat(key, 64); parsed != score {
+		t.Errorf("key = %s, want %v", key, score)
+	}
+
+	created := time.Date(2024, 5, 1, 10, 30, 0, 123456000, time.UTC)
+	key, _, err = parseDiscussionCursor("oldest", discussionCursor("oldest", formatCursorKey(created), 7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed, _ := time.Parse(time.RFC3339Nano, key); !parsed.Equal(created) {
+		t.Errorf("key = %s, want %v", key, created)
+	}
+
+	for _, bad := range []string{"", "%%%", discussionCursor("top", "3", 1), "bmV3ZXN0fHh8eQ"} {
+		if _, _, err := parseDiscussionCursor("best", bad); err != ErrInvalidDiscussionCursor {
+			t.Errorf("cursor %q: err = %v, want ErrInvalidDiscussionCursor", bad, err)
+		}
+	}
+	if err := validateCursorKey("timestamptz", "yesterday'); DROP TABLE discussions; --"); err != ErrInvalidDiscussionCursor {
+		t.Errorf("err = %v, want ErrInvalidDiscussionCursor", err)
+	}
+}
+
+func TestValidateCursorKey(t *testing.T) {
+	tests := []struct {
+		cast, key string
+		valid     bool
+	}{
+		{"int", "42", true},
+		{"int", "-7", true},
+		{"int", "2147483647", true},
+		{"int", "2147483648", false},
+		{"int", "1_0", false},
+		{"int", "0x10", false},
+		{"float8", "0.30000000000000004", true},
+		{"float8", "-1.5e-07", true},
+		{"float8", "1e+21", true},
+		{"float8", ".5", true},
+		{"float8", "0x1p-2", false},
+		{"float8", "1_0", false},
+		{"float8", "inf", false},
+		{"float8", "-Infinity", false},
+		{"float8", "NaN", false},
+		{"float8", "1e999", false},
+		{"float8", "", false},
+	}
+
+	for _, tt := range tests {
+		err := validateCursorKey(tt.cast, tt.key)
+		if tt.valid && err != nil {
+			t.Errorf("%s %q: unexpected error %v", tt.cast, tt.key, err)
+		} else if !tt.valid && err != ErrInvalidDiscussionCursor {
+			t.Errorf("%s %q: err = %v, want ErrInvalidDiscussionCursor", tt.cast, tt.key, err)
+		}
+	}
+}
+
+// ===================== Benchmarks =====================
+
+func BenchmarkGetCatDiscussions(b *testing.B) {
+	for _, pageSize := range []int{10, 50, 200} {
+		b.Run(fmt.Sprintf("page=%d", pageSize), func(b *testing.B) {
+			connector := useCountingDB(b, 5)
+			b.ReportAllocs()
+
+			for b.Loop() {
+				if _, _, err := GetCatDiscussions(1, nil, "newest", "", pageSize, 0); err != nil {
+					b.Fatal(err)
+				}
+			}
+
+			b.ReportMetric(float64(connector.queries.Load())/float64(b.N), "queries/op")
+		})
+	}
+}
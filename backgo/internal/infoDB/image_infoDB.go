@@ -0,0 +1,533 @@
+package infoDB
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ===================== Breed Image Models =====================
+
+type ImageVariant struct {
+	URL         string `json:"url"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	ContentType string `json:"content_type"`
+}
+
+type BreedImage struct {
+	ID       int    `json:"id"`
+	BreedID  int    `json:"breed_id"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	Blurhash string `json:"blurhash"`
+	// thumb, small, medium, large, original
+	Variants map[string]ImageVariant `json:"variants"`
+
+	Caption     string `json:"caption"`
+	AltText     string `json:"alt_text"`
+	License     string `json:"license"`
+	Attribution string `json:"attribution"`
+	SortOrder   int    `json:"sort_order"`
+	IsCover     bool   `json:"is_cover"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ImageMetadataRequest is sent as form fields on upload
+type ImageMetadataRequest struct {
+	Caption     string `json:"caption" form:"caption" binding:"max=500"`
+	AltText     string `json:"alt_text" form:"alt_text" binding:"max=300"`
+	License     string `json:"license" form:"license" binding:"max=100"`
+	Attribution string `json:"attribution" form:"attribution" binding:"max=300"`
+}
+
+// UpdateImageMetadataRequest changes the given fields only
+type UpdateImageMetadataRequest struct {
+	Caption     *string `json:"caption" binding:"omitempty,max=500"`
+	AltText     *string `json:"alt_text" binding:"omitempty,max=300"`
+	License     *string `json:"license" binding:"omitempty,max=100"`
+	Attribution *string `json:"attribution" binding:"omitempty,max=300"`
+}
+
+type ReorderImagesRequest struct {
+	ImageIDs []int `json:"image_ids" binding:"required,min=1"`
+}
+
+type SetCoverImageRequest struct {
+	ImageID int `json:"image_id" binding:"required"`
+}
+
+// StoredImageVariant is how a variant is kept in breed_images.variants;
+// the public URL is derived from Key when the image is read
+type StoredImageVariant struct {
+	Key         string `json:"key"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	ContentType string `json:"content_type"`
+}
+
+type NewBreedImage struct {
+	SourceType string
+	Width      int
+	Height     int
+	Blurhash   string
+	Variants   map[string]StoredImageVariant
+	Metadata   ImageMetadataRequest
+}
+
+// ErrImageSetMismatch is returned when a reorder request does not list exactly the breed's images
+var ErrImageSetMismatch = errors.New("image_ids must list every image of the breed exactly once")
+
+// ErrInvalidImageURL is returned for an image_url that is not an absolute http(s) URL
+var ErrInvalidImageURL = errors.New("image_url must be an http or https URL")
+
+const breedImageColumns = `
+	bi.id, bi.breed_id, bi.width, bi.height, bi.blurhash, bi.variants,
+	bi.caption, bi.alt_text, bi.license, bi.attribution, bi.sort_order,
+	(cb.image_id IS NOT DISTINCT FROM bi.id) AS is_cover, bi.created_at`
+
+// ===================== Blob URL Config =====================
+
+var blobURL = func(key string) string { return "/media/" + key }
+
+// SetBlobURLResolver sets how stored blob keys are turned into public URLs
+func SetBlobURLResolver(resolve func(key string) string) {
+	blobURL = resolve
+}
+
+// validateImageURL checks an image_url given by hand (empty means "not given").
+// Uploaded images never go through here: their image_url is set from the cover.
+func validateImageURL(imageURL string) error {
+	if imageURL == "" {
+		return nil
+	}
+
+	u, err := url.Parse(imageURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidImageURL
+	}
+	return nil
+}
+
+// ===================== Breed Image Functions =====================
+
+// AddCatImage adds an uploaded image to the end of the breed's gallery. The image
+// becomes the cover when setCover is true or the breed has no cover yet.
+func AddCatImage(catID, userID int, img NewBreedImage, setCover bool) (BreedImage, error) {
+	variantsJSON, err := json.Marshal(img.Variants)
+	if err != nil {
+		return BreedImage{}, err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return BreedImage{}, err
+	}
+	defer tx.Rollback()
+
+	var coverID sql.NullInt64
+	err = tx.QueryRow(`
+		SELECT image_id FROM cat_breeds WHERE id = $1 AND deleted_at IS NULL FOR UPDATE
+	`, catID).Scan(&coverID)
+	if err != nil {
+		return BreedImage{}, err
+	}
+
+	var imageID int
+	meta := img.Metadata
+	err = tx.QueryRow(`
+		INSERT INTO breed_images
+			(breed_id, source_type, width, height, blurhash, variants,
+			 caption, alt_text, license, attribution, sort_order, uploaded_by)
+		SELECT $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, COALESCE(MAX(sort_order), -1) + 1, $11
+		FROM breed_images
+		WHERE breed_id = $1
+		RETURNING id
+	`, catID, img.SourceType, img.Width, img.Height, img.Blurhash, variantsJSON,
+		meta.Caption, meta.AltText, meta.License, meta.Attribution, userID).Scan(&imageID)
+	if err != nil {
+		return BreedImage{}, err
+	}
+
+	if setCover || !coverID.Valid {
+		if err := setCoverImage(tx, catID, imageID, userID); err != nil {
+			return BreedImage{}, err
+		}
+	}
+
+	image, err := getBreedImage(tx, catID, imageID)
+	if err != nil {
+		return BreedImage{}, err
+	}
+
+	return image, tx.Commit()
+}
+
+// UpdateCatImage updates the caption, alt text, license or attribution of a gallery
+// image; fields left out keep their value
+func UpdateCatImage(catID, imageID int, req UpdateImageMetadataRequest) (BreedImage, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return BreedImage{}, err
+	}
+	defer tx.Rollback()
+
+	active, err := isCatActive(catID)
+	if err != nil {
+		return BreedImage{}, err
+	}
+	if !active {
+		return BreedImage{}, sql.ErrNoRows
+	}
+
+	result, err := tx.Exec(`
+		UPDATE breed_images
+		SET caption = COALESCE($1, caption),
+		    alt_text = COALESCE($2, alt_text),
+		    license = COALESCE($3, license),
+		    attribution = COALESCE($4, attribution)
+		WHERE id = $5 AND breed_id = $6
+	`, req.Caption, req.AltText, req.License, req.Attribution, imageID, catID)
+	if err != nil {
+		return BreedImage{}, err
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return BreedImage{}, sql.ErrNoRows
+	}
+
+	image, err := getBreedImage(tx, catID, imageID)
+	if err != nil {
+		return BreedImage{}, err
+	}
+
+	return image, tx.Commit()
+}
+
+// ReorderCatImages sets the gallery order; imageIDs must list every image of the breed once
+func ReorderCatImages(catID int, imageIDs []int) ([]BreedImage, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	active, err := isCatActive(catID)
+	if err != nil {
+		return nil, err
+	}
+	if !active {
+		return nil, sql.ErrNoRows
+	}
+
+	ids := make([]int64, len(imageIDs))
+	for i, id := range imageIDs {
+		ids[i] = int64(id)
+	}
+
+	// ตรวจว่า image_ids ตรงกับรูปทั้งหมดของ breed พอดี (ไม่ขาด ไม่เกิน ไม่ซ้ำ)
+	var total, matched, distinct int
+	err = tx.QueryRow(`
+		SELECT
+			(SELECT COUNT(*) FROM breed_images WHERE breed_id = $1),
+			(SELECT COUNT(*) FROM breed_images WHERE breed_id = $1 AND id = ANY($2)),
+			(SELECT COUNT(DISTINCT x) FROM unnest($2::int[]) AS x)
+	`, catID, pq.Array(ids)).Scan(&total, &matched, &distinct)
+	if err != nil {
+		return nil, err
+	}
+	if total != len(ids) || matched != len(ids) || distinct != len(ids) {
+		return nil, ErrImageSetMismatch
+	}
+
+	_, err = tx.Exec(`
+		UPDATE breed_images bi
+		SET sort_order = o.position - 1
+		FROM unnest($2::int[]) WITH ORDINALITY AS o(id, position)
+		WHERE bi.id = o.id AND bi.breed_id = $1
+	`, catID, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return GetCatGallery(catID)
+}
+
+// SetCatCoverImage designates a gallery image as the breed's cover
+func SetCatCoverImage(catID, imageID, userID int) (BreedImage, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return BreedImage{}, err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	err = tx.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM breed_images bi
+			JOIN cat_breeds cb ON bi.breed_id = cb.id AND cb.deleted_at IS NULL
+			WHERE bi.id = $1 AND bi.breed_id = $2
+		)
+	`, imageID, catID).Scan(&exists)
+	if err != nil {
+		return BreedImage{}, err
+	}
+	if !exists {
+		return BreedImage{}, sql.ErrNoRows
+	}
+
+	if err := setCoverImage(tx, catID, imageID, userID); err != nil {
+		return BreedImage{}, err
+	}
+
+	image, err := getBreedImage(tx, catID, imageID)
+	if err != nil {
+		return BreedImage{}, err
+	}
+
+	return image, tx.Commit()
+}
+
+// DeleteCatImage removes an image from the gallery and returns its blob keys.
+// If it was the cover, the first remaining image becomes the cover.
+func DeleteCatImage(catID, imageID, userID int) ([]string, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var coverID sql.NullInt64
+	err = tx.QueryRow(`
+		SELECT image_id FROM cat_breeds WHERE id = $1 FOR UPDATE
+	`, catID).Scan(&coverID)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := deleteBreedImageRows(tx, `id = $1 AND breed_id = $2`, imageID, catID)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	if coverID.Valid && int(coverID.Int64) == imageID {
+		var nextID sql.NullInt64
+		err = tx.QueryRow(`
+			SELECT id FROM breed_images WHERE breed_id = $1 ORDER BY sort_order, id LIMIT 1
+		`, catID).Scan(&nextID)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, err
+		}
+
+		if nextID.Valid {
+			err = setCoverImage(tx, catID, int(nextID.Int64), userID)
+		} else {
+			_, err = tx.Exec(`
+				UPDATE cat_breeds SET image_id = NULL, image_url = '', updated_at = CURRENT_TIMESTAMP WHERE id = $1
+			`, catID)
+			if err == nil {
+				err = recordCoverRevision(tx, catID, userID)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return keys, tx.Commit()
+}
+
+// GetCatGallery lists the gallery of a breed in display order
+func GetCatGallery(catID int) ([]BreedImage, error) {
+	rows, err := db.Query(`
+		SELECT `+breedImageColumns+`
+		FROM breed_images bi
+		JOIN cat_breeds cb ON bi.breed_id = cb.id AND cb.deleted_at IS NULL
+		WHERE bi.breed_id = $1
+		ORDER BY bi.sort_order, bi.id
+	`, catID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	gallery := []BreedImage{}
+	for rows.Next() {
+		image, err := scanBreedImage(rows)
+		if err != nil {
+			return nil, err
+		}
+		gallery = append(gallery, image)
+	}
+
+	return gallery, rows.Err()
+}
+
+// setCoverImage points cat_breeds.image_id (and the legacy image_url) at a gallery image
+func setCoverImage(tx *sql.Tx, catID, imageID, userID int) error {
+	// image_url ยังคงไว้สำหรับ client เดิม ชี้ไปที่ขนาด large ของรูป cover
+	var largeKey sql.NullString
+	err := tx.QueryRow(`
+		SELECT variants->'large'->>'key' FROM breed_images WHERE id = $1
+	`, imageID).Scan(&largeKey)
+	if err != nil {
+		return err
+	}
+
+	imageURL := ""
+	if largeKey.Valid {
+		imageURL = blobURL(largeKey.String)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE cat_breeds SET image_id = $1, image_url = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $3
+	`, imageID, imageURL, catID)
+	if err != nil {
+		return err
+	}
+
+	return recordCoverRevision(tx, catID, userID)
+}
+
+// recordCoverRevision records the breed as it is after its cover changed, so the
+// revision history shows image_url changes made through the gallery
+func recordCoverRevision(tx *sql.Tx, catID, userID int) error {
+	var cat Cat
+	err := scanCat(tx.QueryRow(`SELECT `+catColumns+` FROM cat_breeds cb WHERE cb.id = $1`, catID), &cat)
+	if err != nil {
+		return err
+	}
+
+	return recordBreedRevision(tx, cat, "update", nil, userID)
+}
+
+func getBreedImage(tx *sql.Tx, catID, imageID int) (BreedImage, error) {
+	row := tx.QueryRow(`
+		SELECT `+breedImageColumns+`
+		FROM breed_images bi
+		JOIN cat_breeds cb ON bi.breed_id = cb.id
+		WHERE bi.id = $1 AND bi.breed_id = $2
+	`, imageID, catID)
+
+	return scanBreedImage(row)
+}
+
+// deleteBreedImageRows deletes breed_images rows matching where and returns their blob keys
+func deleteBreedImageRows(tx *sql.Tx, where string, args ...any) ([]string, error) {
+	rows, err := tx.Query(`DELETE FROM breed_images WHERE `+where+` RETURNING variants`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var variantsJSON []byte
+		if err := rows.Scan(&variantsJSON); err != nil {
+			return nil, err
+		}
+
+		var variants map[string]StoredImageVariant
+		if err := json.Unmarshal(variantsJSON, &variants); err != nil {
+			return nil, err
+		}
+		for _, v := range variants {
+			keys = append(keys, v.Key)
+		}
+	}
+
+	return keys, rows.Err()
+}
+
+// attachCatImages loads the cover image of each cat that has one
+func attachCatImages(cats []Cat) error {
+	var ids []int64
+	for _, cat := range cats {
+		if cat.ImageID != nil {
+			ids = append(ids, int64(*cat.ImageID))
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	rows, err := db.Query(`
+		SELECT `+breedImageColumns+`
+		FROM breed_images bi
+		JOIN cat_breeds cb ON bi.breed_id = cb.id
+		WHERE bi.id = ANY($1)
+	`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	images := map[int]BreedImage{}
+	for rows.Next() {
+		image, err := scanBreedImage(rows)
+		if err != nil {
+			return err
+		}
+		images[image.ID] = image
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range cats {
+		if cats[i].ImageID == nil {
+			continue
+		}
+		if image, ok := images[*cats[i].ImageID]; ok {
+			cats[i].Image = &image
+		}
+	}
+
+	return nil
+}
+
+func scanBreedImage(scanner rowScanner) (BreedImage, error) {
+	var image BreedImage
+	var blurhash sql.NullString
+	var variantsJSON []byte
+
+	err := scanner.Scan(
+		&image.ID, &image.BreedID, &image.Width, &image.Height, &blurhash, &variantsJSON,
+		&image.Caption, &image.AltText, &image.License, &image.Attribution, &image.SortOrder,
+		&image.IsCover, &image.CreatedAt,
+	)
+	if err != nil {
+		return BreedImage{}, err
+	}
+	image.Blurhash = blurhash.String
+
+	var stored map[string]StoredImageVariant
+	if err := json.Unmarshal(variantsJSON, &stored); err != nil {
+		return BreedImage{}, err
+	}
+
+	image.Variants = make(map[string]ImageVariant, len(stored))
+	for name, v := range stored {
+		image.Variants[name] = ImageVariant{
+			URL:         blobURL(v.Key),
+			Width:       v.Width,
+			Height:      v.Height,
+			ContentType: v.ContentType,
+		}
+	}
+
+	return image, nil
+}
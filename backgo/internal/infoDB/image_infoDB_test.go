@@ -0,0 +1,29 @@
+package infoDB
+
+import "testing"
+
+func TestValidateImageURL(t *testing.T) {
+	tests := []struct {
+		url   string
+		valid bool
+	}{
+		{"", true},
+		{"https://images.unsplash.com/photo-1518791841217-8f162f1e1131", true},
+		{"http://example.com/cat.jpg", true},
+		{"javascript:alert(1)", false},
+		{"data:image/png;base64,AAAA", false},
+		{"/media/cats/1/large.jpg", false},
+		{"//evil.example/cat.jpg", false},
+		{"ftp://example.com/cat.jpg", false},
+		{"https://", false},
+	}
+
+	for _, tt := range tests {
+		err := validateImageURL(tt.url)
+		if tt.valid && err != nil {
+			t.Errorf("%q: unexpected error %v", tt.url, err)
+		} else if !tt.valid && err != ErrInvalidImageURL {
+			t.Errorf("%q: err = %v, want ErrInvalidImageURL", tt.url, err)
+		}
+	}
+}
@@ -0,0 +1,658 @@
+package infoDB
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// ===================== Breed Matcher Models =====================
+
+type MatcherOption struct {
+	Value string `json:"value"`
+	Label string `json:"label"`
+}
+
+type MatcherQuestion struct {
+	ID      string          `json:"id"`
+	Text    string          `json:"text"`
+	Options []MatcherOption `json:"options"`
+}
+
+type MatcherAnswers struct {
+	LivingSpace  string `json:"living_space" binding:"required,oneof=small_apartment large_apartment house house_with_garden"`
+	Allergies    string `json:"allergies" binding:"required,oneof=none mild severe"`
+	GroomingTime string `json:"grooming_time" binding:"required,oneof=minimal weekly daily"`
+	Kids         string `json:"kids" binding:"required,oneof=none older young"`
+	OtherPets    string `json:"other_pets" binding:"required,oneof=none cats dogs"`
+}
+
+// CriterionScore explains how one answer scored against a breed (score 0-1)
+type CriterionScore struct {
+	Criterion   string  `json:"criterion"`
+	Score       float64 `json:"score"`
+	Weight      float64 `json:"weight"`
+	Explanation string  `json:"explanation"`
+}
+
+type BreedMatch struct {
+	Rank int `json:"rank"`
+	// คะแนนรวมแบบถ่วงน้ำหนัก 0-100
+	Score    float64          `json:"score"`
+	Criteria []CriterionScore `json:"criteria"`
+	Cat      Cat              `json:"cat"`
+}
+
+type MatcherResult struct {
+	ID        *int           `json:"id,omitempty"`
+	Answers   MatcherAnswers `json:"answers"`
+	Matches   []BreedMatch   `json:"matches"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+type MatcherResultSummary struct {
+	ID         int            `json:"id"`
+	Answers    MatcherAnswers `json:"answers"`
+	MatchCount int            `json:"match_count"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+type MatcherWeight struct {
+	Criterion string     `json:"criterion"`
+	Weight    float64    `json:"weight"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	UpdatedBy *int       `json:"updated_by,omitempty"`
+}
+
+type UpdateMatcherWeightsRequest struct {
+	Weights map[string]float64 `json:"weights" binding:"required,min=1,dive,gte=0,lte=10"`
+}
+
+// savedMatch is how a match is stored in matcher_results.matches
+type savedMatch struct {
+	BreedID  int              `json:"breed_id"`
+	Rank     int              `json:"rank"`
+	Score    float64          `json:"score"`
+	Criteria []CriterionScore `json:"criteria"`
+}
+
+const DefaultMatcherResults = 10
+const MaxMatcherResults = 50
+
+// จำนวนผลลัพธ์ที่เก็บไว้ต่อผู้ใช้ ผลที่เก่ากว่านี้จะถูกลบ
+const maxSavedMatcherResults = 50
+
+var ErrUnknownMatcherCriterion = errors.New("unknown matcher criterion")
+var ErrMatcherWeightsZero = errors.New("at least one matcher weight must be greater than zero")
+
+// matcherCriterion ties a question to the breed attribute it is scored against
+type matcherCriterion struct {
+	question      MatcherQuestion
+	defaultWeight float64
+	answer        func(MatcherAnswers) string
+	score         func(answer string, attrs BreedAttributes) (float64, string)
+}
+
+var matcherCriteria = []matcherCriterion{
+	{
+		question: MatcherQuestion{
+			ID:   "living_space",
+			Text: "Where will your cat live?",
+			Options: []MatcherOption{
+				{"small_apartment", "Small apartment or condo"},
+				{"large_apartment", "Large apartment"},
+				{"house", "House"},
+				{"house_with_garden", "House with a garden"},
+			},
+		},
+		defaultWeight: 1,
+		answer:        func(a MatcherAnswers) string { return a.LivingSpace },
+		score:         scoreLivingSpace,
+	},
+	{
+		question: MatcherQuestion{
+			ID:   "allergies",
+			Text: "Does anyone in your home have cat allergies?",
+			Options: []MatcherOption{
+				{"none", "No"},
+				{"mild", "Mild allergies"},
+				{"severe", "Severe allergies"},
+			},
+		},
+		defaultWeight: 2,
+		answer:        func(a MatcherAnswers) string { return a.Allergies },
+		score:         scoreAllergies,
+	},
+	{
+		question: MatcherQuestion{
+			ID:   "grooming_time",
+			Text: "How much time can you spend on grooming?",
+			Options: []MatcherOption{
+				{"minimal", "As little as possible"},
+				{"weekly", "A brushing session every week"},
+				{"daily", "Some time every day"},
+			},
+		},
+		defaultWeight: 1,
+		answer:        func(a MatcherAnswers) string { return a.GroomingTime },
+		score:         scoreGroomingTime,
+	},
+	{
+		question: MatcherQuestion{
+			ID:   "kids",
+			Text: "Are there children in your home?",
+			Options: []MatcherOption{
+				{"none", "No children"},
+				{"older", "Older children"},
+				{"young", "Young children"},
+			},
+		},
+		defaultWeight: 1.5,
+		answer:        func(a MatcherAnswers) string { return a.Kids },
+		score:         scoreKids,
+	},
+	{
+		question: MatcherQuestion{
+			ID:   "other_pets",
+			Text: "Do you have other pets?",
+			Options: []MatcherOption{
+				{"none", "No other pets"},
+				{"cats", "Other cats"},
+				{"dogs", "Dogs"},
+			},
+		},
+		defaultWeight: 1,
+		answer:        func(a MatcherAnswers) string { return a.OtherPets },
+		score:         scoreOtherPets,
+	},
+}
+
+// ===================== Breed Matcher Scoring =====================
+
+func scoreLivingSpace(answer string, attrs BreedAttributes) (float64, string) {
+	if attrs.ActivityLevel == nil {
+		return 0.5, "No activity level data for this breed"
+	}
+	activity := *attrs.ActivityLevel
+
+	limits := map[string]int{"small_apartment": 2, "large_apartment": 3, "house": 4, "house_with_garden": 5}
+	space := map[string]string{
+		"small_apartment": "a small apartment", "large_apartment": "a large apartment",
+		"house": "a house", "house_with_garden": "a house with a garden",
+	}
+
+	score := atMost(activity, limits[answer])
+	if score == 1 {
+		return score, fmt.Sprintf("Activity level %d/5 suits %s", activity, space[answer])
+	}
+	return score, fmt.Sprintf("Activity level %d/5 may be too energetic for %s", activity, space[answer])
+}
+
+func scoreAllergies(answer string, attrs BreedAttributes) (float64, string) {
+	if answer == "none" {
+		return 1, "No allergy concerns"
+	}
+	if attrs.Hypoallergenic != nil && *attrs.Hypoallergenic {
+		return 1, "Hypoallergenic breed"
+	}
+	if attrs.SheddingLevel == nil {
+		if attrs.Hypoallergenic == nil {
+			return 0.5, "No allergy or shedding data for this breed"
+		}
+		if answer == "severe" {
+			return 0, "Not hypoallergenic"
+		}
+		return 0.5, "Not hypoallergenic"
+	}
+
+	shedding := *attrs.SheddingLevel
+	if answer == "severe" && attrs.Hypoallergenic != nil {
+		return 0, fmt.Sprintf("Not hypoallergenic and sheds at level %d/5", shedding)
+	}
+
+	// ขนร่วงน้อย = สารก่อภูมิแพ้น้อยกว่า
+	score := atMost(shedding, 1)
+	if answer == "severe" {
+		score /= 2
+	}
+	return score, fmt.Sprintf("Sheds at level %d/5", shedding)
+}
+
+func scoreGroomingTime(answer string, attrs BreedAttributes) (float64, string) {
+	if attrs.GroomingLevel == nil {
+		return 0.5, "No grooming data for this breed"
+	}
+	grooming := *attrs.GroomingLevel
+
+	limits := map[string]int{"minimal": 2, "weekly": 3, "daily": 5}
+
+	score := atMost(grooming, limits[answer])
+	if score == 1 {
+		return score, fmt.Sprintf("Grooming needs %d/5 fit the time you have", grooming)
+	}
+	return score, fmt.Sprintf("Grooming needs %d/5 are more than the time you have", grooming)
+}
+
+func scoreKids(answer string, attrs BreedAttributes) (float64, string) {
+	if answer == "none" {
+		return 1, "No children to consider"
+	}
+	if attrs.ChildFriendly == nil {
+		return 0.5, "No child-friendliness data for this breed"
+	}
+	friendly := *attrs.ChildFriendly
+
+	needs := map[string]int{"older": 3, "young": 4}
+	who := map[string]string{"older": "older children", "young": "young children"}
+
+	score := atLeast(friendly, needs[answer])
+	if score == 1 {
+		return score, fmt.Sprintf("Child-friendliness %d/5 is good with %s", friendly, who[answer])
+	}
+	return score, fmt.Sprintf("Child-friendliness %d/5 may be low for %s", friendly, who[answer])
+}
+
+func scoreOtherPets(answer string, attrs BreedAttributes) (float64, string) {
+	if answer == "none" {
+		return 1, "No other pets to consider"
+	}
+	if attrs.PetFriendly == nil {
+		return 0.5, "No pet-friendliness data for this breed"
+	}
+	friendly := *attrs.PetFriendly
+
+	needs := map[string]int{"cats": 3, "dogs": 4}
+
+	score := atLeast(friendly, needs[answer])
+	if score == 1 {
+		return score, fmt.Sprintf("Pet-friendliness %d/5 is good with %s", friendly, answer)
+	}
+	return score, fmt.Sprintf("Pet-friendliness %d/5 may be low for living with %s", friendly, answer)
+}
+
+// atMost scores a 1-5 level that should not exceed limit
+func atMost(level, limit int) float64 {
+	if level <= limit {
+		return 1
+	}
+	return 1 - float64(level-limit)/float64(5-limit)
+}
+
+// atLeast scores a 1-5 level that should reach need
+func atLeast(level, need int) float64 {
+	if level >= need {
+		return 1
+	}
+	return float64(level-1) / float64(need-1)
+}
+
+// scoreBreed weighs every criterion into a 0-100 score
+func scoreBreed(answers MatcherAnswers, attrs BreedAttributes, weights map[string]float64) (float64, []CriterionScore) {
+	criteria := make([]CriterionScore, 0, len(matcherCriteria))
+	var total, weightSum float64
+
+	for _, c := range matcherCriteria {
+		score, explanation := c.score(c.answer(answers), attrs)
+		weight := weights[c.question.ID]
+
+		criteria = append(criteria, CriterionScore{
+			Criterion:   c.question.ID,
+			Score:       math.Round(score*100) / 100,
+			Weight:      weight,
+			Explanation: explanation,
+		})
+		total += score * weight
+		weightSum += weight
+	}
+
+	if weightSum == 0 {
+		return 0, criteria
+	}
+	return math.Round(total/weightSum*1000) / 10, criteria
+}
+
+// ===================== Breed Matcher Functions =====================
+
+// GetMatcherQuestions returns the questionnaire in the order it should be asked
+func GetMatcherQuestions() []MatcherQuestion {
+	questions := make([]MatcherQuestion, len(matcherCriteria))
+	for i, c := range matcherCriteria {
+		questions[i] = c.question
+	}
+	return questions
+}
+
+// MatchCats scores every active breed against answers and returns the best limit
+// matches. Results of a signed-in user are saved so they can be revisited.
+func MatchCats(answers MatcherAnswers, currentUserID *int, limit int) (MatcherResult, error) {
+	weights, err := matcherWeights()
+	if err != nil {
+		return MatcherResult{}, err
+	}
+
+	var userID int
+	if currentUserID != nil {
+		userID = *currentUserID
+	}
+
+	rows, err := db.Query(`
+		SELECT `+catColumns+`,
+			br.reaction_type as user_reaction
+		FROM cat_breeds cb
+		LEFT JOIN breed_reactions br ON cb.id = br.breed_id AND br.user_id = $1
+		WHERE cb.deleted_at IS NULL
+	`, userID)
+	if err != nil {
+		return MatcherResult{}, err
+	}
+	defer rows.Close()
+
+	matches := []BreedMatch{}
+	for rows.Next() {
+		var match BreedMatch
+		if err := scanCat(rows, &match.Cat, &match.Cat.UserReaction); err != nil {
+			return MatcherResult{}, err
+		}
+		match.Score, match.Criteria = scoreBreed(answers, match.Cat.Attributes, weights)
+		matches = append(matches, match)
+	}
+	if err := rows.Err(); err != nil {
+		return MatcherResult{}, err
+	}
+
+	// คะแนนเท่ากันให้สายพันธุ์ที่คนชอบมากกว่าขึ้นก่อน
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Cat.LikeCount != b.Cat.LikeCount {
+			return a.Cat.LikeCount > b.Cat.LikeCount
+		}
+		return a.Cat.Name < b.Cat.Name
+	})
+
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	for i := range matches {
+		matches[i].Rank = i + 1
+	}
+
+	cats := make([]Cat, len(matches))
+	for i := range matches {
+		cats[i] = matches[i].Cat
+	}
+	if err := attachCatImages(cats); err != nil {
+		return MatcherResult{}, err
+	}
+	for i := range matches {
+		matches[i].Cat = cats[i]
+	}
+
+	result := MatcherResult{Answers: answers, Matches: matches, CreatedAt: time.Now()}
+	if currentUserID == nil {
+		return result, nil
+	}
+
+	id, createdAt, err := saveMatcherResult(*currentUserID, answers, matches)
+	if err != nil {
+		return MatcherResult{}, err
+	}
+	result.ID = &id
+	result.CreatedAt = createdAt
+
+	return result, nil
+}
+
+// saveMatcherResult stores a snapshot of the matches and prunes the user's oldest results
+func saveMatcherResult(userID int, answers MatcherAnswers, matches []BreedMatch) (int, time.Time, error) {
+	saved := make([]savedMatch, len(matches))
+	for i, m := range matches {
+		saved[i] = savedMatch{BreedID: m.Cat.ID, Rank: m.Rank, Score: m.Score, Criteria: m.Criteria}
+	}
+
+	answersJSON, err := json.Marshal(answers)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	matchesJSON, err := json.Marshal(saved)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	defer tx.Rollback()
+
+	var id int
+	var createdAt time.Time
+	err = tx.QueryRow(`
+		INSERT INTO matcher_results (user_id, answers, matches)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`, userID, answersJSON, matchesJSON).Scan(&id, &createdAt)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+
+	_, err = tx.Exec(`
+		DELETE FROM matcher_results
+		WHERE user_id = $1 AND id NOT IN (
+			SELECT id FROM matcher_results
+			WHERE user_id = $1
+			ORDER BY created_at DESC, id DESC
+			LIMIT $2
+		)
+	`, userID, maxSavedMatcherResults)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+
+	return id, createdAt, tx.Commit()
+}
+
+// GetUserMatcherResults lists the saved results of a user, newest first
+func GetUserMatcherResults(userID, limit, offset int) ([]MatcherResultSummary, error) {
+	rows, err := db.Query(`
+		SELECT id, answers, jsonb_array_length(matches), created_at
+		FROM matcher_results
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []MatcherResultSummary{}
+	for rows.Next() {
+		var r MatcherResultSummary
+		var answersJSON []byte
+		if err := rows.Scan(&r.ID, &answersJSON, &r.MatchCount, &r.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(answersJSON, &r.Answers); err != nil {
+			return nil, err
+		}
+		results = append(results, r)
+	}
+
+	return results, rows.Err()
+}
+
+// GetUserMatcherResult loads a saved result with the current data of its breeds.
+// Breeds deleted since then are left out; the saved ranks and scores are kept.
+func GetUserMatcherResult(userID, resultID int) (MatcherResult, error) {
+	var answersJSON, matchesJSON []byte
+	result := MatcherResult{ID: &resultID}
+
+	err := db.QueryRow(`
+		SELECT answers, matches, created_at
+		FROM matcher_results
+		WHERE id = $1 AND user_id = $2
+	`, resultID, userID).Scan(&answersJSON, &matchesJSON, &result.CreatedAt)
+	if err != nil {
+		return MatcherResult{}, err
+	}
+
+	var saved []savedMatch
+	if err := json.Unmarshal(answersJSON, &result.Answers); err != nil {
+		return MatcherResult{}, err
+	}
+	if err := json.Unmarshal(matchesJSON, &saved); err != nil {
+		return MatcherResult{}, err
+	}
+
+	ids := make([]int, len(saved))
+	for i, m := range saved {
+		ids[i] = m.BreedID
+	}
+
+	cats, err := GetCatsByIDs(ids, &userID)
+	if err != nil {
+		return MatcherResult{}, err
+	}
+
+	byID := map[int]Cat{}
+	for _, cat := range cats {
+		byID[cat.ID] = cat
+	}
+
+	result.Matches = []BreedMatch{}
+	for _, m := range saved {
+		cat, ok := byID[m.BreedID]
+		if !ok {
+			continue
+		}
+		result.Matches = append(result.Matches, BreedMatch{Rank: m.Rank, Score: m.Score, Criteria: m.Criteria, Cat: cat})
+	}
+
+	return result, nil
+}
+
+// DeleteUserMatcherResult deletes a saved result of a user
+func DeleteUserMatcherResult(userID, resultID int) error {
+	res, err := db.Exec(`DELETE FROM matcher_results WHERE id = $1 AND user_id = $2`, resultID, userID)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// GetMatcherWeights lists the weight of every criterion in questionnaire order
+func GetMatcherWeights() ([]MatcherWeight, error) {
+	rows, err := db.Query(`SELECT criterion, weight, updated_at, updated_by FROM matcher_weights`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stored := map[string]MatcherWeight{}
+	for rows.Next() {
+		var w MatcherWeight
+		if err := rows.Scan(&w.Criterion, &w.Weight, &w.UpdatedAt, &w.UpdatedBy); err != nil {
+			return nil, err
+		}
+		stored[w.Criterion] = w
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// เกณฑ์ที่ยังไม่มีในตารางใช้น้ำหนักเริ่มต้น
+	weights := make([]MatcherWeight, len(matcherCriteria))
+	for i, c := range matcherCriteria {
+		w, ok := stored[c.question.ID]
+		if !ok {
+			w = MatcherWeight{Criterion: c.question.ID, Weight: c.defaultWeight}
+		}
+		weights[i] = w
+	}
+
+	return weights, nil
+}
+
+// UpdateMatcherWeights changes the weights of the given criteria; others keep their weight
+func UpdateMatcherWeights(userID int, req UpdateMatcherWeightsRequest) ([]MatcherWeight, error) {
+	current, err := matcherWeights()
+	if err != nil {
+		return nil, err
+	}
+
+	for criterion, weight := range req.Weights {
+		if _, ok := current[criterion]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownMatcherCriterion, criterion)
+		}
+		current[criterion] = weight
+	}
+
+	var sum float64
+	for _, weight := range current {
+		sum += weight
+	}
+	if sum == 0 {
+		return nil, ErrMatcherWeightsZero
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for _, c := range matcherCriteria {
+		weight, ok := req.Weights[c.question.ID]
+		if !ok {
+			continue
+		}
+
+		_, err := tx.Exec(`
+			INSERT INTO matcher_weights (criterion, weight, updated_by)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (criterion) DO UPDATE
+			SET weight = EXCLUDED.weight,
+			    updated_by = EXCLUDED.updated_by,
+			    updated_at = CURRENT_TIMESTAMP
+		`, c.question.ID, weight, userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return GetMatcherWeights()
+}
+
+// matcherWeights returns the weights keyed by criterion
+func matcherWeights() (map[string]float64, error) {
+	list, err := GetMatcherWeights()
+	if err != nil {
+		return nil, err
+	}
+
+	weights := make(map[string]float64, len(list))
+	for _, w := range list {
+		weights[w.Criterion] = w.Weight
+	}
+	return weights, nil
+}
@@ -0,0 +1,327 @@
+package infoDB
+
+import (
+	"database/sql"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/lib/pq"
+)
+
+// ===================== Mention Models =====================
+
+// MentionEntity is a resolved @username in a discussion message. Offset and
+// Length count characters (Unicode code points) of the markdown source and
+// cover the leading @.
+type MentionEntity struct {
+	UserID   int    `json:"user_id"`
+	Username string `json:"username"`
+	Offset   int    `json:"offset"`
+	Length   int    `json:"length"`
+}
+
+type MentionFeedItem struct {
+	MentionedAt time.Time  `json:"mentioned_at"`
+	BreedSlug   string     `json:"breed_slug"`
+	Discussion  Discussion `json:"discussion"`
+}
+
+// ข้อความหนึ่ง mention ได้ไม่เกิน maxMentionedUsers คน (ส่วนเกินเป็นข้อความธรรมดา)
+const maxMentionedUsers = 20
+
+// @ ต้องไม่อยู่ต่อจากตัวอักษร (กัน email) ชื่อยาว 3-50 ตัวอักษรตาม users.username
+var mentionPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{M}\p{N}_@/])@([\p{L}\p{M}\p{N}_][\p{L}\p{M}\p{N}_.\-]{2,49})`)
+
+type mentionCandidate struct {
+	name  string
+	start int // byte offset ของ @
+	end   int
+}
+
+// ===================== Mention Functions =====================
+
+// parseMentions finds @username candidates outside code spans and fenced code blocks
+func parseMentions(message string) []mentionCandidate {
+	code := codeRanges(message)
+
+	var candidates []mentionCandidate
+	for _, m := range mentionPattern.FindAllStringSubmatchIndex(message, -1) {
+		start, end := m[2]-1, m[3]
+		// "@bob." หรือ "@bob-" ท้ายประโยคไม่นับเครื่องหมาย
+		name := strings.TrimRight(message[m[2]:end], ".-")
+		end = m[2] + len(name)
+		if utf8.RuneCountInString(name) < 3 {
+			continue
+		}
+
+		inCode := false
+		for _, r := range code {
+			if start >= r[0] && start < r[1] {
+				inCode = true
+				break
+			}
+		}
+		if !inCode {
+			candidates = append(candidates, mentionCandidate{name: name, start: start, end: end})
+		}
+	}
+
+	return candidates
+}
+
+// codeRanges returns the byte ranges of `code` spans and ``` fenced blocks
+func codeRanges(message string) [][2]int {
+	var ranges [][2]int
+
+	inFence, fenceStart, pos := false, 0, 0
+	for _, line := range strings.SplitAfter(message, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			if inFence {
+				ranges = append(ranges, [2]int{fenceStart, pos + len(line)})
+			} else {
+				fenceStart = pos
+			}
+			inFence = !inFence
+		} else if !inFence {
+			for i := 0; i < len(line); {
+				if line[i] != '`' {
+					i++
+					continue
+				}
+				n := 0
+				for i+n < len(line) && line[i+n] == '`' {
+					n++
+				}
+				end := strings.Index(line[i+n:], strings.Repeat("`", n))
+				if end < 0 {
+					i += n
+					continue
+				}
+				ranges = append(ranges, [2]int{pos + i, pos + i + n + end + n})
+				i += n + end + n
+			}
+		}
+		pos += len(line)
+	}
+	if inFence {
+		ranges = append(ranges, [2]int{fenceStart, len(message)})
+	}
+
+	return ranges
+}
+
+// saveMentions resolves the mentions of a discussion message against users and
+// replaces its rows in discussion_mentions. A user who was already mentioned
+// keeps the original mention time. Returns the ids of newly mentioned users.
+func saveMentions(tx *sql.Tx, discussionID int, message string) ([]int, error) {
+	candidates := parseMentions(message)
+
+	previous := map[int]time.Time{}
+	rows, err := tx.Query(`
+		SELECT mentioned_user_id, MIN(created_at)
+		FROM discussion_mentions
+		WHERE discussion_id = $1
+		GROUP BY mentioned_user_id
+	`, discussionID)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var userID int
+		var createdAt time.Time
+		if err := rows.Scan(&userID, &createdAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		previous[userID] = createdAt
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM discussion_mentions WHERE discussion_id = $1`, discussionID); err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	users, err := resolveUsernames(tx, candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	var mentioned []int
+	seen := map[int]bool{}
+	for _, c := range candidates {
+		userID, ok := users[strings.ToLower(c.name)]
+		if !ok || (!seen[userID] && len(seen) >= maxMentionedUsers) {
+			continue
+		}
+
+		var createdAt any = nil
+		if t, ok := previous[userID]; ok {
+			createdAt = t
+		} else if !seen[userID] {
+			mentioned = append(mentioned, userID)
+		}
+		seen[userID] = true
+
+		_, err := tx.Exec(`
+			INSERT INTO discussion_mentions (discussion_id, mentioned_user_id, start_offset, length, created_at)
+			VALUES ($1, $2, $3, $4, COALESCE($5, CURRENT_TIMESTAMP))
+		`, discussionID, userID, utf8.RuneCountInString(message[:c.start]),
+			utf8.RuneCountInString(message[c.start:c.end]), createdAt)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return mentioned, nil
+}
+
+// resolveUsernames maps lowercased candidate names to active user ids. Matching
+// ignores case; an exact-case match wins when several users differ only in case.
+func resolveUsernames(tx *sql.Tx, candidates []mentionCandidate) (map[string]int, error) {
+	names := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		names = append(names, strings.ToLower(c.name))
+	}
+
+	rows, err := tx.Query(`
+		SELECT id, username
+		FROM users
+		WHERE lower(username) = ANY($1) AND is_active = TRUE
+		ORDER BY id
+	`, pq.Array(names))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	exact := map[string]bool{}
+	for _, c := range candidates {
+		exact[c.name] = true
+	}
+
+	users := map[string]int{}
+	for rows.Next() {
+		var id int
+		var username string
+		if err := rows.Scan(&id, &username); err != nil {
+			return nil, err
+		}
+		key := strings.ToLower(username)
+		if _, taken := users[key]; !taken || exact[username] {
+			users[key] = id
+		}
+	}
+
+	return users, rows.Err()
+}
+
+// attachDiscussionMentions loads the mention entities of discussions and all
+// their nested replies in one query
+func attachDiscussionMentions(discussions []Discussion) error {
+	byID := map[int][]*Discussion{}
+	var ids []int64
+	var walk func([]Discussion)
+	walk = func(list []Discussion) {
+		for i := range list {
+			d := &list[i]
+			d.Mentions = []MentionEntity{}
+			// ข้อความที่ถูกลบแล้วไม่มี mention (offset ไม่ตรงกับข้อความใหม่)
+			if !d.IsDeleted {
+				if _, seen := byID[d.ID]; !seen {
+					ids = append(ids, int64(d.ID))
+				}
+				byID[d.ID] = append(byID[d.ID], d)
+			}
+			walk(d.Replies)
+		}
+	}
+	walk(discussions)
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	rows, err := db.Query(`
+		SELECT m.discussion_id, m.mentioned_user_id, u.username, m.start_offset, m.length
+		FROM discussion_mentions m
+		JOIN users u ON u.id = m.mentioned_user_id
+		WHERE m.discussion_id = ANY($1)
+		ORDER BY m.discussion_id, m.start_offset
+	`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var discussionID int
+		var m MentionEntity
+		if err := rows.Scan(&discussionID, &m.UserID, &m.Username, &m.Offset, &m.Length); err != nil {
+			return err
+		}
+		for _, d := range byID[discussionID] {
+			d.Mentions = append(d.Mentions, m)
+		}
+	}
+
+	return rows.Err()
+}
+
+// GetUserMentions gets the discussions a user was mentioned in, newest mention first.
+// Deleted discussions, deleted breeds and self-mentions are left out.
+func GetUserMentions(userID, limit, offset int) ([]MentionFeedItem, error) {
+	rows, err := db.Query(`
+		SELECT `+discussionColumns+`, cb.slug, m.mentioned_at
+		FROM (
+			SELECT discussion_id, MIN(created_at) AS mentioned_at
+			FROM discussion_mentions
+			WHERE mentioned_user_id = $1
+			GROUP BY discussion_id
+		) m
+		JOIN discussions d ON d.id = m.discussion_id
+		JOIN users u ON d.user_id = u.id
+		LEFT JOIN discussion_reactions dr ON d.id = dr.discussion_id AND dr.user_id = $1
+		JOIN cat_breeds cb ON d.breed_id = cb.id AND cb.deleted_at IS NULL
+		WHERE d.is_deleted = FALSE AND d.user_id <> $1
+		ORDER BY m.mentioned_at DESC, d.id DESC
+		LIMIT $2 OFFSET $3
+	`, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []MentionFeedItem{}
+	for rows.Next() {
+		var item MentionFeedItem
+		if err := scanDiscussion(rows, &item.Discussion, &item.BreedSlug, &item.MentionedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	discussions := make([]Discussion, len(items))
+	for i := range items {
+		discussions[i] = items[i].Discussion
+	}
+	if err := attachDiscussionMentions(discussions); err != nil {
+		return nil, err
+	}
+	for i := range items {
+		items[i].Discussion = discussions[i]
+	}
+
+	return items, nil
+}
@@ -0,0 +1,37 @@
+package infoDB
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseMentions(t *testing.T) {
+	tests := []struct {
+		message string
+		want    []string
+	}{
+		{"hi @alice and @bob_99", []string{"alice", "bob_99"}},
+		{"@alice.", []string{"alice"}},
+		{"thanks @mr.whiskers!", []string{"mr.whiskers"}},
+		{"(@alice) @@bob", []string{"alice"}},
+		{"mail vet@example.com or see /@alice", nil},
+		{"@ab is too short", nil},
+		{"ขอบคุณ @แมวส้ม มาก", []string{"แมวส้ม"}},
+		{"run `@alice` not @bob", []string{"bob"}},
+		{"```\n@alice\n```\n@bob", []string{"bob"}},
+		{"```\n@alice", nil},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, c := range parseMentions(tt.message) {
+			got = append(got, c.name)
+			if tt.message[c.start] != '@' || tt.message[c.start+1:c.end] != c.name {
+				t.Errorf("parseMentions(%q): bad range %d-%d for %q", tt.message, c.start, c.end, c.name)
+			}
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("parseMentions(%q) = %q, want %q", tt.message, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,435 @@
+package infoDB
+
+import (
+	"database/sql"
+	"errors"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ===================== Notification Models =====================
+
+// NotificationTypes are the discussion events a user is notified about
+var NotificationTypes = []string{"reply", "mention", "like"}
+
+// แสดงชื่อ actor ล่าสุดไม่เกินเท่านี้ต่อแจ้งเตือน
+const notificationActorLimit = 3
+
+var ErrInvalidNotificationType = errors.New("invalid notification type")
+
+// Notification groups events of one type on one discussion until it is read
+type Notification struct {
+	ID           int    `json:"id"`
+	Type         string `json:"type"`
+	DiscussionID int    `json:"discussion_id"` // reply/like: the user's comment, mention: the mentioning comment
+	BreedID      int    `json:"breed_id"`
+	BreedSlug    string `json:"breed_slug"`
+	Summary      string `json:"summary"` // "5 people liked your comment"
+	ActorCount   int    `json:"actor_count"`
+
+	Actors    []NotificationActor `json:"actors"` // latest first
+	IsRead    bool                `json:"is_read"`
+	ReadAt    *time.Time          `json:"read_at"`
+	CreatedAt time.Time           `json:"created_at"`
+	UpdatedAt time.Time           `json:"updated_at"` // time of the latest event
+}
+
+type NotificationActor struct {
+	UserID   int    `json:"user_id"`
+	Username string `json:"username"`
+	// reply ล่าสุดของ actor (type reply)
+	DiscussionID *int      `json:"discussion_id,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type UnreadNotificationCounts struct {
+	Total  int            `json:"total"`
+	ByType map[string]int `json:"by_type"`
+}
+
+// ===================== Notification Events =====================
+
+// notifyReply notifies the author of the parent discussion about a reply.
+// Returns the parent author's id (0 when the parent was deleted; nobody is notified).
+func notifyReply(tx *sql.Tx, parentID, replyID, actorID int) (int, error) {
+	var authorID int
+	err := tx.QueryRow(`
+		SELECT user_id FROM discussions WHERE id = $1 AND is_deleted = FALSE
+	`, parentID).Scan(&authorID)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	return authorID, notify(tx, authorID, "reply", parentID, actorID, &replyID)
+}
+
+// notifyMentions notifies newly mentioned users, except skipUserID
+// (the parent author already gets a reply notification)
+func notifyMentions(tx *sql.Tx, discussionID, actorID int, mentioned []int, skipUserID int) error {
+	for _, userID := range mentioned {
+		if userID == skipUserID {
+			continue
+		}
+		if err := notify(tx, userID, "mention", discussionID, actorID, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notifyLike notifies the author of a discussion about a like. A user who has
+// already been counted for this discussion (e.g. like, unlike, like again) is not
+// notified again.
+func notifyLike(tx *sql.Tx, discussionID, actorID int) error {
+	var authorID int
+	var notified bool
+	err := tx.QueryRow(`
+		SELECT d.user_id, EXISTS (
+			SELECT 1
+			FROM notifications n
+			JOIN notification_actors na ON na.notification_id = n.id
+			WHERE n.user_id = d.user_id AND n.type = 'like' AND n.discussion_id = d.id
+			  AND na.actor_id = $2
+		)
+		FROM discussions d
+		WHERE d.id = $1 AND d.is_deleted = FALSE
+	`, discussionID, actorID).Scan(&authorID, &notified)
+	if err == sql.ErrNoRows || notified {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	return notify(tx, authorID, "like", discussionID, actorID, nil)
+}
+
+// retractLike removes a withdrawn like from the unread like notification of a
+// discussion. Notifications that were already read stay as they are.
+func retractLike(tx *sql.Tx, discussionID, actorID int) error {
+	var notificationID int
+	err := tx.QueryRow(`
+		DELETE FROM notification_actors na
+		USING notifications n
+		WHERE na.notification_id = n.id
+		  AND n.type = 'like' AND n.discussion_id = $1 AND n.read_at IS NULL
+		  AND na.actor_id = $2
+		RETURNING n.id
+	`, discussionID, actorID).Scan(&notificationID)
+	if err == sql.ErrNoRows {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if err := countNotificationActors(tx, notificationID); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`DELETE FROM notifications WHERE id = $1 AND actor_count = 0`, notificationID)
+	return err
+}
+
+// notify adds an event to the recipient's unread notification of the same type
+// and discussion, creating it if needed. Nothing is recorded for the recipient's
+// own actions or when they turned the type off.
+func notify(tx *sql.Tx, recipientID int, notificationType string, discussionID, actorID int, sourceID *int) error {
+	if recipientID == actorID {
+		return nil
+	}
+
+	var notificationID int
+	err := tx.QueryRow(`
+		INSERT INTO notifications (user_id, type, discussion_id)
+		SELECT $1, $2, $3
+		WHERE NOT EXISTS (
+			SELECT 1 FROM notification_preferences
+			WHERE user_id = $1 AND type = $2 AND enabled = FALSE
+		)
+		ON CONFLICT (user_id, type, discussion_id) WHERE read_at IS NULL
+		DO UPDATE SET updated_at = CURRENT_TIMESTAMP
+		RETURNING id
+	`, recipientID, notificationType, discussionID).Scan(&notificationID)
+	if err == sql.ErrNoRows {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO notification_actors (notification_id, actor_id, source_discussion_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (notification_id, actor_id)
+		DO UPDATE SET source_discussion_id = EXCLUDED.source_discussion_id, created_at = CURRENT_TIMESTAMP
+	`, notificationID, actorID, sourceID)
+	if err != nil {
+		return err
+	}
+
+	return countNotificationActors(tx, notificationID)
+}
+
+func countNotificationActors(tx *sql.Tx, notificationID int) error {
+	_, err := tx.Exec(`
+		UPDATE notifications
+		SET actor_count = (SELECT COUNT(*) FROM notification_actors WHERE notification_id = $1)
+		WHERE id = $1
+	`, notificationID)
+	return err
+}
+
+// ===================== Notification Functions =====================
+
+// GetUserNotifications gets a user's notifications, latest activity first.
+// Notifications on deleted discussions or breeds are left out.
+func GetUserNotifications(userID int, unreadOnly bool, limit, offset int) ([]Notification, error) {
+	rows, err := db.Query(`
+		SELECT n.id, n.type, n.discussion_id, d.breed_id, cb.slug, n.actor_count,
+		       n.read_at, n.created_at, n.updated_at
+		FROM notifications n
+		JOIN discussions d ON d.id = n.discussion_id AND d.is_deleted = FALSE
+		JOIN cat_breeds cb ON cb.id = d.breed_id AND cb.deleted_at IS NULL
+		WHERE n.user_id = $1 AND (NOT $2 OR n.read_at IS NULL)
+		ORDER BY n.updated_at DESC, n.id DESC
+		LIMIT $3 OFFSET $4
+	`, userID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := []Notification{}
+	var ids []int64
+	for rows.Next() {
+		var n Notification
+		err := rows.Scan(
+			&n.ID, &n.Type, &n.DiscussionID, &n.BreedID, &n.BreedSlug, &n.ActorCount,
+			&n.ReadAt, &n.CreatedAt, &n.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		n.IsRead = n.ReadAt != nil
+		n.Actors = []NotificationActor{}
+		notifications = append(notifications, n)
+		ids = append(ids, int64(n.ID))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if len(ids) == 0 {
+		return notifications, nil
+	}
+
+	// actor ล่าสุดของทุกแจ้งเตือนใน query เดียว
+	rows, err = db.Query(`
+		SELECT n.id, a.actor_id, u.username, a.source_discussion_id, a.created_at
+		FROM unnest($1::int[]) AS n(id)
+		CROSS JOIN LATERAL (
+			SELECT actor_id, source_discussion_id, created_at
+			FROM notification_actors
+			WHERE notification_id = n.id
+			ORDER BY created_at DESC, actor_id DESC
+			LIMIT $2
+		) a
+		JOIN users u ON u.id = a.actor_id
+		ORDER BY n.id, a.created_at DESC, a.actor_id DESC
+	`, pq.Array(ids), notificationActorLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	index := make(map[int]int, len(notifications))
+	for i, n := range notifications {
+		index[n.ID] = i
+	}
+	for rows.Next() {
+		var notificationID int
+		var actor NotificationActor
+		var sourceID sql.NullInt64
+		if err := rows.Scan(&notificationID, &actor.UserID, &actor.Username, &sourceID, &actor.CreatedAt); err != nil {
+			return nil, err
+		}
+		if sourceID.Valid {
+			id := int(sourceID.Int64)
+			actor.DiscussionID = &id
+		}
+		n := &notifications[index[notificationID]]
+		n.Actors = append(n.Actors, actor)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range notifications {
+		notifications[i].Summary = notificationSummary(notifications[i])
+	}
+
+	return notifications, nil
+}
+
+// notificationSummary describes a notification, naming the actor when there is only one
+func notificationSummary(n Notification) string {
+	who := strconv.Itoa(n.ActorCount) + " people"
+	if n.ActorCount == 1 && len(n.Actors) > 0 {
+		who = n.Actors[0].Username
+	}
+
+	switch n.Type {
+	case "reply":
+		return who + " replied to your comment"
+	case "mention":
+		return who + " mentioned you in a comment"
+	default:
+		return who + " liked your comment"
+	}
+}
+
+// GetUnreadNotificationCounts counts a user's unread notifications per type
+func GetUnreadNotificationCounts(userID int) (UnreadNotificationCounts, error) {
+	counts := UnreadNotificationCounts{ByType: map[string]int{}}
+	for _, t := range NotificationTypes {
+		counts.ByType[t] = 0
+	}
+
+	rows, err := db.Query(`
+		SELECT n.type, COUNT(*)
+		FROM notifications n
+		JOIN discussions d ON d.id = n.discussion_id AND d.is_deleted = FALSE
+		JOIN cat_breeds cb ON cb.id = d.breed_id AND cb.deleted_at IS NULL
+		WHERE n.user_id = $1 AND n.read_at IS NULL
+		GROUP BY n.type
+	`, userID)
+	if err != nil {
+		return counts, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t string
+		var count int
+		if err := rows.Scan(&t, &count); err != nil {
+			return counts, err
+		}
+		counts.ByType[t] = count
+		counts.Total += count
+	}
+
+	return counts, rows.Err()
+}
+
+// MarkNotificationRead marks one of a user's notifications as read.
+// Returns sql.ErrNoRows when the notification is not the user's.
+func MarkNotificationRead(notificationID, userID int) error {
+	result, err := db.Exec(`
+		UPDATE notifications
+		SET read_at = COALESCE(read_at, CURRENT_TIMESTAMP)
+		WHERE id = $1 AND user_id = $2
+	`, notificationID, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// MarkAllNotificationsRead marks a user's unread notifications as read, only
+// those of notificationType when it is not empty. Like GetUserNotifications it
+// leaves out notifications on deleted discussions or breeds, so the returned
+// count matches what the user could see.
+func MarkAllNotificationsRead(userID int, notificationType string) (int64, error) {
+	if notificationType != "" && !slices.Contains(NotificationTypes, notificationType) {
+		return 0, ErrInvalidNotificationType
+	}
+
+	result, err := db.Exec(`
+		UPDATE notifications n
+		SET read_at = CURRENT_TIMESTAMP
+		FROM discussions d
+		JOIN cat_breeds cb ON cb.id = d.breed_id AND cb.deleted_at IS NULL
+		WHERE d.id = n.discussion_id AND d.is_deleted = FALSE
+		  AND n.user_id = $1 AND n.read_at IS NULL AND ($2 = '' OR n.type::text = $2)
+	`, userID, notificationType)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// ===================== Notification Preferences =====================
+
+// GetNotificationPreferences gets whether each notification type is enabled for a user
+func GetNotificationPreferences(userID int) (map[string]bool, error) {
+	preferences := map[string]bool{}
+	for _, t := range NotificationTypes {
+		preferences[t] = true
+	}
+
+	rows, err := db.Query(`
+		SELECT type, enabled FROM notification_preferences WHERE user_id = $1
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t string
+		var enabled bool
+		if err := rows.Scan(&t, &enabled); err != nil {
+			return nil, err
+		}
+		preferences[t] = enabled
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return preferences, nil
+}
+
+// UpdateNotificationPreferences turns notification types on or off; types not in
+// changes keep their setting. Returns the resulting preferences.
+func UpdateNotificationPreferences(userID int, changes map[string]bool) (map[string]bool, error) {
+	for t := range changes {
+		if !slices.Contains(NotificationTypes, t) {
+			return nil, ErrInvalidNotificationType
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for t, enabled := range changes {
+		_, err := tx.Exec(`
+			INSERT INTO notification_preferences (user_id, type, enabled)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (user_id, type)
+			DO UPDATE SET enabled = EXCLUDED.enabled, updated_at = CURRENT_TIMESTAMP
+		`, userID, t, enabled)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return GetNotificationPreferences(userID)
+}
@@ -0,0 +1,24 @@
+package infoDB
+
+import "testing"
+
+func TestNotificationSummary(t *testing.T) {
+	alice := []NotificationActor{{UserID: 1, Username: "alice"}}
+
+	tests := []struct {
+		n    Notification
+		want string
+	}{
+		{Notification{Type: "like", ActorCount: 1, Actors: alice}, "alice liked your comment"},
+		{Notification{Type: "like", ActorCount: 5, Actors: alice}, "5 people liked your comment"},
+		{Notification{Type: "reply", ActorCount: 1, Actors: alice}, "alice replied to your comment"},
+		{Notification{Type: "reply", ActorCount: 2, Actors: alice}, "2 people replied to your comment"},
+		{Notification{Type: "mention", ActorCount: 1, Actors: alice}, "alice mentioned you in a comment"},
+	}
+
+	for _, tt := range tests {
+		if got := notificationSummary(tt.n); got != tt.want {
+			t.Errorf("notificationSummary(%s, %d) = %q, want %q", tt.n.Type, tt.n.ActorCount, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package infoDB
+
+import (
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ===================== Breed Ranking Models =====================
+
+type RankedCat struct {
+	Rank  int     `json:"rank"`
+	Score float64 `json:"score"`
+	Cat
+}
+
+type rankingEntry struct {
+	catID int
+	score float64
+}
+
+// rankings are recomputed in the background; requests only read the cached result
+type rankingCache struct {
+	mu         sync.RWMutex
+	trending   []rankingEntry
+	top        []rankingEntry
+	computedAt time.Time
+}
+
+var rankings rankingCache
+
+// TrendingConfig tunes the time-decayed trending score
+type TrendingConfig struct {
+	WindowDays int           // only the last N days of activity count
+	HalfLife   time.Duration // activity loses half its weight every HalfLife
+
+	ViewWeight       float64
+	LikeWeight       float64
+	DislikeWeight    float64 // ติดลบ: dislike ดัน breed ลง ไม่ใช่ขึ้น
+	DiscussionWeight float64
+}
+
+var trendingConfig = TrendingConfig{
+	WindowDays:       14,
+	HalfLife:         48 * time.Hour,
+	ViewWeight:       1,
+	LikeWeight:       3,
+	DislikeWeight:    -1,
+	DiscussionWeight: 5,
+}
+
+// จำนวนอันดับสูงสุดที่เก็บใน cache
+const maxRankedCats = 500
+
+// ===================== Breed Ranking Functions =====================
+
+// StartRankingRefresher computes the rankings now and then every interval
+func StartRankingRefresher(cfg TrendingConfig, interval time.Duration) {
+	trendingConfig = cfg
+
+	refresh := func() {
+		if err := RefreshRankings(); err != nil {
+			log.Println("Failed to refresh breed rankings:", err)
+		}
+	}
+	refresh()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			refresh()
+		}
+	}()
+}
+
+// RefreshRankings recomputes the trending and top rankings and replaces the cache
+func RefreshRankings() error {
+	trending, err := computeTrending()
+	if err != nil {
+		return err
+	}
+
+	top, err := computeTop()
+	if err != nil {
+		return err
+	}
+
+	rankings.mu.Lock()
+	rankings.trending = trending
+	rankings.top = top
+	rankings.computedAt = time.Now()
+	rankings.mu.Unlock()
+
+	return nil
+}
+
+// computeTrending scores each breed by its recent daily activity, each day weighted
+// by 0.5^(age / half-life) so that today's activity counts most
+func computeTrending() ([]rankingEntry, error) {
+	cfg := trendingConfig
+	halfLifeDays := cfg.HalfLife.Hours() / 24
+
+	rows, err := db.Query(`
+		SELECT breed_id, score
+		FROM (
+			SELECT d.breed_id,
+			       SUM((d.views * $3::float8 + d.likes * $4::float8
+			            + d.dislikes * $5::float8 + d.discussions * $6::float8)
+			           * power(0.5, (breed_stats_day(CURRENT_TIMESTAMP) - d.day) / $2::float8)) AS score
+			FROM breed_daily_stats d
+			JOIN cat_breeds cb ON d.breed_id = cb.id AND cb.deleted_at IS NULL
+			WHERE d.day > breed_stats_day(CURRENT_TIMESTAMP) - $1::int
+			GROUP BY d.breed_id
+		) scored
+		WHERE score > 0
+		ORDER BY score DESC, breed_id
+		LIMIT $7
+	`, cfg.WindowDays, halfLifeDays,
+		cfg.ViewWeight, cfg.LikeWeight, cfg.DislikeWeight, cfg.DiscussionWeight, maxRankedCats)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []rankingEntry{}
+	for rows.Next() {
+		var e rankingEntry
+		if err := rows.Scan(&e.catID, &e.score); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+// computeTop ranks breeds by the Wilson lower bound of their like ratio
+func computeTop() ([]rankingEntry, error) {
+	rows, err := db.Query(`
+		SELECT id, like_count, dislike_count
+		FROM cat_breeds
+		WHERE deleted_at IS NULL AND like_count + dislike_count > 0
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []rankingEntry{}
+	likes := map[int]int{}
+	for rows.Next() {
+		var catID, up, down int
+		if err := rows.Scan(&catID, &up, &down); err != nil {
+			return nil, err
+		}
+		entries = append(entries, rankingEntry{catID: catID, score: WilsonLowerBound(up, up+down)})
+		likes[catID] = up
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.score != b.score {
+			return a.score > b.score
+		}
+		if likes[a.catID] != likes[b.catID] {
+			return likes[a.catID] > likes[b.catID]
+		}
+		return a.catID < b.catID
+	})
+
+	if len(entries) > maxRankedCats {
+		entries = entries[:maxRankedCats]
+	}
+	return entries, nil
+}
+
+// WilsonLowerBound is the lower bound of the 95% Wilson score interval for
+// positive out of total votes. A few votes give a low bound, many votes a bound
+// close to the observed ratio.
+func WilsonLowerBound(positive, total int) float64 {
+	if total <= 0 {
+		return 0
+	}
+
+	const z = 1.96
+	n := float64(total)
+	p := float64(positive) / n
+
+	return (p + z*z/(2*n) - z*math.Sqrt((p*(1-p)+z*z/(4*n))/n)) / (1 + z*z/n)
+}
+
+// GetTrendingCats returns a page of the cached trending ranking
+func GetTrendingCats(currentUserID *int, limit, offset int) ([]RankedCat, time.Time, error) {
+	rankings.mu.RLock()
+	entries, computedAt := rankings.trending, rankings.computedAt
+	rankings.mu.RUnlock()
+
+	cats, err := rankedCats(entries, currentUserID, limit, offset)
+	return cats, computedAt, err
+}
+
+// GetTopCats returns a page of the cached top-rated ranking
+func GetTopCats(currentUserID *int, limit, offset int) ([]RankedCat, time.Time, error) {
+	rankings.mu.RLock()
+	entries, computedAt := rankings.top, rankings.computedAt
+	rankings.mu.RUnlock()
+
+	cats, err := rankedCats(entries, currentUserID, limit, offset)
+	return cats, computedAt, err
+}
+
+// rankedCats loads the breeds of one page of a ranking. Breeds deleted since the
+// last refresh are skipped, so a page may be shorter than limit.
+func rankedCats(entries []rankingEntry, currentUserID *int, limit, offset int) ([]RankedCat, error) {
+	if offset >= len(entries) {
+		return []RankedCat{}, nil
+	}
+	page := entries[offset:min(offset+limit, len(entries))]
+
+	ids := make([]int, len(page))
+	for i, e := range page {
+		ids[i] = e.catID
+	}
+
+	cats, err := GetCatsByIDs(ids, currentUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := map[int]Cat{}
+	for _, cat := range cats {
+		byID[cat.ID] = cat
+	}
+
+	ranked := []RankedCat{}
+	for i, e := range page {
+		cat, ok := byID[e.catID]
+		if !ok {
+			continue
+		}
+		ranked = append(ranked, RankedCat{Rank: offset + i + 1, Score: e.score, Cat: cat})
+	}
+
+	return ranked, nil
+}
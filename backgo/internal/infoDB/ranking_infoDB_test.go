@@ -0,0 +1,42 @@
+package infoDB
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWilsonLowerBound(t *testing.T) {
+	tests := []struct {
+		name            string
+		positive, total int
+		want            float64
+	}{
+		{"no votes", 0, 0, 0},
+		{"negative total", 3, -1, 0},
+		{"0 of 10", 0, 10, 0},
+		{"1 of 1", 1, 1, 0.2065},
+		{"10 of 10", 10, 10, 0.7225},
+		{"900 of 1000", 900, 1000, 0.8798},
+	}
+
+	for _, tt := range tests {
+		if got := WilsonLowerBound(tt.positive, tt.total); math.Abs(got-tt.want) > 1e-4 {
+			t.Errorf("%s: WilsonLowerBound(%d, %d) = %.4f, want %.4f", tt.name, tt.positive, tt.total, got, tt.want)
+		}
+	}
+
+	// เกณฑ์ของ request: 1 like จาก 1 ต้องอยู่ต่ำกว่า 900 like จาก 1000
+	if WilsonLowerBound(1, 1) >= WilsonLowerBound(900, 1000) {
+		t.Error("1 of 1 should rank below 900 of 1000")
+	}
+
+	// n of n เข้าใกล้ 1 เมื่อ n มากขึ้น แต่ไม่เกิน 1
+	previous := 0.0
+	for _, n := range []int{1, 10, 100, 10000} {
+		got := WilsonLowerBound(n, n)
+		if got <= previous || got >= 1 {
+			t.Errorf("%d of %d = %.4f, want between %.4f and 1", n, n, got, previous)
+		}
+		previous = got
+	}
+}
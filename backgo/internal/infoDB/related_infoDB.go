@@ -0,0 +1,445 @@
+package infoDB
+
+import (
+	"database/sql"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ===================== Related Breed Models =====================
+
+type RelatedCat struct {
+	Score          float64 `json:"score"`
+	CoLikeScore    float64 `json:"co_like_score"`
+	AttributeScore float64 `json:"attribute_score"`
+	Cat
+}
+
+type RecommendedCat struct {
+	Score float64 `json:"score"`
+	// สายพันธุ์ที่ผู้ใช้ชอบ/บันทึกไว้ซึ่งทำให้ได้คำแนะนำนี้
+	BecauseOf []int `json:"because_of"`
+	Cat
+}
+
+// RelatedConfig tunes how breed neighbors are computed
+type RelatedConfig struct {
+	Neighbors    int     // neighbors stored per breed
+	CoLikeWeight float64 // share (0-1) of co-like similarity in the blend; the rest is attribute similarity
+}
+
+var relatedConfig = RelatedConfig{Neighbors: 20, CoLikeWeight: 0.6}
+
+// co-like similarity of a pair with few common users is shrunk towards zero
+const coLikeShrinkage = 5
+
+// จำนวนคุณลักษณะที่ใช้เทียบ ถ้าข้อมูลขาดจะนับเป็นไม่เหมือน
+const attributeFeatures = 9
+
+var coatLengthOrder = map[string]int{"hairless": 0, "short": 1, "medium": 2, "long": 3}
+
+const RecommendationsPersonalized = "personalized"
+const RecommendationsPopular = "popular"
+
+// ===================== Related Breed Functions =====================
+
+// StartRelatedRefresher recomputes the breed neighbors now and then every interval
+func StartRelatedRefresher(cfg RelatedConfig, interval time.Duration) {
+	relatedConfig = cfg
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if err := RefreshBreedNeighbors(); err != nil {
+				log.Println("Failed to refresh related breeds:", err)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// RefreshBreedNeighbors replaces breed_neighbors with the top neighbors of every active breed
+func RefreshBreedNeighbors() error {
+	cfg := relatedConfig
+
+	rows, err := db.Query(`SELECT ` + catColumns + ` FROM cat_breeds cb WHERE cb.deleted_at IS NULL ORDER BY cb.id`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var cats []Cat
+	for rows.Next() {
+		var cat Cat
+		if err := scanCat(rows, &cat); err != nil {
+			return err
+		}
+		cats = append(cats, cat)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	coLikes, err := coLikeCounts()
+	if err != nil {
+		return err
+	}
+
+	type neighbor struct {
+		id                  int
+		score, coLike, attr float64
+	}
+	neighbors := make([][]neighbor, len(cats))
+
+	for i := range cats {
+		for j := i + 1; j < len(cats); j++ {
+			a, b := cats[i], cats[j]
+
+			attr := attributeSimilarity(a.Attributes, b.Attributes)
+			coLike := coLikeSimilarity(coLikes[[2]int{a.ID, b.ID}], a.LikeCount, b.LikeCount)
+
+			// สายพันธุ์ที่ยังไม่มีใครกดชอบใช้คุณลักษณะอย่างเดียว
+			score := attr
+			if a.LikeCount > 0 && b.LikeCount > 0 {
+				score = cfg.CoLikeWeight*coLike + (1-cfg.CoLikeWeight)*attr
+			}
+			if score <= 0 {
+				continue
+			}
+
+			neighbors[i] = append(neighbors[i], neighbor{b.ID, score, coLike, attr})
+			neighbors[j] = append(neighbors[j], neighbor{a.ID, score, coLike, attr})
+		}
+	}
+
+	var breedIDs, neighborIDs []int64
+	var scores, coLikeScores, attributeScores []float64
+	for i, list := range neighbors {
+		sort.Slice(list, func(x, y int) bool {
+			if list[x].score != list[y].score {
+				return list[x].score > list[y].score
+			}
+			return list[x].id < list[y].id
+		})
+		if len(list) > cfg.Neighbors {
+			list = list[:cfg.Neighbors]
+		}
+
+		for _, n := range list {
+			breedIDs = append(breedIDs, int64(cats[i].ID))
+			neighborIDs = append(neighborIDs, int64(n.id))
+			scores = append(scores, roundScore(n.score))
+			coLikeScores = append(coLikeScores, roundScore(n.coLike))
+			attributeScores = append(attributeScores, roundScore(n.attr))
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM breed_neighbors`); err != nil {
+		return err
+	}
+
+	// สายพันธุ์อาจถูกลบถาวรระหว่างคำนวณ จึงกรองด้วย JOIN
+	_, err = tx.Exec(`
+		INSERT INTO breed_neighbors (breed_id, neighbor_id, score, co_like_score, attribute_score)
+		SELECT n.breed_id, n.neighbor_id, n.score, n.co_like_score, n.attribute_score
+		FROM unnest($1::int[], $2::int[], $3::float8[], $4::float8[], $5::float8[])
+			AS n(breed_id, neighbor_id, score, co_like_score, attribute_score)
+		JOIN cat_breeds a ON a.id = n.breed_id
+		JOIN cat_breeds b ON b.id = n.neighbor_id
+	`, pq.Array(breedIDs), pq.Array(neighborIDs), pq.Array(scores), pq.Array(coLikeScores), pq.Array(attributeScores))
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// coLikeCounts counts the users who liked both breeds of each pair, keyed by (lower id, higher id)
+func coLikeCounts() (map[[2]int]int, error) {
+	rows, err := db.Query(`
+		SELECT a.breed_id, b.breed_id, COUNT(*)
+		FROM breed_reactions a
+		JOIN breed_reactions b ON a.user_id = b.user_id AND a.breed_id < b.breed_id
+		WHERE a.reaction_type = 'like' AND b.reaction_type = 'like'
+		GROUP BY a.breed_id, b.breed_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[[2]int]int{}
+	for rows.Next() {
+		var a, b, n int
+		if err := rows.Scan(&a, &b, &n); err != nil {
+			return nil, err
+		}
+		counts[[2]int{a, b}] = n
+	}
+
+	return counts, rows.Err()
+}
+
+// coLikeSimilarity is the cosine similarity of the breeds' like sets, shrunk when few users overlap
+func coLikeSimilarity(common, likesA, likesB int) float64 {
+	if common == 0 || likesA == 0 || likesB == 0 {
+		return 0
+	}
+	cosine := float64(common) / math.Sqrt(float64(likesA)*float64(likesB))
+	return math.Min(cosine, 1) * float64(common) / float64(common+coLikeShrinkage)
+}
+
+// attributeSimilarity compares the attributes two breeds both have (0-1)
+func attributeSimilarity(a, b BreedAttributes) float64 {
+	var sum float64
+
+	levels := [][2]*int{
+		{a.SheddingLevel, b.SheddingLevel},
+		{a.ActivityLevel, b.ActivityLevel},
+		{a.GroomingLevel, b.GroomingLevel},
+		{a.ChildFriendly, b.ChildFriendly},
+		{a.PetFriendly, b.PetFriendly},
+	}
+	for _, l := range levels {
+		if l[0] != nil && l[1] != nil {
+			sum += 1 - math.Abs(float64(*l[0]-*l[1]))/4
+		}
+	}
+
+	if a.CoatLength != nil && b.CoatLength != nil {
+		diff := coatLengthOrder[*a.CoatLength] - coatLengthOrder[*b.CoatLength]
+		sum += 1 - math.Abs(float64(diff))/3
+	}
+
+	if a.Hypoallergenic != nil && b.Hypoallergenic != nil && *a.Hypoallergenic == *b.Hypoallergenic {
+		sum++
+	}
+
+	if midA, ok := weightMidpoint(a); ok {
+		if midB, ok := weightMidpoint(b); ok {
+			// ต่างกันตั้งแต่ 5 กก. ขึ้นไปถือว่าไม่เหมือน
+			sum += math.Max(0, 1-math.Abs(midA-midB)/5)
+		}
+	}
+
+	sum += temperamentOverlap(a.Temperament, b.Temperament)
+
+	return sum / attributeFeatures
+}
+
+func weightMidpoint(attrs BreedAttributes) (float64, bool) {
+	switch {
+	case attrs.WeightMinKg != nil && attrs.WeightMaxKg != nil:
+		return (*attrs.WeightMinKg + *attrs.WeightMaxKg) / 2, true
+	case attrs.WeightMinKg != nil:
+		return *attrs.WeightMinKg, true
+	case attrs.WeightMaxKg != nil:
+		return *attrs.WeightMaxKg, true
+	}
+	return 0, false
+}
+
+// temperamentOverlap is the Jaccard index of two temperament lists, ignoring case
+func temperamentOverlap(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	set := map[string]bool{}
+	for _, t := range a {
+		set[strings.ToLower(t)] = true
+	}
+
+	union := len(set)
+	common := 0
+	seen := map[string]bool{}
+	for _, t := range b {
+		t = strings.ToLower(t)
+		if seen[t] {
+			continue
+		}
+		seen[t] = true
+		if set[t] {
+			common++
+		} else {
+			union++
+		}
+	}
+
+	return float64(common) / float64(union)
+}
+
+func roundScore(score float64) float64 {
+	return math.Round(score*10000) / 10000
+}
+
+// GetRelatedCats returns the precomputed neighbors of an active breed, most similar first
+func GetRelatedCats(catID int, currentUserID *int, limit int) ([]RelatedCat, error) {
+	active, err := isCatActive(catID)
+	if err != nil {
+		return nil, err
+	}
+	if !active {
+		return nil, sql.ErrNoRows
+	}
+
+	rows, err := db.Query(`
+		SELECT neighbor_id, score, co_like_score, attribute_score
+		FROM breed_neighbors
+		WHERE breed_id = $1
+		ORDER BY score DESC, neighbor_id
+		LIMIT $2
+	`, catID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	scores := map[int]RelatedCat{}
+	for rows.Next() {
+		var id int
+		var r RelatedCat
+		if err := rows.Scan(&id, &r.Score, &r.CoLikeScore, &r.AttributeScore); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+		scores[id] = r
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	cats, err := GetCatsByIDs(ids, currentUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	related := make([]RelatedCat, len(cats))
+	for i, cat := range cats {
+		r := scores[cat.ID]
+		r.Cat = cat
+		related[i] = r
+	}
+
+	return related, nil
+}
+
+// GetRecommendations suggests breeds related to the ones a user liked or favorited,
+// leaving out breeds the user already reacted to or saved. Users without any likes
+// get the top-rated breeds instead; the second value tells which source was used.
+func GetRecommendations(userID, limit int) ([]RecommendedCat, string, error) {
+	rows, err := db.Query(`
+		WITH seeds AS (
+			SELECT breed_id FROM breed_reactions WHERE user_id = $1 AND reaction_type = 'like'
+			UNION ALL
+			SELECT breed_id FROM user_favorites WHERE user_id = $1
+		),
+		seen AS (
+			SELECT breed_id FROM breed_reactions WHERE user_id = $1
+			UNION
+			SELECT breed_id FROM user_favorites WHERE user_id = $1
+		)
+		SELECT n.neighbor_id, SUM(n.score) AS score,
+		       array_agg(n.breed_id ORDER BY n.score DESC) AS because_of
+		FROM seeds s
+		JOIN breed_neighbors n ON n.breed_id = s.breed_id
+		WHERE n.neighbor_id NOT IN (SELECT breed_id FROM seen)
+		GROUP BY n.neighbor_id
+		ORDER BY score DESC, n.neighbor_id
+		LIMIT $2
+	`, userID, limit)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	var ids []int
+	scores := map[int]RecommendedCat{}
+	for rows.Next() {
+		var id int
+		var because []int64
+		var r RecommendedCat
+		if err := rows.Scan(&id, &r.Score, pq.Array(&because)); err != nil {
+			return nil, "", err
+		}
+		r.Score = roundScore(r.Score)
+		r.BecauseOf = topDistinct(because, 3)
+		ids = append(ids, id)
+		scores[id] = r
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	if len(ids) == 0 {
+		return popularRecommendations(userID, limit)
+	}
+
+	cats, err := GetCatsByIDs(ids, &userID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	recommended := make([]RecommendedCat, len(cats))
+	for i, cat := range cats {
+		r := scores[cat.ID]
+		r.Cat = cat
+		recommended[i] = r
+	}
+
+	return recommended, RecommendationsPersonalized, nil
+}
+
+// popularRecommendations falls back to the top-rated breeds the user has not reacted to yet
+func popularRecommendations(userID, limit int) ([]RecommendedCat, string, error) {
+	recommended := []RecommendedCat{}
+
+	for offset := 0; len(recommended) < limit; offset += limit {
+		ranked, _, err := GetTopCats(&userID, limit, offset)
+		if err != nil {
+			return nil, "", err
+		}
+		if len(ranked) == 0 {
+			break
+		}
+
+		for _, r := range ranked {
+			if r.UserReaction != nil || len(recommended) == limit {
+				continue
+			}
+			recommended = append(recommended, RecommendedCat{Score: r.Score, BecauseOf: []int{}, Cat: r.Cat})
+		}
+	}
+
+	return recommended, RecommendationsPopular, nil
+}
+
+// topDistinct returns the first n distinct ids
+func topDistinct(ids []int64, n int) []int {
+	seen := map[int64]bool{}
+	out := []int{}
+	for _, id := range ids {
+		if len(out) == n {
+			break
+		}
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, int(id))
+		}
+	}
+	return out
+}
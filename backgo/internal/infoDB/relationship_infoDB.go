@@ -0,0 +1,268 @@
+package infoDB
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// ===================== Breed Relationship Models =====================
+
+// BreedRelationship is an edge of the lineage graph. For derived_from and
+// variant_of, Source is the newer breed and Target the breed it comes from.
+type BreedRelationship struct {
+	ID        int       `json:"id"`
+	Source    int       `json:"source"`
+	Target    int       `json:"target"`
+	Type      string    `json:"type"` // "derived_from", "variant_of" or "confused_with"
+	Note      string    `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type LineageNode struct {
+	ID       int         `json:"id"`
+	Slug     string      `json:"slug"`
+	Name     string      `json:"name"`
+	Origin   string      `json:"origin"`
+	ImageURL string      `json:"image_url"`
+	Image    *BreedImage `json:"image,omitempty"`
+	// รุ่นเทียบกับสายพันธุ์หลัก: ติดลบ = บรรพบุรุษ, บวก = สายพันธุ์ที่สืบมา, null = confused_with
+	Generation *int `json:"generation"`
+}
+
+type BreedLineage struct {
+	Root   int                 `json:"root"`
+	Depth  int                 `json:"depth"`
+	Locale string              `json:"locale"`
+	Nodes  []LineageNode       `json:"nodes"`
+	Edges  []BreedRelationship `json:"edges"`
+}
+
+type CreateRelationshipRequest struct {
+	RelatedID int    `json:"related_id" binding:"required"`
+	Type      string `json:"type" binding:"required,oneof=derived_from variant_of confused_with"`
+	Note      string `json:"note" binding:"max=500"`
+}
+
+const DefaultLineageDepth = 3
+const MaxLineageDepth = 10
+
+var (
+	ErrRelationshipSelf   = errors.New("a breed cannot be related to itself")
+	ErrRelationshipExists = errors.New("relationship already exists")
+	ErrRelationshipCycle  = errors.New("relationship would make a breed its own ancestor")
+)
+
+// ===================== Breed Relationship Functions =====================
+
+// CreateBreedRelationship links two active breeds. derived_from and variant_of
+// edges may not form a cycle.
+func CreateBreedRelationship(catID, userID int, req CreateRelationshipRequest) (BreedRelationship, error) {
+	if catID == req.RelatedID {
+		return BreedRelationship{}, ErrRelationshipSelf
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return BreedRelationship{}, err
+	}
+	defer tx.Rollback()
+
+	for _, id := range []int{catID, req.RelatedID} {
+		if err := requireActiveBreed(tx, id); err != nil {
+			return BreedRelationship{}, err
+		}
+	}
+
+	source, target := catID, req.RelatedID
+	if req.Type == "confused_with" {
+		source, target = min(catID, req.RelatedID), max(catID, req.RelatedID)
+	} else {
+		// กันการเพิ่ม edge พร้อมกันที่รวมกันแล้วเป็นวง
+		if _, err := tx.Exec(`LOCK TABLE breed_relationships IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+			return BreedRelationship{}, err
+		}
+
+		var cycle bool
+		err := tx.QueryRow(`
+			WITH RECURSIVE ancestors AS (
+				SELECT $1::int AS id
+				UNION
+				SELECT r.related_id
+				FROM ancestors a
+				JOIN breed_relationships r ON r.breed_id = a.id
+				WHERE r.type IN ('derived_from', 'variant_of')
+			)
+			SELECT EXISTS (SELECT 1 FROM ancestors WHERE id = $2)
+		`, target, source).Scan(&cycle)
+		if err != nil {
+			return BreedRelationship{}, err
+		}
+		if cycle {
+			return BreedRelationship{}, ErrRelationshipCycle
+		}
+	}
+
+	rel := BreedRelationship{Source: source, Target: target, Type: req.Type, Note: req.Note}
+	err = tx.QueryRow(`
+		INSERT INTO breed_relationships (breed_id, related_id, type, note, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (breed_id, related_id, type) DO NOTHING
+		RETURNING id, created_at
+	`, source, target, req.Type, req.Note, userID).Scan(&rel.ID, &rel.CreatedAt)
+	if err == sql.ErrNoRows {
+		return BreedRelationship{}, ErrRelationshipExists
+	} else if err != nil {
+		return BreedRelationship{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return BreedRelationship{}, err
+	}
+
+	return rel, nil
+}
+
+// DeleteBreedRelationship removes a relationship the breed takes part in
+func DeleteBreedRelationship(catID, relationshipID int) error {
+	result, err := db.Exec(`
+		DELETE FROM breed_relationships
+		WHERE id = $1 AND (breed_id = $2 OR related_id = $2)
+	`, relationshipID, catID)
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// GetCatLineage walks the ancestors and descendants of an active breed up to
+// depth generations, plus the breeds it is often confused with. Deleted breeds
+// are not part of the graph.
+func GetCatLineage(catID, depth int, locale string) (BreedLineage, error) {
+	active, err := isCatActive(catID)
+	if err != nil {
+		return BreedLineage{}, err
+	}
+	if !active {
+		return BreedLineage{}, sql.ErrNoRows
+	}
+
+	// depth ติดลบ = ขึ้นไปหาบรรพบุรุษ, บวก = ลงไปหาสายพันธุ์ที่สืบมา
+	rows, err := db.Query(`
+		WITH RECURSIVE up AS (
+			SELECT r.id, r.breed_id, r.related_id, r.type, r.note, r.created_at, 1 AS depth
+			FROM breed_relationships r
+			JOIN cat_breeds cb ON cb.id = r.related_id AND cb.deleted_at IS NULL
+			WHERE r.breed_id = $1 AND r.type IN ('derived_from', 'variant_of')
+			UNION
+			SELECT r.id, r.breed_id, r.related_id, r.type, r.note, r.created_at, up.depth + 1
+			FROM up
+			JOIN breed_relationships r ON r.breed_id = up.related_id
+			JOIN cat_breeds cb ON cb.id = r.related_id AND cb.deleted_at IS NULL
+			WHERE r.type IN ('derived_from', 'variant_of') AND up.depth < $2
+		),
+		down AS (
+			SELECT r.id, r.breed_id, r.related_id, r.type, r.note, r.created_at, 1 AS depth
+			FROM breed_relationships r
+			JOIN cat_breeds cb ON cb.id = r.breed_id AND cb.deleted_at IS NULL
+			WHERE r.related_id = $1 AND r.type IN ('derived_from', 'variant_of')
+			UNION
+			SELECT r.id, r.breed_id, r.related_id, r.type, r.note, r.created_at, down.depth + 1
+			FROM down
+			JOIN breed_relationships r ON r.related_id = down.breed_id
+			JOIN cat_breeds cb ON cb.id = r.breed_id AND cb.deleted_at IS NULL
+			WHERE r.type IN ('derived_from', 'variant_of') AND down.depth < $2
+		),
+		confused AS (
+			SELECT r.id, r.breed_id, r.related_id, r.type, r.note, r.created_at, 0 AS depth
+			FROM breed_relationships r
+			JOIN cat_breeds cb ON cb.id = CASE WHEN r.breed_id = $1 THEN r.related_id ELSE r.breed_id END
+				AND cb.deleted_at IS NULL
+			WHERE (r.breed_id = $1 OR r.related_id = $1) AND r.type = 'confused_with'
+		)
+		SELECT id, breed_id, related_id, type, note, created_at, MIN(depth) * -1 FROM up
+		GROUP BY id, breed_id, related_id, type, note, created_at
+		UNION ALL
+		SELECT id, breed_id, related_id, type, note, created_at, MIN(depth) FROM down
+		GROUP BY id, breed_id, related_id, type, note, created_at
+		UNION ALL
+		SELECT id, breed_id, related_id, type, note, created_at, depth FROM confused
+	`, catID, depth)
+	if err != nil {
+		return BreedLineage{}, err
+	}
+	defer rows.Close()
+
+	root := 0
+	generations := map[int]*int{catID: &root}
+	ids := []int{catID}
+
+	lineage := BreedLineage{Root: catID, Depth: depth, Edges: []BreedRelationship{}}
+	for rows.Next() {
+		var e BreedRelationship
+		var d int
+		if err := rows.Scan(&e.ID, &e.Source, &e.Target, &e.Type, &e.Note, &e.CreatedAt, &d); err != nil {
+			return BreedLineage{}, err
+		}
+		lineage.Edges = append(lineage.Edges, e)
+
+		// node ใหม่ของ edge: บรรพบุรุษคือ target, สายพันธุ์ที่สืบมาคือ source
+		node, generation := e.Target, &d
+		if d > 0 || (d == 0 && e.Target == catID) {
+			node = e.Source
+		}
+		if d == 0 {
+			generation = nil
+		}
+
+		if _, seen := generations[node]; !seen {
+			generations[node] = generation
+			ids = append(ids, node)
+		} else if g := generations[node]; g != nil && generation != nil && abs(*generation) < abs(*g) {
+			generations[node] = generation
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return BreedLineage{}, err
+	}
+
+	cats, err := GetCatsByIDs(ids, nil)
+	if err != nil {
+		return BreedLineage{}, err
+	}
+	if err := TranslateCats(cats, locale); err != nil {
+		return BreedLineage{}, err
+	}
+
+	lineage.Locale = locale
+	lineage.Nodes = make([]LineageNode, len(cats))
+	for i, cat := range cats {
+		lineage.Nodes[i] = LineageNode{
+			ID:         cat.ID,
+			Slug:       cat.Slug,
+			Name:       cat.Name,
+			Origin:     cat.Origin,
+			ImageURL:   cat.ImageURL,
+			Image:      cat.Image,
+			Generation: generations[cat.ID],
+		}
+	}
+
+	return lineage, nil
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
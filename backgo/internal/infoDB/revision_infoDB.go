@@ -0,0 +1,304 @@
+package infoDB
+
+import (
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ===================== Breed Revision Models =====================
+
+type BreedRevision struct {
+	ID             int    `json:"id"`
+	BreedID        int    `json:"breed_id"`
+	RevisionNumber int    `json:"revision_number"`
+	Action         string `json:"action"` // "create", "update" or "rollback"
+	RolledBackFrom *int   `json:"rolled_back_from,omitempty"`
+
+	// Snapshot after the change
+	Name        string `json:"name"`
+	Origin      string `json:"origin"`
+	Description string `json:"description"`
+	Care        string `json:"care"`
+	ImageURL    string `json:"image_url"`
+
+	Attributes BreedAttributes `json:"attributes"`
+
+	ChangedBy         *int      `json:"changed_by,omitempty"`
+	ChangedByUsername *string   `json:"changed_by_username,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+type RevisionFieldDiff struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+type RevisionDiff struct {
+	BreedID      int                 `json:"breed_id"`
+	FromRevision int                 `json:"from_revision"`
+	ToRevision   int                 `json:"to_revision"`
+	Changes      []RevisionFieldDiff `json:"changes"`
+}
+
+// ===================== Breed Revision Functions =====================
+
+// recordBreedRevision stores a full snapshot of cat as the next revision of the breed
+func recordBreedRevision(tx *sql.Tx, cat Cat, action string, rolledBackFrom *int, userID int) error {
+	attributesJSON, err := json.Marshal(cat.Attributes)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO breed_revisions
+			(breed_id, revision_number, action, rolled_back_from,
+			 name, origin, description, care_instructions, image_url, attributes, changed_by)
+		SELECT $1, COALESCE(MAX(revision_number), 0) + 1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+		FROM breed_revisions
+		WHERE breed_id = $1
+	`, cat.ID, action, rolledBackFrom,
+		cat.Name, cat.Origin, cat.Description, cat.Care, cat.ImageURL, attributesJSON, userID)
+
+	return err
+}
+
+// GetBreedRevisions lists all revisions of a breed, newest first
+func GetBreedRevisions(catID int) ([]BreedRevision, error) {
+	rows, err := db.Query(`
+		SELECT
+			r.id, r.breed_id, r.revision_number, r.action, r.rolled_back_from,
+			r.name, r.origin, r.description, r.care_instructions, r.image_url, r.attributes,
+			r.changed_by, u.username, r.created_at
+		FROM breed_revisions r
+		LEFT JOIN users u ON r.changed_by = u.id
+		WHERE r.breed_id = $1
+		ORDER BY r.revision_number DESC
+	`, catID)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []BreedRevision{}
+	for rows.Next() {
+		revision, err := scanBreedRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+
+	return revisions, rows.Err()
+}
+
+// GetBreedRevision gets a single revision of a breed by its revision number
+func GetBreedRevision(catID, revisionNumber int) (BreedRevision, error) {
+	row := db.QueryRow(`
+		SELECT
+			r.id, r.breed_id, r.revision_number, r.action, r.rolled_back_from,
+			r.name, r.origin, r.description, r.care_instructions, r.image_url, r.attributes,
+			r.changed_by, u.username, r.created_at
+		FROM breed_revisions r
+		LEFT JOIN users u ON r.changed_by = u.id
+		WHERE r.breed_id = $1 AND r.revision_number = $2
+	`, catID, revisionNumber)
+
+	return scanBreedRevision(row)
+}
+
+// DiffBreedRevisions compares two revisions field by field
+func DiffBreedRevisions(catID, fromRevision, toRevision int) (RevisionDiff, error) {
+	from, err := GetBreedRevision(catID, fromRevision)
+	if err != nil {
+		return RevisionDiff{}, err
+	}
+
+	to, err := GetBreedRevision(catID, toRevision)
+	if err != nil {
+		return RevisionDiff{}, err
+	}
+
+	diff := RevisionDiff{
+		BreedID:      catID,
+		FromRevision: fromRevision,
+		ToRevision:   toRevision,
+		Changes:      []RevisionFieldDiff{},
+	}
+
+	fields := []struct {
+		name     string
+		from, to string
+	}{
+		{"name", from.Name, to.Name},
+		{"origin", from.Origin, to.Origin},
+		{"description", from.Description, to.Description},
+		{"care", from.Care, to.Care},
+		{"image_url", from.ImageURL, to.ImageURL},
+	}
+
+	for _, f := range fields {
+		if f.from != f.to {
+			diff.Changes = append(diff.Changes, RevisionFieldDiff{Field: f.name, From: f.from, To: f.to})
+		}
+	}
+
+	fromAttrs, err := attributeMap(from.Attributes)
+	if err != nil {
+		return RevisionDiff{}, err
+	}
+	toAttrs, err := attributeMap(to.Attributes)
+	if err != nil {
+		return RevisionDiff{}, err
+	}
+
+	keys := make([]string, 0, len(fromAttrs))
+	for key := range fromAttrs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if !reflect.DeepEqual(fromAttrs[key], toAttrs[key]) {
+			diff.Changes = append(diff.Changes, RevisionFieldDiff{
+				Field: "attributes." + key,
+				From:  fromAttrs[key],
+				To:    toAttrs[key],
+			})
+		}
+	}
+
+	return diff, nil
+}
+
+// attributeMap flattens attributes into their JSON field names and values
+func attributeMap(attrs BreedAttributes) (map[string]any, error) {
+	data, err := json.Marshal(attrs)
+	if err != nil {
+		return nil, err
+	}
+
+	m := map[string]any{}
+	err = json.Unmarshal(data, &m)
+	return m, err
+}
+
+// RollbackCat restores a breed to the snapshot of a previous revision.
+// The rollback itself is recorded as a new revision. The cover (image_url) is
+// left as it is: it belongs to the gallery, and the old image may be deleted.
+func RollbackCat(catID, revisionNumber, userID int) (Cat, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return Cat{}, err
+	}
+	defer tx.Rollback()
+
+	var target BreedRevision
+	var attributesJSON []byte
+	err = tx.QueryRow(`
+		SELECT name, origin, description, care_instructions, attributes
+		FROM breed_revisions
+		WHERE breed_id = $1 AND revision_number = $2
+	`, catID, revisionNumber).Scan(
+		&target.Name, &target.Origin, &target.Description, &target.Care, &attributesJSON,
+	)
+	if err != nil {
+		return Cat{}, err
+	}
+
+	if len(attributesJSON) > 0 {
+		if err := json.Unmarshal(attributesJSON, &target.Attributes); err != nil {
+			return Cat{}, err
+		}
+	}
+
+	var previousName string
+	err = tx.QueryRow(`
+		SELECT name FROM cat_breeds WHERE id = $1 AND deleted_at IS NULL FOR UPDATE
+	`, catID).Scan(&previousName)
+	if err != nil {
+		return Cat{}, err
+	}
+
+	var cat Cat
+	attrs := target.Attributes
+
+	row := tx.QueryRow(`
+		UPDATE cat_breeds AS cb
+		SET name = $1, origin = $2, description = $3, care_instructions = $4,
+		    weight_min_kg = $6, weight_max_kg = $7,
+		    lifespan_min_years = $8, lifespan_max_years = $9,
+		    coat_length = $10, shedding_level = $11, activity_level = $12, grooming_level = $13,
+		    hypoallergenic = COALESCE($14, FALSE), child_friendly = $15, pet_friendly = $16,
+		    temperament = COALESCE($17::text[], '{}'),
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $5 AND deleted_at IS NULL
+		RETURNING `+catColumns,
+		target.Name, target.Origin, target.Description, target.Care, catID,
+		attrs.WeightMinKg, attrs.WeightMaxKg, attrs.LifespanMinYears, attrs.LifespanMaxYears,
+		attrs.CoatLength, attrs.SheddingLevel, attrs.ActivityLevel, attrs.GroomingLevel,
+		attrs.Hypoallergenic, attrs.ChildFriendly, attrs.PetFriendly, pq.Array(attrs.Temperament),
+	)
+
+	if err := scanCat(row, &cat); err != nil {
+		return Cat{}, err
+	}
+
+	if err := syncCatSlug(tx, &cat, previousName); err != nil {
+		return Cat{}, err
+	}
+
+	if err := recordBreedRevision(tx, cat, "rollback", &revisionNumber, userID); err != nil {
+		return Cat{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Cat{}, err
+	}
+
+	return cat, nil
+}
+
+func scanBreedRevision(scanner rowScanner) (BreedRevision, error) {
+	var revision BreedRevision
+	var rolledBackFrom, changedBy sql.NullInt64
+	var changedByUsername sql.NullString
+	var attributesJSON []byte
+
+	err := scanner.Scan(
+		&revision.ID, &revision.BreedID, &revision.RevisionNumber, &revision.Action, &rolledBackFrom,
+		&revision.Name, &revision.Origin, &revision.Description, &revision.Care, &revision.ImageURL, &attributesJSON,
+		&changedBy, &changedByUsername, &revision.CreatedAt,
+	)
+	if err != nil {
+		return BreedRevision{}, err
+	}
+
+	if len(attributesJSON) > 0 {
+		if err := json.Unmarshal(attributesJSON, &revision.Attributes); err != nil {
+			return BreedRevision{}, err
+		}
+	}
+
+	if rolledBackFrom.Valid {
+		rb := int(rolledBackFrom.Int64)
+		revision.RolledBackFrom = &rb
+	}
+
+	if changedBy.Valid {
+		cb := int(changedBy.Int64)
+		revision.ChangedBy = &cb
+	}
+
+	if changedByUsername.Valid {
+		revision.ChangedByUsername = &changedByUsername.String
+	}
+
+	return revision, nil
+}
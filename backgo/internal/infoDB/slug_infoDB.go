@@ -0,0 +1,212 @@
+package infoDB
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/lib/pq"
+	"golang.org/x/text/unicode/norm"
+)
+
+// ErrSlugTaken is returned when another breed took the chosen slug between
+// uniqueSlug's check and the write (concurrent creates or renames); retrying works.
+var ErrSlugTaken = errors.New("slug was taken by another breed, try again")
+
+// ===================== Breed Slug Functions =====================
+
+// Slugify turns a breed name into a URL slug ("Scottish Fold" -> "scottish-fold").
+// Accents are stripped from Latin letters only; letters of other scripts (e.g. Thai,
+// whose vowels and tone marks are combining characters) are kept as they are, in NFC
+// form so that ジ or ำ match what browsers send.
+func Slugify(name string) string {
+	var b strings.Builder
+	lastWasLatin := false
+	pendingDash := false
+
+	write := func(r rune) {
+		if pendingDash && b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		pendingDash = false
+		b.WriteRune(unicode.ToLower(r))
+	}
+
+	for _, r := range norm.NFC.String(name) {
+		switch {
+		case unicode.Is(unicode.Latin, r):
+			// é -> e, ﬁ -> fi, Ａ -> a
+			for _, d := range norm.NFKD.String(string(r)) {
+				if unicode.IsLetter(d) || unicode.IsDigit(d) {
+					write(d)
+				}
+			}
+			lastWasLatin = true
+		case unicode.IsMark(r):
+			if lastWasLatin {
+				continue
+			}
+			if b.Len() > 0 && !pendingDash {
+				b.WriteRune(r)
+			}
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			write(r)
+			lastWasLatin = false
+		default:
+			pendingDash = true
+			lastWasLatin = false
+		}
+	}
+
+	slug := b.String()
+	if slug == "" {
+		return "breed"
+	}
+
+	// จำกัดความยาวตามคอลัมน์ slug (เผื่อที่ไว้สำหรับ suffix -N)
+	runes := []rune(slug)
+	if len(runes) > 200 {
+		slug = strings.TrimRight(string(runes[:200]), "-")
+	}
+	return slug
+}
+
+// reservedSlugs are static routes under /api/cats/ that a breed slug would be shadowed by
+var reservedSlugs = map[string]bool{
+	"trending": true,
+	"top":      true,
+	"compare":  true,
+}
+
+// slugBase is the slug of name before collision suffixes. All-digit slugs get
+// "-breed" appended, since catIDParam reads "1984" as a numeric ID.
+func slugBase(name string) string {
+	base := Slugify(name)
+	if _, err := strconv.Atoi(base); err == nil {
+		base += "-breed"
+	}
+	return base
+}
+
+// slugTaken reports whether slug is used, currently or historically, by a breed other than catID
+func slugTaken(tx *sql.Tx, slug string, catID int) (bool, error) {
+	var taken bool
+	err := tx.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM cat_breeds WHERE slug = $1 AND id <> $2)
+		    OR EXISTS (SELECT 1 FROM breed_slug_history WHERE slug = $1 AND breed_id <> $2)
+	`, slug, catID).Scan(&taken)
+
+	return taken, err
+}
+
+// uniqueSlug finds a free slug for name, appending -2, -3, ... on collision.
+// catID is the breed the slug is for (0 for a new breed). name may also be a
+// requested slug, which is normalized the same way.
+func uniqueSlug(tx *sql.Tx, name string, catID int) (string, error) {
+	base := slugBase(name)
+
+	for n := 1; ; n++ {
+		candidate := base
+		if n > 1 {
+			candidate = base + "-" + strconv.Itoa(n)
+		}
+
+		if reservedSlugs[candidate] {
+			continue
+		}
+
+		taken, err := slugTaken(tx, candidate, catID)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return candidate, nil
+		}
+	}
+}
+
+// slugConflict maps a unique violation on cat_breeds.slug to ErrSlugTaken
+func slugConflict(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == "cat_breeds_slug_key" {
+		return ErrSlugTaken
+	}
+	return err
+}
+
+// slugMatchesName reports whether slug was generated from name (possibly with a -N suffix)
+func slugMatchesName(slug, name string) bool {
+	base := slugBase(name)
+	if slug == base {
+		return true
+	}
+
+	suffix, ok := strings.CutPrefix(slug, base+"-")
+	if !ok {
+		return false
+	}
+	_, err := strconv.Atoi(suffix)
+	return err == nil
+}
+
+// syncCatSlug regenerates the slug of a renamed breed. The old slug is kept in
+// breed_slug_history so existing links can be redirected. A breed that keeps its
+// name keeps its slug, even one that was not generated from the name (see ImportCats).
+func syncCatSlug(tx *sql.Tx, cat *Cat, previousName string) error {
+	if cat.Name == previousName || slugMatchesName(cat.Slug, cat.Name) {
+		return nil
+	}
+
+	newSlug, err := uniqueSlug(tx, cat.Name, cat.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO breed_slug_history (slug, breed_id) VALUES ($1, $2)
+		ON CONFLICT (slug) DO NOTHING
+	`, cat.Slug, cat.ID)
+	if err != nil {
+		return err
+	}
+
+	// slug ใหม่อาจเคยเป็น slug เก่าของ breed นี้ (เปลี่ยนชื่อกลับ)
+	_, err = tx.Exec(`DELETE FROM breed_slug_history WHERE slug = $1`, newSlug)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`UPDATE cat_breeds SET slug = $1 WHERE id = $2`, newSlug, cat.ID)
+	if err != nil {
+		return slugConflict(err)
+	}
+
+	cat.Slug = newSlug
+	return nil
+}
+
+// ResolveCatSlug finds the breed for a current or historical slug.
+// It returns the breed ID and its current slug; the two slugs differ for an old link.
+func ResolveCatSlug(slug string) (int, string, error) {
+	var catID int
+	var currentSlug string
+
+	err := db.QueryRow(`
+		SELECT id, slug FROM cat_breeds
+		WHERE slug = $1 AND deleted_at IS NULL
+	`, slug).Scan(&catID, &currentSlug)
+	if err != sql.ErrNoRows {
+		return catID, currentSlug, err
+	}
+
+	err = db.QueryRow(`
+		SELECT cb.id, cb.slug
+		FROM breed_slug_history h
+		JOIN cat_breeds cb ON h.breed_id = cb.id
+		WHERE h.slug = $1 AND cb.deleted_at IS NULL
+	`, slug).Scan(&catID, &currentSlug)
+
+	return catID, currentSlug, err
+}
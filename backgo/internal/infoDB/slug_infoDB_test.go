@@ -0,0 +1,101 @@
+package infoDB
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/lib/pq"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Scottish Fold", "scottish-fold"},
+		{"  Maine   Coon!  ", "maine-coon"},
+		{"Chartreux Élégant", "chartreux-elegant"},
+		{"Ñandú Ñ", "nandu-n"},
+		{"วิเชียรมาศ", "วิเชียรมาศ"}, // สระและวรรณยุกต์ไทยต้องไม่หาย
+		{"ขาวมณี (Khao Manee)", "ขาวมณี-khao-manee"},
+		{"ขนมจีนน้ำยา", "ขนมจีนน้ำยา"}, // ำ ต้องไม่ถูกแยกเป็น ํ + า
+		{"मार्जार", "मार्जार"},
+		{"暹罗猫", "暹罗猫"},
+		{"スコティッシュ・フォールド", "スコティッシュ-フォールド"},
+		{"", "breed"},
+		{"  !!  ", "breed"},
+		{"1984", "1984"},
+		{"Cat 9", "cat-9"},
+		{"Ｍａｉｎｅ ﬁne", "maine-fine"},
+	}
+
+	for _, tt := range tests {
+		if got := Slugify(tt.name); got != tt.want {
+			t.Errorf("Slugify(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	// ตัดที่ 200 ตัวอักษรโดยไม่เหลือ - ท้าย
+	long := Slugify(strings.Repeat("abc ", 100))
+	if n := utf8.RuneCountInString(long); n != 199 || strings.HasSuffix(long, "-") {
+		t.Errorf("long name: %d runes, %q", n, long[len(long)-8:])
+	}
+	if n := utf8.RuneCountInString(Slugify(strings.Repeat("แมว", 100))); n != 200 {
+		t.Errorf("long Thai name: %d runes, want 200", n)
+	}
+}
+
+func TestSlugBaseNumericNames(t *testing.T) {
+	// slug ที่เป็นตัวเลขล้วนจะถูกอ่านเป็น id ใน catIDParam
+	for name, want := range map[string]string{
+		"1984":    "1984-breed",
+		"007":     "007-breed",
+		"Cat 9":   "cat-9",
+		"19 84":   "19-84",
+		"Ragdoll": "ragdoll",
+	} {
+		if got := slugBase(name); got != want {
+			t.Errorf("slugBase(%q) = %q, want %q", name, got, want)
+		}
+	}
+
+	if !slugMatchesName("1984-breed", "1984") || !slugMatchesName("1984-breed-2", "1984") {
+		t.Error("numeric name should keep its suffixed slug")
+	}
+	if slugMatchesName("1984", "1984") {
+		t.Error("an all-digit slug should be regenerated")
+	}
+}
+
+func TestSlugBaseRequestedSlugs(t *testing.T) {
+	// slug จากไฟล์ import ผ่าน uniqueSlug เหมือนชื่อ: slug ที่ถูกต้องอยู่แล้วไม่เปลี่ยน
+	for requested, want := range map[string]string{
+		"persian-longhair": "persian-longhair",
+		"Persian Longhair": "persian-longhair",
+		"--siamese--":      "siamese",
+		"42":               "42-breed",
+		"!!!":              "breed",
+	} {
+		if got := slugBase(requested); got != want {
+			t.Errorf("slugBase(%q) = %q, want %q", requested, got, want)
+		}
+	}
+}
+
+func TestSlugConflict(t *testing.T) {
+	taken := &pq.Error{Code: "23505", Constraint: "cat_breeds_slug_key"}
+	if err := slugConflict(fmt.Errorf("insert: %w", taken)); err != ErrSlugTaken {
+		t.Errorf("slug violation: err = %v, want ErrSlugTaken", err)
+	}
+
+	// violation อื่นต้องส่งต่อไปตามเดิม
+	other := &pq.Error{Code: "23505", Constraint: "breed_slug_history_pkey"}
+	if err := slugConflict(other); err != other {
+		t.Errorf("other violation: err = %v, want it unchanged", err)
+	}
+	if err := slugConflict(nil); err != nil {
+		t.Errorf("nil: err = %v", err)
+	}
+}
@@ -0,0 +1,637 @@
+package infoDB
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ===================== Breed Submission Models =====================
+
+type BreedSubmission struct {
+	ID                int    `json:"id"`
+	Kind              string `json:"kind"` // "create" or "edit"
+	BreedID           *int   `json:"breed_id"`
+	SubmittedBy       int    `json:"submitted_by"`
+	SubmitterUsername string `json:"submitter_username"`
+	// "draft", "pending", "changes_requested", "approved" or "rejected"
+	Status string `json:"status"`
+
+	// edit proposals are partial like UpdateCat; create proposals require a name
+	Proposal UpdateCatRequest `json:"proposal"`
+	Note     string           `json:"note"`
+
+	SubmittedAt      *time.Time `json:"submitted_at,omitempty"`
+	ReviewedBy       *int       `json:"reviewed_by,omitempty"`
+	ReviewerUsername *string    `json:"reviewer_username,omitempty"`
+	ReviewedAt       *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+
+	// เฉพาะ GET รายการเดียว: สิ่งที่ edit จะเปลี่ยนจากข้อมูลปัจจุบัน และความเห็นทั้งหมด
+	Changes  []RevisionFieldDiff `json:"changes,omitempty"`
+	Comments []SubmissionComment `json:"comments,omitempty"`
+}
+
+type SubmissionComment struct {
+	ID       int     `json:"id"`
+	UserID   *int    `json:"user_id"`
+	Username *string `json:"username"`
+	// สถานะที่เปลี่ยนไปพร้อมความเห็นนี้ (ถ้ามี)
+	Status    *string   `json:"status,omitempty"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type BreedContributor struct {
+	UserID            int       `json:"user_id"`
+	Username          string    `json:"username"`
+	Contributions     int       `json:"contributions"`
+	LastContributedAt time.Time `json:"last_contributed_at"`
+}
+
+type CreateSubmissionRequest struct {
+	// ระบุเพื่อเสนอแก้ไขสายพันธุ์ที่มีอยู่ ไม่ระบุ = เสนอสายพันธุ์ใหม่
+	BreedID *int             `json:"breed_id"`
+	Breed   UpdateCatRequest `json:"breed"`
+	Note    string           `json:"note" binding:"max=2000"`
+	// ส่งให้ตรวจทันทีแทนการเก็บเป็น draft
+	Submit bool `json:"submit"`
+}
+
+type UpdateSubmissionRequest struct {
+	Breed UpdateCatRequest `json:"breed"`
+	Note  string           `json:"note" binding:"max=2000"`
+}
+
+type ReviewSubmissionRequest struct {
+	Decision string `json:"decision" binding:"required,oneof=approve reject request_changes"`
+	Comment  string `json:"comment" binding:"max=2000"`
+}
+
+// SubmissionStatusRequest carries an optional message when submitting or withdrawing
+type SubmissionStatusRequest struct {
+	Message string `json:"message" binding:"max=2000"`
+}
+
+type SubmissionCommentRequest struct {
+	Message string `json:"message" binding:"required,min=1,max=2000"`
+}
+
+var SubmissionStatuses = []string{"draft", "pending", "changes_requested", "approved", "rejected"}
+
+// จำนวนข้อเสนอที่ยังไม่ปิด (draft/pending/changes_requested) ต่อผู้ใช้
+const maxOpenSubmissions = 20
+
+var (
+	ErrSubmissionState            = errors.New("submission cannot be changed in its current state")
+	ErrTooManySubmissions         = errors.New("open submission limit reached")
+	ErrReviewCommentRequired      = errors.New("a comment is required when rejecting or requesting changes")
+	ErrSubmissionBreedUnavailable = errors.New("the breed of this submission no longer exists")
+	ErrProposalNameRequired       = errors.New("a name is required when proposing a new breed")
+)
+
+const submissionColumns = `
+	s.id, s.kind, s.breed_id, s.submitted_by, su.username, s.status, s.proposal, s.note,
+	s.submitted_at, s.reviewed_by, ru.username, s.reviewed_at, s.created_at, s.updated_at`
+
+const submissionFrom = `
+	FROM breed_submissions s
+	JOIN users su ON su.id = s.submitted_by
+	LEFT JOIN users ru ON ru.id = s.reviewed_by`
+
+// ===================== Breed Submission Functions =====================
+
+// CreateSubmission stores a proposal as a draft, or as pending when req.Submit is set
+func CreateSubmission(userID int, req CreateSubmissionRequest) (BreedSubmission, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return BreedSubmission{}, err
+	}
+	defer tx.Rollback()
+
+	var open int
+	err = tx.QueryRow(`
+		SELECT COUNT(*) FROM breed_submissions
+		WHERE submitted_by = $1 AND status IN ('draft', 'pending', 'changes_requested')
+	`, userID).Scan(&open)
+	if err != nil {
+		return BreedSubmission{}, err
+	}
+	if open >= maxOpenSubmissions {
+		return BreedSubmission{}, ErrTooManySubmissions
+	}
+
+	kind := "create"
+	if req.BreedID != nil {
+		kind = "edit"
+		if err := requireActiveBreed(tx, *req.BreedID); err != nil {
+			return BreedSubmission{}, err
+		}
+	}
+	if err := validateProposal(tx, kind, req.BreedID, req.Breed); err != nil {
+		return BreedSubmission{}, err
+	}
+
+	proposal, err := json.Marshal(req.Breed)
+	if err != nil {
+		return BreedSubmission{}, err
+	}
+
+	status := "draft"
+	if req.Submit {
+		status = "pending"
+	}
+
+	var id int
+	err = tx.QueryRow(`
+		INSERT INTO breed_submissions (kind, breed_id, submitted_by, status, proposal, note, submitted_at)
+		VALUES ($1, $2, $3, $4, $5, $6, CASE WHEN $7 THEN CURRENT_TIMESTAMP END)
+		RETURNING id
+	`, kind, req.BreedID, userID, status, proposal, req.Note, req.Submit).Scan(&id)
+	if err != nil {
+		return BreedSubmission{}, err
+	}
+
+	if req.Submit {
+		if err := addSubmissionComment(tx, id, userID, &status, ""); err != nil {
+			return BreedSubmission{}, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return BreedSubmission{}, err
+	}
+
+	return GetSubmission(id, &userID)
+}
+
+// UpdateSubmission replaces the proposal of a draft or of a submission sent back for changes
+func UpdateSubmission(userID, submissionID int, req UpdateSubmissionRequest) (BreedSubmission, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return BreedSubmission{}, err
+	}
+	defer tx.Rollback()
+
+	s, err := lockSubmission(tx, submissionID, &userID)
+	if err != nil {
+		return BreedSubmission{}, err
+	}
+	if s.Status != "draft" && s.Status != "changes_requested" {
+		return BreedSubmission{}, ErrSubmissionState
+	}
+	if err := validateProposal(tx, s.Kind, s.BreedID, req.Breed); err == sql.ErrNoRows {
+		return BreedSubmission{}, ErrSubmissionBreedUnavailable
+	} else if err != nil {
+		return BreedSubmission{}, err
+	}
+
+	proposal, err := json.Marshal(req.Breed)
+	if err != nil {
+		return BreedSubmission{}, err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE breed_submissions SET proposal = $1, note = $2 WHERE id = $3
+	`, proposal, req.Note, submissionID)
+	if err != nil {
+		return BreedSubmission{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return BreedSubmission{}, err
+	}
+
+	return GetSubmission(submissionID, &userID)
+}
+
+// SubmitSubmission sends a draft (or a revised submission) to the review queue
+func SubmitSubmission(userID, submissionID int, message string) (BreedSubmission, error) {
+	return changeOwnSubmissionStatus(userID, submissionID, message, "pending", "draft", "changes_requested")
+}
+
+// WithdrawSubmission takes a pending submission back to draft
+func WithdrawSubmission(userID, submissionID int, message string) (BreedSubmission, error) {
+	return changeOwnSubmissionStatus(userID, submissionID, message, "draft", "pending")
+}
+
+func changeOwnSubmissionStatus(userID, submissionID int, message, to string, from ...string) (BreedSubmission, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return BreedSubmission{}, err
+	}
+	defer tx.Rollback()
+
+	s, err := lockSubmission(tx, submissionID, &userID)
+	if err != nil {
+		return BreedSubmission{}, err
+	}
+
+	allowed := false
+	for _, status := range from {
+		if s.Status == status {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return BreedSubmission{}, ErrSubmissionState
+	}
+
+	if to == "pending" && s.Kind == "edit" {
+		if s.BreedID == nil {
+			return BreedSubmission{}, ErrSubmissionBreedUnavailable
+		}
+		if err := requireActiveBreed(tx, *s.BreedID); err == sql.ErrNoRows {
+			return BreedSubmission{}, ErrSubmissionBreedUnavailable
+		} else if err != nil {
+			return BreedSubmission{}, err
+		}
+	}
+	if to == "pending" {
+		// breed อาจถูกแก้ไปแล้วตั้งแต่บันทึก draft
+		if err := validateProposal(tx, s.Kind, s.BreedID, s.Proposal); err != nil {
+			return BreedSubmission{}, err
+		}
+	}
+
+	_, err = tx.Exec(`
+		UPDATE breed_submissions
+		SET status = $1::submission_status_enum,
+		    submitted_at = CASE WHEN $1::submission_status_enum = 'pending' THEN CURRENT_TIMESTAMP ELSE submitted_at END
+		WHERE id = $2
+	`, to, submissionID)
+	if err != nil {
+		return BreedSubmission{}, err
+	}
+
+	if err := addSubmissionComment(tx, submissionID, userID, &to, message); err != nil {
+		return BreedSubmission{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return BreedSubmission{}, err
+	}
+
+	return GetSubmission(submissionID, &userID)
+}
+
+// DeleteSubmission deletes a submission of the user that has not been approved
+func DeleteSubmission(userID, submissionID int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	s, err := lockSubmission(tx, submissionID, &userID)
+	if err != nil {
+		return err
+	}
+	if s.Status == "approved" {
+		return ErrSubmissionState
+	}
+
+	if _, err := tx.Exec(`DELETE FROM breed_submissions WHERE id = $1`, submissionID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ReviewSubmission approves, rejects or sends back a pending submission.
+// Approving applies the proposal through the same code as CreateCat/UpdateCat,
+// credited to the submitter.
+func ReviewSubmission(reviewerID, submissionID int, req ReviewSubmissionRequest) (BreedSubmission, error) {
+	if req.Decision != "approve" && req.Comment == "" {
+		return BreedSubmission{}, ErrReviewCommentRequired
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return BreedSubmission{}, err
+	}
+	defer tx.Rollback()
+
+	s, err := lockSubmission(tx, submissionID, nil)
+	if err != nil {
+		return BreedSubmission{}, err
+	}
+	if s.Status != "pending" {
+		return BreedSubmission{}, ErrSubmissionState
+	}
+
+	status := map[string]string{
+		"approve":         "approved",
+		"reject":          "rejected",
+		"request_changes": "changes_requested",
+	}[req.Decision]
+
+	breedID := s.BreedID
+	if status == "approved" {
+		var cat Cat
+		if s.Kind == "create" {
+			cat, err = createCatTx(tx, s.SubmittedBy, CreateCatRequest(s.Proposal), "")
+		} else if s.BreedID == nil {
+			err = ErrSubmissionBreedUnavailable
+		} else {
+			// updateCatTx ตรวจช่วงค่ากับข้อมูลปัจจุบันของ breed อีกครั้ง
+			cat, err = updateCatTx(tx, *s.BreedID, s.SubmittedBy, s.Proposal)
+			if err == sql.ErrNoRows {
+				err = ErrSubmissionBreedUnavailable
+			}
+		}
+		if err != nil {
+			return BreedSubmission{}, err
+		}
+		breedID = &cat.ID
+	}
+
+	_, err = tx.Exec(`
+		UPDATE breed_submissions
+		SET status = $1, breed_id = $2, reviewed_by = $3, reviewed_at = CURRENT_TIMESTAMP
+		WHERE id = $4
+	`, status, breedID, reviewerID, submissionID)
+	if err != nil {
+		return BreedSubmission{}, err
+	}
+
+	if err := addSubmissionComment(tx, submissionID, reviewerID, &status, req.Comment); err != nil {
+		return BreedSubmission{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return BreedSubmission{}, err
+	}
+
+	return GetSubmission(submissionID, nil)
+}
+
+// CommentOnSubmission adds a comment without changing the status.
+// ownerID limits it to the submitter's own submissions; nil is a reviewer.
+func CommentOnSubmission(submissionID, userID int, ownerID *int, message string) (SubmissionComment, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return SubmissionComment{}, err
+	}
+	defer tx.Rollback()
+
+	if _, err := lockSubmission(tx, submissionID, ownerID); err != nil {
+		return SubmissionComment{}, err
+	}
+
+	var comment SubmissionComment
+	err = tx.QueryRow(`
+		WITH inserted AS (
+			INSERT INTO submission_comments (submission_id, user_id, message)
+			VALUES ($1, $2, $3)
+			RETURNING id, user_id, status, message, created_at
+		)
+		SELECT i.id, i.user_id, u.username, i.status, i.message, i.created_at
+		FROM inserted i
+		LEFT JOIN users u ON u.id = i.user_id
+	`, submissionID, userID, message).Scan(
+		&comment.ID, &comment.UserID, &comment.Username, &comment.Status, &comment.Message, &comment.CreatedAt,
+	)
+	if err != nil {
+		return SubmissionComment{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return SubmissionComment{}, err
+	}
+
+	return comment, nil
+}
+
+// GetSubmission gets a submission with its comments and, for open edit
+// proposals, the changes it would make. ownerID limits it to the submitter.
+func GetSubmission(submissionID int, ownerID *int) (BreedSubmission, error) {
+	query := `SELECT ` + submissionColumns + submissionFrom + ` WHERE s.id = $1`
+	args := []any{submissionID}
+	if ownerID != nil {
+		query += ` AND s.submitted_by = $2`
+		args = append(args, *ownerID)
+	}
+
+	s, err := scanSubmission(db.QueryRow(query, args...))
+	if err != nil {
+		return BreedSubmission{}, err
+	}
+
+	if s.Kind == "edit" && s.BreedID != nil && s.Status != "approved" && s.Status != "rejected" {
+		current, err := GetCat(*s.BreedID, nil)
+		if err != nil && err != sql.ErrNoRows {
+			return BreedSubmission{}, err
+		}
+		if err == nil {
+			s.Changes, err = proposalChanges(current, s.Proposal)
+			if err != nil {
+				return BreedSubmission{}, err
+			}
+		}
+	}
+
+	rows, err := db.Query(`
+		SELECT sc.id, sc.user_id, u.username, sc.status, sc.message, sc.created_at
+		FROM submission_comments sc
+		LEFT JOIN users u ON u.id = sc.user_id
+		WHERE sc.submission_id = $1
+		ORDER BY sc.created_at, sc.id
+	`, submissionID)
+	if err != nil {
+		return BreedSubmission{}, err
+	}
+	defer rows.Close()
+
+	s.Comments = []SubmissionComment{}
+	for rows.Next() {
+		var c SubmissionComment
+		if err := rows.Scan(&c.ID, &c.UserID, &c.Username, &c.Status, &c.Message, &c.CreatedAt); err != nil {
+			return BreedSubmission{}, err
+		}
+		s.Comments = append(s.Comments, c)
+	}
+
+	return s, rows.Err()
+}
+
+// GetSubmissions lists submissions, optionally of one user and/or in one status.
+// The review queue (pending) is oldest first, everything else newest first.
+func GetSubmissions(ownerID *int, status string, limit, offset int) ([]BreedSubmission, error) {
+	args := []any{}
+	where := ` WHERE TRUE`
+	if ownerID != nil {
+		args = append(args, *ownerID)
+		where += ` AND s.submitted_by = $1`
+	}
+	if status != "" {
+		args = append(args, status)
+		where += ` AND s.status = $` + strconv.Itoa(len(args))
+	}
+
+	order := ` ORDER BY s.created_at DESC, s.id DESC`
+	if status == "pending" {
+		order = ` ORDER BY s.submitted_at, s.id`
+	}
+
+	args = append(args, limit, offset)
+	rows, err := db.Query(`SELECT `+submissionColumns+submissionFrom+where+order+
+		` LIMIT $`+strconv.Itoa(len(args)-1)+` OFFSET $`+strconv.Itoa(len(args)), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	submissions := []BreedSubmission{}
+	for rows.Next() {
+		s, err := scanSubmission(rows)
+		if err != nil {
+			return nil, err
+		}
+		submissions = append(submissions, s)
+	}
+
+	return submissions, rows.Err()
+}
+
+// GetCatContributors lists the users whose approved submissions shaped a breed
+func GetCatContributors(catID int) ([]BreedContributor, error) {
+	rows, err := db.Query(`
+		SELECT u.id, u.username, COUNT(*), MAX(s.reviewed_at)
+		FROM breed_submissions s
+		JOIN users u ON u.id = s.submitted_by
+		WHERE s.breed_id = $1 AND s.status = 'approved'
+		GROUP BY u.id, u.username
+		ORDER BY COUNT(*) DESC, MAX(s.reviewed_at) DESC
+	`, catID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	contributors := []BreedContributor{}
+	for rows.Next() {
+		var c BreedContributor
+		if err := rows.Scan(&c.UserID, &c.Username, &c.Contributions, &c.LastContributedAt); err != nil {
+			return nil, err
+		}
+		contributors = append(contributors, c)
+	}
+
+	return contributors, rows.Err()
+}
+
+// lockSubmission locks a submission row for a status change. ownerID limits it to the submitter.
+func lockSubmission(tx *sql.Tx, submissionID int, ownerID *int) (BreedSubmission, error) {
+	query := `SELECT ` + submissionColumns + submissionFrom + ` WHERE s.id = $1`
+	args := []any{submissionID}
+	if ownerID != nil {
+		query += ` AND s.submitted_by = $2`
+		args = append(args, *ownerID)
+	}
+
+	return scanSubmission(tx.QueryRow(query+` FOR UPDATE OF s`, args...))
+}
+
+func requireActiveBreed(tx *sql.Tx, catID int) error {
+	var id int
+	return tx.QueryRow(`SELECT id FROM cat_breeds WHERE id = $1 AND deleted_at IS NULL`, catID).Scan(&id)
+}
+
+func addSubmissionComment(tx *sql.Tx, submissionID, userID int, status *string, message string) error {
+	_, err := tx.Exec(`
+		INSERT INTO submission_comments (submission_id, user_id, status, message)
+		VALUES ($1, $2, $3, $4)
+	`, submissionID, userID, status, message)
+	return err
+}
+
+func scanSubmission(scanner rowScanner) (BreedSubmission, error) {
+	var s BreedSubmission
+	var proposal []byte
+
+	err := scanner.Scan(
+		&s.ID, &s.Kind, &s.BreedID, &s.SubmittedBy, &s.SubmitterUsername, &s.Status, &proposal, &s.Note,
+		&s.SubmittedAt, &s.ReviewedBy, &s.ReviewerUsername, &s.ReviewedAt, &s.CreatedAt, &s.UpdatedAt,
+	)
+	if err != nil {
+		return BreedSubmission{}, err
+	}
+
+	if err := json.Unmarshal(proposal, &s.Proposal); err != nil {
+		return BreedSubmission{}, err
+	}
+
+	return s, nil
+}
+
+// validateProposal checks a proposal the way CreateCat/UpdateCat would apply it:
+// a new breed needs a name, and an edit's ranges must fit the breed's current values
+func validateProposal(tx *sql.Tx, kind string, breedID *int, p UpdateCatRequest) error {
+	if kind == "create" {
+		if strings.TrimSpace(p.Name) == "" {
+			return ErrProposalNameRequired
+		}
+		return p.Attributes.ValidateRanges()
+	}
+	if breedID == nil {
+		return ErrSubmissionBreedUnavailable
+	}
+	return validateStoredRanges(tx, *breedID, p.Attributes)
+}
+
+// proposalChanges lists what an edit proposal would change on the current breed.
+// Empty fields and null attributes keep their value, as in UpdateCat.
+func proposalChanges(current Cat, p UpdateCatRequest) ([]RevisionFieldDiff, error) {
+	changes := []RevisionFieldDiff{}
+
+	fields := []struct {
+		name     string
+		from, to string
+	}{
+		{"name", current.Name, p.Name},
+		{"origin", current.Origin, p.Origin},
+		{"description", current.Description, p.Description},
+		{"care", current.Care, p.Care},
+		{"image_url", current.ImageURL, p.ImageURL},
+	}
+	for _, f := range fields {
+		if f.to != "" && f.to != f.from {
+			changes = append(changes, RevisionFieldDiff{Field: f.name, From: f.from, To: f.to})
+		}
+	}
+
+	fromAttrs, err := attributeMap(current.Attributes)
+	if err != nil {
+		return nil, err
+	}
+	toAttrs, err := attributeMap(p.Attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(toAttrs))
+	for key := range toAttrs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if toAttrs[key] != nil && !reflect.DeepEqual(fromAttrs[key], toAttrs[key]) {
+			changes = append(changes, RevisionFieldDiff{
+				Field: "attributes." + key,
+				From:  fromAttrs[key],
+				To:    toAttrs[key],
+			})
+		}
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,29 @@
+package infoDB
+
+import "testing"
+
+func TestValidateProposalCreate(t *testing.T) {
+	weightMin, weightMax := 9.0, 6.0
+
+	tests := []struct {
+		name     string
+		proposal UpdateCatRequest
+		want     error
+	}{
+		{"new breed", UpdateCatRequest{Name: "Khao Manee"}, nil},
+		{"missing name", UpdateCatRequest{Origin: "Thailand"}, ErrProposalNameRequired},
+		{"blank name", UpdateCatRequest{Name: "  "}, ErrProposalNameRequired},
+		{"weight range", UpdateCatRequest{Name: "Khao Manee", Attributes: BreedAttributes{WeightMinKg: &weightMin, WeightMaxKg: &weightMax}}, ErrWeightRange},
+	}
+
+	// proposal สายพันธุ์ใหม่ไม่ต้องอ่านฐานข้อมูล
+	for _, tt := range tests {
+		if got := validateProposal(nil, "create", nil, tt.proposal); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if got := validateProposal(nil, "edit", nil, UpdateCatRequest{}); got != ErrSubmissionBreedUnavailable {
+		t.Errorf("edit without breed: got %v, want ErrSubmissionBreedUnavailable", got)
+	}
+}
@@ -0,0 +1,211 @@
+package infoDB
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ===================== Breed Translation Models =====================
+
+type BreedTranslation struct {
+	BreedID     int       `json:"breed_id"`
+	Locale      string    `json:"locale"`
+	Name        *string   `json:"name"`
+	Description *string   `json:"description"`
+	Care        *string   `json:"care"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	UpdatedBy   *int      `json:"updated_by,omitempty"`
+}
+
+// UpsertTranslationRequest sets the translated fields of one locale.
+// Fields left empty fall back to the default-locale content.
+type UpsertTranslationRequest struct {
+	Name        string `json:"name" binding:"omitempty,min=2,max=255"`
+	Description string `json:"description"`
+	Care        string `json:"care"`
+}
+
+// ===================== Locale Config =====================
+
+// เนื้อหาใน cat_breeds คือภาษา default ส่วนภาษาอื่นเก็บใน breed_translations
+var defaultLocale = "th"
+var supportedLocales = []string{"th", "en"}
+
+// SetLocales configures the default locale and the locales the API can serve
+func SetLocales(defaultLoc string, supported []string) {
+	defaultLocale = strings.ToLower(strings.TrimSpace(defaultLoc))
+	supportedLocales = []string{defaultLocale}
+	for _, locale := range supported {
+		locale = strings.ToLower(strings.TrimSpace(locale))
+		if locale != "" && !IsSupportedLocale(locale) {
+			supportedLocales = append(supportedLocales, locale)
+		}
+	}
+}
+
+// DefaultLocale returns the locale of the base content in cat_breeds
+func DefaultLocale() string {
+	return defaultLocale
+}
+
+// SupportedLocales returns all servable locales, default first
+func SupportedLocales() []string {
+	return supportedLocales
+}
+
+// IsSupportedLocale reports whether locale is one of the configured locales
+func IsSupportedLocale(locale string) bool {
+	for _, l := range supportedLocales {
+		if l == locale {
+			return true
+		}
+	}
+	return false
+}
+
+// ===================== Breed Translation Functions =====================
+
+// TranslateCats replaces name, description and care with their translation in locale
+// where one exists, and records the locale actually served on each cat.
+func TranslateCats(cats []Cat, locale string) error {
+	for i := range cats {
+		cats[i].Locale = defaultLocale
+	}
+
+	if locale == defaultLocale || len(cats) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(cats))
+	for i, cat := range cats {
+		ids[i] = int64(cat.ID)
+	}
+
+	rows, err := db.Query(`
+		SELECT breed_id, name, description, care_instructions
+		FROM breed_translations
+		WHERE locale = $1 AND breed_id = ANY($2)
+	`, locale, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	translations := map[int]BreedTranslation{}
+	for rows.Next() {
+		var t BreedTranslation
+		if err := rows.Scan(&t.BreedID, &t.Name, &t.Description, &t.Care); err != nil {
+			return err
+		}
+		translations[t.BreedID] = t
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range cats {
+		t, ok := translations[cats[i].ID]
+		if !ok {
+			continue
+		}
+
+		if t.Name != nil && *t.Name != "" {
+			cats[i].Name = *t.Name
+		}
+		if t.Description != nil && *t.Description != "" {
+			cats[i].Description = *t.Description
+		}
+		if t.Care != nil && *t.Care != "" {
+			cats[i].Care = *t.Care
+		}
+		cats[i].Locale = locale
+	}
+
+	return nil
+}
+
+// TranslateCat is TranslateCats for a single breed
+func TranslateCat(cat *Cat, locale string) error {
+	cats := []Cat{*cat}
+	if err := TranslateCats(cats, locale); err != nil {
+		return err
+	}
+	*cat = cats[0]
+	return nil
+}
+
+// GetBreedTranslations lists all translations of a breed
+func GetBreedTranslations(catID int) ([]BreedTranslation, error) {
+	rows, err := db.Query(`
+		SELECT bt.breed_id, bt.locale, bt.name, bt.description, bt.care_instructions,
+		       bt.updated_at, bt.updated_by
+		FROM breed_translations bt
+		JOIN cat_breeds cb ON bt.breed_id = cb.id AND cb.deleted_at IS NULL
+		WHERE bt.breed_id = $1
+		ORDER BY bt.locale
+	`, catID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	translations := []BreedTranslation{}
+	for rows.Next() {
+		var t BreedTranslation
+		err := rows.Scan(&t.BreedID, &t.Locale, &t.Name, &t.Description, &t.Care, &t.UpdatedAt, &t.UpdatedBy)
+		if err != nil {
+			return nil, err
+		}
+		translations = append(translations, t)
+	}
+
+	return translations, rows.Err()
+}
+
+// UpsertBreedTranslation creates or replaces the translation of a breed for one locale
+func UpsertBreedTranslation(catID int, locale string, userID int, req UpsertTranslationRequest) (BreedTranslation, error) {
+	active, err := isCatActive(catID)
+	if err != nil {
+		return BreedTranslation{}, err
+	}
+	if !active {
+		return BreedTranslation{}, sql.ErrNoRows
+	}
+
+	var t BreedTranslation
+	err = db.QueryRow(`
+		INSERT INTO breed_translations (breed_id, locale, name, description, care_instructions, updated_by)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, ''), $6)
+		ON CONFLICT (breed_id, locale) DO UPDATE
+		SET name = EXCLUDED.name,
+		    description = EXCLUDED.description,
+		    care_instructions = EXCLUDED.care_instructions,
+		    updated_by = EXCLUDED.updated_by,
+		    updated_at = CURRENT_TIMESTAMP
+		RETURNING breed_id, locale, name, description, care_instructions, updated_at, updated_by
+	`, catID, locale, req.Name, req.Description, req.Care, userID).Scan(
+		&t.BreedID, &t.Locale, &t.Name, &t.Description, &t.Care, &t.UpdatedAt, &t.UpdatedBy,
+	)
+
+	return t, err
+}
+
+// DeleteBreedTranslation removes the translation of a breed for one locale
+func DeleteBreedTranslation(catID int, locale string) error {
+	result, err := db.Exec(`
+		DELETE FROM breed_translations WHERE breed_id = $1 AND locale = $2
+	`, catID, locale)
+	if err != nil {
+		return err
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
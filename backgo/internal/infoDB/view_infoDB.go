@@ -0,0 +1,183 @@
+package infoDB
+
+import (
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ===================== Breed View Counting =====================
+
+// views are counted in memory and written to cat_breeds in batches, so GET /cats/:id
+// never waits on (or contends for) the breed row.
+
+type catView struct {
+	catID  int
+	viewer string
+}
+
+type viewRecorder struct {
+	events        chan catView
+	window        time.Duration
+	flushInterval time.Duration
+	batchSize     int
+
+	// viewer ที่นับไปแล้ว -> เวลาที่นับ (ไม่นับซ้ำภายใน window)
+	seen    map[catView]time.Time
+	pending map[int]int
+
+	// views ที่ทิ้งไปเพราะ buffer เต็ม (log แล้ว reset ทุกรอบ flush)
+	dropped atomic.Int64
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+var views *viewRecorder
+
+// StartViewRecorder starts the background view aggregator. A viewer is counted at most
+// once per breed per window; pending counts are written every flushInterval, or sooner
+// once batchSize views are waiting.
+func StartViewRecorder(window, flushInterval time.Duration, bufferSize, batchSize int) {
+	views = &viewRecorder{
+		events:        make(chan catView, bufferSize),
+		window:        window,
+		flushInterval: flushInterval,
+		batchSize:     batchSize,
+		seen:          map[catView]time.Time{},
+		pending:       map[int]int{},
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+
+	go views.run()
+}
+
+// RecordCatView queues a view of a breed by viewer (a user or a hashed client key).
+// It never blocks: when the buffer is full the view is dropped and counted, and the
+// drops are logged with the next flush.
+func RecordCatView(catID int, viewer string) {
+	if views == nil {
+		return
+	}
+
+	select {
+	case views.events <- catView{catID: catID, viewer: viewer}:
+	default:
+		views.dropped.Add(1)
+	}
+}
+
+// StopViewRecorder drains the views still in the buffer and writes all pending
+// counts; call it on shutdown so the last flushInterval of views is not lost.
+func StopViewRecorder() {
+	if views == nil {
+		return
+	}
+
+	close(views.stop)
+	<-views.done
+}
+
+func (r *viewRecorder) run() {
+	ticker := time.NewTicker(r.flushInterval)
+	defer ticker.Stop()
+	defer close(r.done)
+
+	queued := 0
+	for {
+		select {
+		case view := <-r.events:
+			if !r.record(view) {
+				continue
+			}
+
+			queued++
+			if queued >= r.batchSize {
+				r.flush()
+				queued = 0
+			}
+
+		case <-ticker.C:
+			r.flush()
+			queued = 0
+			r.expireSeen()
+
+		case <-r.stop:
+			// เก็บ views ที่ค้างใน buffer ให้หมดก่อน flush รอบสุดท้าย
+		drain:
+			for {
+				select {
+				case view := <-r.events:
+					r.record(view)
+				default:
+					break drain
+				}
+			}
+			r.flush()
+			return
+		}
+	}
+}
+
+// record adds a view to the pending counts unless the viewer was already counted
+// within the window
+func (r *viewRecorder) record(view catView) bool {
+	now := time.Now()
+	if at, ok := r.seen[view]; ok && now.Sub(at) < r.window {
+		return false
+	}
+	r.seen[view] = now
+	r.pending[view.catID]++
+	return true
+}
+
+// flush writes all pending counts in one statement. On failure the counts stay
+// pending and are retried with the next flush.
+func (r *viewRecorder) flush() {
+	if n := r.dropped.Swap(0); n > 0 {
+		log.Printf("Dropped %d cat view(s): view buffer full (raise VIEW_BUFFER_SIZE)", n)
+	}
+	if len(r.pending) == 0 {
+		return
+	}
+
+	ids := make([]int64, 0, len(r.pending))
+	counts := make([]int64, 0, len(r.pending))
+	for catID, n := range r.pending {
+		ids = append(ids, int64(catID))
+		counts = append(counts, int64(n))
+	}
+
+	_, err := db.Exec(`
+		WITH counted AS (
+			UPDATE cat_breeds cb
+			SET view_count = cb.view_count + v.n
+			FROM unnest($1::int[], $2::int[]) AS v(id, n)
+			WHERE cb.id = v.id
+			RETURNING cb.id, v.n
+		)
+		INSERT INTO breed_daily_stats (breed_id, day, views)
+		SELECT id, breed_stats_day(CURRENT_TIMESTAMP), n FROM counted
+		ON CONFLICT (breed_id, day) DO UPDATE
+		SET views = breed_daily_stats.views + EXCLUDED.views
+	`, pq.Array(ids), pq.Array(counts))
+	if err != nil {
+		log.Println("Failed to flush cat views:", err)
+		return
+	}
+
+	r.pending = map[int]int{}
+}
+
+// expireSeen forgets viewers whose window has passed
+func (r *viewRecorder) expireSeen() {
+	cutoff := time.Now().Add(-r.window)
+	for view, at := range r.seen {
+		if at.Before(cutoff) {
+			delete(r.seen, view)
+		}
+	}
+}
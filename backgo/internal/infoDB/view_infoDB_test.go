@@ -0,0 +1,32 @@
+package infoDB
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecordCatViewCountsDrops(t *testing.T) {
+	r := &viewRecorder{
+		events:  make(chan catView, 2),
+		window:  time.Hour,
+		seen:    map[catView]time.Time{},
+		pending: map[int]int{},
+	}
+	views = r
+	t.Cleanup(func() { views = nil })
+
+	for range 5 {
+		RecordCatView(1, "a:test")
+	}
+	if got := r.dropped.Load(); got != 3 {
+		t.Errorf("dropped = %d, want 3", got)
+	}
+
+	// ผู้ชมคนเดิมภายใน window นับครั้งเดียว
+	for len(r.events) > 0 {
+		r.record(<-r.events)
+	}
+	if r.pending[1] != 1 {
+		t.Errorf("pending = %d, want 1", r.pending[1])
+	}
+}
@@ -0,0 +1,427 @@
+package markdown
+
+import (
+	"html"
+	"net/url"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// ===================== Markdown (CommonMark subset) =====================
+//
+// Supported: paragraphs and line breaks, **bold**, *italic*, `code`, fenced code
+// blocks, > quotes, - / 1. lists, [links](https://...), bare http(s) URLs and
+// ![images](https://...) from allowlisted hosts. Everything else is escaped as
+// text, so the output only contains the tags written by this file:
+// p, br, strong, em, code, pre, blockquote, ul, ol, li, a, img.
+
+const maxQuoteDepth = 3
+const maxInlineDepth = 8
+
+// host ที่อนุญาตให้แสดงรูปใน discussion ได้ (https เท่านั้น) ว่าง = ไม่แสดงรูปเลย
+var imageHosts []string
+
+// SetImageHosts configures the hosts that ![images](...) may be loaded from
+func SetImageHosts(hosts []string) {
+	imageHosts = nil
+	for _, host := range hosts {
+		host = strings.ToLower(strings.TrimSpace(host))
+		if host != "" {
+			imageHosts = append(imageHosts, host)
+		}
+	}
+}
+
+// Render converts markdown source to sanitized HTML
+func Render(src string) string {
+	src = strings.ToValidUTF8(src, "�")
+	src = strings.ReplaceAll(src, "\x00", "�")
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	src = strings.ReplaceAll(src, "\r", "\n")
+
+	var b strings.Builder
+	renderBlocks(&b, strings.Split(src, "\n"), 0)
+	return b.String()
+}
+
+// ===================== Blocks =====================
+
+func renderBlocks(b *strings.Builder, lines []string, depth int) {
+	for i := 0; i < len(lines); {
+		trimmed := strings.TrimSpace(lines[i])
+
+		switch {
+		case trimmed == "":
+			i++
+
+		case isFence(trimmed):
+			end := i + 1
+			for end < len(lines) && !isFence(strings.TrimSpace(lines[end])) {
+				end++
+			}
+			b.WriteString("<pre><code>")
+			b.WriteString(html.EscapeString(strings.Join(lines[i+1:min(end, len(lines))], "\n")))
+			b.WriteString("</code></pre>")
+			i = end + 1
+
+		case isQuote(trimmed) && depth < maxQuoteDepth:
+			var inner []string
+			for ; i < len(lines) && isQuote(strings.TrimSpace(lines[i])); i++ {
+				line := strings.TrimPrefix(strings.TrimSpace(lines[i]), ">")
+				inner = append(inner, strings.TrimPrefix(line, " "))
+			}
+			b.WriteString("<blockquote>")
+			renderBlocks(b, inner, depth+1)
+			b.WriteString("</blockquote>")
+
+		case listItem(trimmed) != "":
+			kind := listItem(trimmed)
+			b.WriteString("<" + kind + ">")
+			for ; i < len(lines) && listItem(strings.TrimSpace(lines[i])) == kind; i++ {
+				b.WriteString("<li>")
+				renderInline(b, listItemText(strings.TrimSpace(lines[i])), false, 0)
+				b.WriteString("</li>")
+			}
+			b.WriteString("</" + kind + ">")
+
+		default:
+			// paragraph ต่อไปจนเจอบรรทัดว่างหรือบรรทัดที่เริ่ม block ใหม่
+			start := i
+			for i++; i < len(lines); i++ {
+				t := strings.TrimSpace(lines[i])
+				if t == "" || isFence(t) || isQuote(t) || listItem(t) != "" {
+					break
+				}
+			}
+			paragraph := make([]string, i-start)
+			for j, line := range lines[start:i] {
+				paragraph[j] = strings.TrimSpace(line)
+			}
+			b.WriteString("<p>")
+			renderInline(b, strings.Join(paragraph, "\n"), false, 0)
+			b.WriteString("</p>")
+		}
+	}
+}
+
+func isFence(line string) bool {
+	return strings.HasPrefix(line, "```")
+}
+
+func isQuote(line string) bool {
+	return strings.HasPrefix(line, ">")
+}
+
+// listItem returns "ul" or "ol" when line starts a list item, otherwise ""
+func listItem(line string) string {
+	if len(line) >= 2 && strings.ContainsRune("-*+", rune(line[0])) && line[1] == ' ' {
+		return "ul"
+	}
+
+	digits := 0
+	for digits < len(line) && digits < 9 && line[digits] >= '0' && line[digits] <= '9' {
+		digits++
+	}
+	if digits > 0 && len(line) > digits+1 && (line[digits] == '.' || line[digits] == ')') && line[digits+1] == ' ' {
+		return "ol"
+	}
+
+	return ""
+}
+
+func listItemText(line string) string {
+	_, text, _ := strings.Cut(line, " ")
+	return strings.TrimSpace(text)
+}
+
+// ===================== Inline =====================
+
+// renderInline writes the inline markup of s. Inside link text noLinks stops
+// links from nesting.
+func renderInline(b *strings.Builder, s string, noLinks bool, depth int) {
+	if depth > maxInlineDepth {
+		b.WriteString(html.EscapeString(s))
+		return
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+
+		switch {
+		case c == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]):
+			b.WriteString(html.EscapeString(s[i+1 : i+2]))
+			i += 2
+			continue
+
+		case c == '\n':
+			b.WriteString("<br>")
+			i++
+			continue
+
+		case c == '`':
+			if end, ok := renderCodeSpan(b, s, i); ok {
+				i = end
+				continue
+			}
+
+		case c == '*' || c == '_':
+			if end, ok := renderEmphasis(b, s, i, noLinks, depth); ok {
+				i = end
+				continue
+			}
+
+		case c == '!' && !noLinks && i+1 < len(s) && s[i+1] == '[':
+			if text, dest, end, ok := parseLink(s, i+1); ok {
+				renderImage(b, text, dest, depth)
+				i = end
+				continue
+			}
+
+		case c == '[' && !noLinks:
+			if text, dest, end, ok := parseLink(s, i); ok {
+				renderLink(b, text, dest, depth)
+				i = end
+				continue
+			}
+
+		case c == 'h' && !noLinks && (i == 0 || !isAlnum(s[i-1])):
+			if end, ok := renderAutolink(b, s, i); ok {
+				i = end
+				continue
+			}
+		}
+
+		// ตัวอักษรธรรมดา (byte ของ UTF-8 หลายไบต์ถูกเขียนต่อกันตามเดิม)
+		b.WriteString(html.EscapeString(s[i : i+1]))
+		i++
+	}
+}
+
+// renderCodeSpan renders `code` starting at the backtick run at i
+func renderCodeSpan(b *strings.Builder, s string, i int) (int, bool) {
+	n := 0
+	for i+n < len(s) && s[i+n] == '`' {
+		n++
+	}
+	delim := strings.Repeat("`", n)
+
+	end := strings.Index(s[i+n:], delim)
+	if end < 0 {
+		b.WriteString(delim)
+		return i + n, true
+	}
+
+	code := s[i+n : i+n+end]
+	b.WriteString("<code>")
+	b.WriteString(html.EscapeString(strings.ReplaceAll(code, "\n", " ")))
+	b.WriteString("</code>")
+	return i + n + end + n, true
+}
+
+// renderEmphasis renders **strong** / __strong__ or *em* / _em_ starting at i
+func renderEmphasis(b *strings.Builder, s string, i int, noLinks bool, depth int) (int, bool) {
+	c := s[i]
+	// _ กลางคำ (เช่น snake_case) ไม่ใช่ emphasis
+	if c == '_' && i > 0 && isAlnum(s[i-1]) {
+		return 0, false
+	}
+
+	n := 1
+	if i+1 < len(s) && s[i+1] == c {
+		n = 2
+	}
+	delim := strings.Repeat(string(c), n)
+
+	end := findClosing(s, i+n, c, n)
+	if end < 0 {
+		return 0, false
+	}
+
+	tag := "em"
+	if n == 2 {
+		tag = "strong"
+	}
+	b.WriteString("<" + tag + ">")
+	renderInline(b, s[i+n:end], noLinks, depth+1)
+	b.WriteString("</" + tag + ">")
+	return end + len(delim), true
+}
+
+// findClosing finds the closing delimiter of n c's for an emphasis opened before from
+func findClosing(s string, from int, c byte, n int) int {
+	if from >= len(s) || isSpace(s[from]) {
+		return -1
+	}
+
+	for j := from + 1; j+n <= len(s); j++ {
+		if s[j-1] == '\\' {
+			continue
+		}
+		if strings.Repeat(string(c), n) != s[j:j+n] || isSpace(s[j-1]) {
+			continue
+		}
+		// ตัวปิดของ *em* ต้องไม่ใช่ส่วนหนึ่งของ ** และ _ ต้องไม่อยู่กลางคำ
+		if n == 1 && (s[j-1] == c || (j+1 < len(s) && s[j+1] == c)) {
+			continue
+		}
+		if c == '_' && j+n < len(s) && isAlnum(s[j+n]) {
+			continue
+		}
+		return j
+	}
+
+	return -1
+}
+
+// parseLink parses [text](dest) starting at the [ at i
+func parseLink(s string, i int) (string, string, int, bool) {
+	depth := 0
+	closeText := -1
+	for j := i; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '[':
+			depth++
+		case ']':
+			depth--
+		}
+		if depth == 0 {
+			closeText = j
+			break
+		}
+	}
+	if closeText < 0 || closeText+1 >= len(s) || s[closeText+1] != '(' {
+		return "", "", 0, false
+	}
+
+	depth = 0
+	for j := closeText + 1; j < len(s); j++ {
+		switch s[j] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+		if depth == 0 {
+			// [text](dest "title") ไม่ใช้ title
+			dest, title, _ := strings.Cut(strings.TrimSpace(s[closeText+2:j]), " ")
+			title = strings.TrimSpace(title)
+			if title != "" && (len(title) < 2 || title[0] != '"' || title[len(title)-1] != '"') {
+				return "", "", 0, false
+			}
+			if strings.ContainsFunc(dest, unicode.IsSpace) {
+				return "", "", 0, false
+			}
+			return s[i+1 : closeText], dest, j + 1, true
+		}
+	}
+
+	return "", "", 0, false
+}
+
+func renderLink(b *strings.Builder, text, dest string, depth int) {
+	href, ok := safeURL(dest)
+	if !ok {
+		// URL ที่ไม่ปลอดภัยถูกทิ้ง เหลือแต่ข้อความ
+		renderInline(b, text, true, depth+1)
+		return
+	}
+
+	b.WriteString(`<a href="` + html.EscapeString(href) + `" rel="nofollow ugc">`)
+	renderInline(b, text, true, depth+1)
+	b.WriteString("</a>")
+}
+
+func renderImage(b *strings.Builder, alt, dest string, depth int) {
+	src, ok := safeURL(dest)
+	if ok && imageAllowed(src) {
+		b.WriteString(`<img src="` + html.EscapeString(src) + `" alt="` + html.EscapeString(alt) + `" loading="lazy">`)
+		return
+	}
+
+	// รูปจาก host อื่นแสดงเป็น link แทน
+	renderLink(b, alt, dest, depth)
+}
+
+// renderAutolink links a bare http(s) URL starting at i
+func renderAutolink(b *strings.Builder, s string, i int) (int, bool) {
+	if !strings.HasPrefix(s[i:], "http://") && !strings.HasPrefix(s[i:], "https://") {
+		return 0, false
+	}
+
+	end := i
+	for end < len(s) && !isSpace(s[end]) && strings.IndexByte(`<>"`, s[end]) < 0 {
+		end++
+	}
+	// ตัดเครื่องหมายวรรคตอนท้าย URL และ ) ที่ไม่มีคู่
+	for end > i {
+		last := s[end-1]
+		if strings.IndexByte(".,:;!?'\"*_", last) >= 0 ||
+			(last == ')' && strings.Count(s[i:end], "(") < strings.Count(s[i:end], ")")) {
+			end--
+			continue
+		}
+		break
+	}
+
+	href, ok := safeURL(s[i:end])
+	if !ok {
+		return 0, false
+	}
+
+	b.WriteString(`<a href="` + html.EscapeString(href) + `" rel="nofollow ugc">`)
+	b.WriteString(html.EscapeString(s[i:end]))
+	b.WriteString("</a>")
+	return end, true
+}
+
+// safeURL accepts absolute http(s) and mailto URLs only
+func safeURL(raw string) (string, bool) {
+	if raw == "" || strings.ContainsFunc(raw, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsControl(r)
+	}) {
+		return "", false
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", false
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		if u.Host == "" {
+			return "", false
+		}
+	case "mailto":
+		if u.Opaque == "" {
+			return "", false
+		}
+	default:
+		return "", false
+	}
+
+	return u.String(), true
+}
+
+func imageAllowed(src string) bool {
+	u, err := url.Parse(src)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Scheme, "https") && slices.Contains(imageHosts, strings.ToLower(u.Hostname()))
+}
+
+func isASCIIPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
+
+func isAlnum(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n'
+}
@@ -0,0 +1,213 @@
+package markdown
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"plain", "Hello cats", "<p>Hello cats</p>"},
+		{"line break", "line one\nline two", "<p>line one<br>line two</p>"},
+		{"paragraphs", "one\n\ntwo", "<p>one</p><p>two</p>"},
+		{"bold", "a **bold** cat", "<p>a <strong>bold</strong> cat</p>"},
+		{"italic", "an *italic* and _other_ cat", "<p>an <em>italic</em> and <em>other</em> cat</p>"},
+		{"nested emphasis", "*a **b** c*", "<p><em>a <strong>b</strong> c</em></p>"},
+		{"snake case", "use cat_breed_id here", "<p>use cat_breed_id here</p>"},
+		{"unclosed emphasis", "2 * 3 = 6", "<p>2 * 3 = 6</p>"},
+		{"code span", "run `go test <pkg>`", "<p>run <code>go test &lt;pkg&gt;</code></p>"},
+		{"escape", `\*not italic\*`, "<p>*not italic*</p>"},
+		{"html is text", "<b>hi</b> & bye", "<p>&lt;b&gt;hi&lt;/b&gt; &amp; bye</p>"},
+		{"link", "[Maine Coon](https://example.com/mc)", `<p><a href="https://example.com/mc" rel="nofollow ugc">Maine Coon</a></p>`},
+		{"link with parens", "[wiki](https://en.wikipedia.org/wiki/Cat_(disambiguation))", `<p><a href="https://en.wikipedia.org/wiki/Cat_(disambiguation)" rel="nofollow ugc">wiki</a></p>`},
+		{"autolink", "see https://example.com/cats.", `<p>see <a href="https://example.com/cats" rel="nofollow ugc">https://example.com/cats</a>.</p>`},
+		{"link title", `[cat](https://example.com "Cats")`, `<p><a href="https://example.com" rel="nofollow ugc">cat</a></p>`},
+		{"mailto", "[mail](mailto:vet@example.com)", `<p><a href="mailto:vet@example.com" rel="nofollow ugc">mail</a></p>`},
+		{"unordered list", "- one\n- **two**", "<ul><li>one</li><li><strong>two</strong></li></ul>"},
+		{"ordered list", "1. one\n2. two", "<ol><li>one</li><li>two</li></ol>"},
+		{"quote", "> quoted\n> text\n\nreply", "<blockquote><p>quoted<br>text</p></blockquote><p>reply</p>"},
+		{"fenced code", "```\n<cat> & *dog*\n```", "<pre><code>&lt;cat&gt; &amp; *dog*</code></pre>"},
+		{"thai", "แมว **เปอร์เซีย** น่ารัก", "<p>แมว <strong>เปอร์เซีย</strong> น่ารัก</p>"},
+		{"image from other host", "![cat](https://evil.example/cat.png)", `<p><a href="https://evil.example/cat.png" rel="nofollow ugc">cat</a></p>`},
+	}
+
+	for _, tt := range tests {
+		if got := Render(tt.src); got != tt.want {
+			t.Errorf("%s: Render(%q)\n got %s\nwant %s", tt.name, tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestRenderImageHosts(t *testing.T) {
+	SetImageHosts([]string{" Images.Example.com "})
+	t.Cleanup(func() { SetImageHosts(nil) })
+
+	got := Render(`![a "cat"](https://images.example.com/cat.png)`)
+	want := `<p><img src="https://images.example.com/cat.png" alt="a &#34;cat&#34;" loading="lazy"></p>`
+	if got != want {
+		t.Errorf("got %s\nwant %s", got, want)
+	}
+
+	// http และ host อื่นไม่แสดงเป็นรูป
+	for _, src := range []string{
+		"![cat](http://images.example.com/cat.png)",
+		"![cat](https://images.example.com.evil.example/cat.png)",
+		"![cat](https://evil.example/?images.example.com)",
+	} {
+		if got := Render(src); strings.Contains(got, "<img") {
+			t.Errorf("Render(%q) = %s, want no image", src, got)
+		}
+	}
+}
+
+// ===================== XSS Corpus =====================
+
+var xssCorpus = []string{
+	`<script>alert(1)</script>`,
+	`<SCRIPT SRC=//evil.example/xss.js></SCRIPT>`,
+	`<img src=x onerror=alert(1)>`,
+	`<svg/onload=alert(1)>`,
+	`<iframe src="javascript:alert(1)"></iframe>`,
+	`<a href="javascript:alert(1)">x</a>`,
+	`<div style="background:url(javascript:alert(1))">`,
+	`<!-- <script>alert(1)</script> -->`,
+	`<math><mi xlink:href="data:x,<script>alert(1)</script>">`,
+	`[x](javascript:alert(1))`,
+	`[x](JaVaScRiPt:alert(1))`,
+	`[x](javascript&#58;alert(1))`,
+	`[x](&#106;avascript:alert(1))`,
+	`[x](java%0ascript:alert(1))`,
+	"[x](java\tscript:alert(1))",
+	"[x](java\x00script:alert(1))",
+	`[x](vbscript:msgbox(1))`,
+	`[x](data:text/html;base64,PHNjcmlwdD5hbGVydCgxKTwvc2NyaXB0Pg==)`,
+	`[x](//evil.example/a)`,
+	`[x](/relative/path)`,
+	`[x]( javascript:alert(1) )`,
+	`[x](https://example.com/"onmouseover="alert(1))`,
+	`[x](https://example.com/'onmouseover='alert(1))`,
+	`[x](https://example.com/><script>alert(1)</script>)`,
+	`[<img src=x onerror=alert(1)>](https://example.com)`,
+	`[[x](javascript:alert(1))](https://example.com)`,
+	`[x](https://example.com "title onmouseover=alert(1)")`,
+	`![x](javascript:alert(1))`,
+	`![x" onerror="alert(1)](https://images.example.com/a.png)`,
+	`![x](https://images.example.com/a.png"onerror="alert(1))`,
+	`![x](data:image/svg+xml;base64,PHN2ZyBvbmxvYWQ9YWxlcnQoMSk+)`,
+	`https://example.com/"><script>alert(1)</script>`,
+	`https://example.com/'onmouseover='alert(1)'`,
+	`javascript:alert(1)`,
+	"`<script>alert(1)</script>`",
+	"```\n</code></pre><script>alert(1)</script>\n```",
+	"```html\n<script>alert(1)</script>",
+	`**<script>alert(1)</script>**`,
+	`*<img src=x onerror=alert(1)>*`,
+	`> <script>alert(1)</script>`,
+	"- <script>alert(1)</script>\n1. <img src=x onerror=alert(1)>",
+	`\<script\>alert(1)\</script\>`,
+	`&lt;script&gt;alert(1)&lt;/script&gt;`,
+	`&#60;script&#62;alert(1)&#60;/script&#62;`,
+	`<<script>script>alert(1)<</script>/script>`,
+	`<scr<script>ipt>alert(1)</scr</script>ipt>`,
+	`<a href="https://example.com" onclick="alert(1)">x</a>`,
+	`<form action="https://evil.example"><input type=submit></form>`,
+	`<object data="javascript:alert(1)">`,
+	`<embed src="javascript:alert(1)">`,
+	`<style>body{background:url("javascript:alert(1)")}</style>`,
+	`<meta http-equiv="refresh" content="0;url=javascript:alert(1)">`,
+	`<base href="javascript:alert(1)//">`,
+	"‮<script>alert(1)</script>",
+	"\xff\xfe<script>alert(1)</script>",
+	strings.Repeat("*", 500) + "<script>" + strings.Repeat("*", 500),
+	strings.Repeat("[", 300) + "x" + strings.Repeat("](https://example.com)", 300),
+	strings.Repeat("> ", 50) + "<script>alert(1)</script>",
+}
+
+// allowedAttrs is the only markup Render may produce
+var allowedAttrs = map[string][]string{
+	"p": nil, "br": nil, "strong": nil, "em": nil, "code": nil, "pre": nil,
+	"blockquote": nil, "ul": nil, "ol": nil, "li": nil,
+	"a":   {"href", "rel"},
+	"img": {"src", "alt", "loading"},
+}
+
+func TestRenderXSSCorpus(t *testing.T) {
+	SetImageHosts([]string{"images.example.com"})
+	t.Cleanup(func() { SetImageHosts(nil) })
+
+	for _, payload := range xssCorpus {
+		out := Render(payload)
+		checkSanitized(t, payload, out)
+	}
+}
+
+func checkSanitized(t *testing.T, payload, out string) {
+	t.Helper()
+
+	z := html.NewTokenizer(strings.NewReader(out))
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			return
+
+		case html.CommentToken, html.DoctypeToken:
+			t.Errorf("payload %q: unexpected %v in %s", payload, tt, out)
+
+		case html.StartTagToken, html.SelfClosingTagToken, html.EndTagToken:
+			token := z.Token()
+			attrs, ok := allowedAttrs[token.Data]
+			if !ok {
+				t.Errorf("payload %q: tag <%s> not allowed in %s", payload, token.Data, out)
+				continue
+			}
+
+			for _, attr := range token.Attr {
+				if !slices.Contains(attrs, attr.Key) {
+					t.Errorf("payload %q: attribute %s on <%s> not allowed in %s", payload, attr.Key, token.Data, out)
+					continue
+				}
+				switch attr.Key {
+				case "href", "src":
+					checkURL(t, payload, token.Data, attr.Val)
+				case "rel":
+					if attr.Val != "nofollow ugc" {
+						t.Errorf("payload %q: rel=%q", payload, attr.Val)
+					}
+				}
+			}
+
+			if token.Data == "a" && tt == html.StartTagToken {
+				if !slices.ContainsFunc(token.Attr, func(a html.Attribute) bool { return a.Key == "rel" }) {
+					t.Errorf("payload %q: link without rel in %s", payload, out)
+				}
+			}
+		}
+	}
+}
+
+func checkURL(t *testing.T, payload, tag, raw string) {
+	t.Helper()
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Errorf("payload %q: invalid URL %q on <%s>", payload, raw, tag)
+		return
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	switch {
+	case tag == "img" && (scheme != "https" || u.Hostname() != "images.example.com"):
+		t.Errorf("payload %q: image from %q", payload, raw)
+	case scheme != "http" && scheme != "https" && scheme != "mailto":
+		t.Errorf("payload %q: URL scheme %q in %q", payload, scheme, raw)
+	}
+}
@@ -9,28 +9,36 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// requestToken gets the access token from the cookie or the Authorization header.
+// It returns an error message for the client when there is no usable token.
+func requestToken(c *gin.Context) (string, string) {
+	// Try to get token from cookie first
+	if tokenString, err := c.Cookie("access_token"); err == nil {
+		return tokenString, ""
+	}
+
+	// If not in cookie, try Authorization header
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		return "", "missing authorization token"
+	}
+
+	// Extract token from "Bearer <token>"
+	parts := strings.Split(authHeader, " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return "", "invalid authorization format"
+	}
+	return parts[1], ""
+}
+
 // AuthMiddleware verifies JWT token
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Try to get token from cookie first
-		tokenString, err := c.Cookie("access_token")
-		if err != nil {
-			// If not in cookie, try Authorization header
-			authHeader := c.GetHeader("Authorization")
-			if authHeader == "" {
-				c.JSON(http.StatusUnauthorized, gin.H{"error": "missing authorization token"})
-				c.Abort()
-				return
-			}
-
-			// Extract token from "Bearer <token>"
-			parts := strings.Split(authHeader, " ")
-			if len(parts) != 2 || parts[0] != "Bearer" {
-				c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid authorization format"})
-				c.Abort()
-				return
-			}
-			tokenString = parts[1]
+		tokenString, problem := requestToken(c)
+		if problem != "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": problem})
+			c.Abort()
+			return
 		}
 
 		// Verify token
@@ -50,6 +58,23 @@ func AuthMiddleware() gin.HandlerFunc {
 	}
 }
 
+// OptionalAuthMiddleware sets the user info like AuthMiddleware when a valid token is
+// sent, and lets anonymous requests (or invalid tokens) through as guests
+func OptionalAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString, problem := requestToken(c)
+		if problem == "" {
+			if claims, err := infoDB.VerifyToken(tokenString); err == nil {
+				c.Set("user_id", claims.UserID)
+				c.Set("username", claims.Username)
+				c.Set("roles", claims.Roles)
+			}
+		}
+
+		c.Next()
+	}
+}
+
 // RequirePermission checks if user has specific permission
 func RequirePermission(permission string) gin.HandlerFunc {
 	return func(c *gin.Context) {
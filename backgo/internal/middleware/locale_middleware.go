@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"backgo/internal/infoDB"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+)
+
+// LocaleMiddleware picks the response locale from ?lang= or the Accept-Language header,
+// falling back to the default locale, and stores it in the context as "locale"
+func LocaleMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("locale", negotiateLocale(c.Query("lang"), c.GetHeader("Accept-Language")))
+		c.Writer.Header().Add("Vary", "Accept-Language")
+		c.Next()
+	}
+}
+
+func negotiateLocale(lang, acceptLanguage string) string {
+	supported := infoDB.SupportedLocales()
+
+	tags := make([]language.Tag, 0, len(supported))
+	for _, locale := range supported {
+		tags = append(tags, language.Make(locale))
+	}
+	matcher := language.NewMatcher(tags)
+
+	// ?lang= มีความสำคัญกว่า Accept-Language
+	var desired []language.Tag
+	if lang != "" {
+		if tag, err := language.Parse(lang); err == nil {
+			desired = []language.Tag{tag}
+		}
+	}
+	if desired == nil && acceptLanguage != "" {
+		desired, _, _ = language.ParseAcceptLanguage(acceptLanguage)
+	}
+	if len(desired) == 0 {
+		return infoDB.DefaultLocale()
+	}
+
+	_, index, confidence := matcher.Match(desired...)
+	if confidence == language.No {
+		return infoDB.DefaultLocale()
+	}
+	return supported[index]
+}
@@ -76,13 +76,33 @@ CREATE INDEX idx_audit_logs_created_at ON audit_logs(created_at);
 
 -- ===================== CAT BREEDS (Admin manages) =====================
 
+CREATE TYPE coat_length_enum AS ENUM ('hairless', 'short', 'medium', 'long');
+
 CREATE TABLE cat_breeds (
     id SERIAL PRIMARY KEY,
     name VARCHAR(255) NOT NULL,
+    -- ใช้ใน URL เช่น /api/cats/scottish-fold
+    slug VARCHAR(255) NOT NULL UNIQUE,
     origin VARCHAR(255),
     description TEXT,
     care_instructions TEXT,
     image_url TEXT,
+    -- รูป cover ของแกลเลอรี (FK เพิ่มหลังสร้างตาราง breed_images)
+    image_id INTEGER,
+    
+    -- Structured attributes (NULL = ไม่ทราบข้อมูล)
+    weight_min_kg NUMERIC(4,1),
+    weight_max_kg NUMERIC(4,1),
+    lifespan_min_years SMALLINT,
+    lifespan_max_years SMALLINT,
+    coat_length coat_length_enum,
+    shedding_level SMALLINT,  -- 1-5
+    activity_level SMALLINT,  -- 1-5
+    grooming_level SMALLINT,  -- 1-5
+    hypoallergenic BOOLEAN NOT NULL DEFAULT FALSE,
+    child_friendly SMALLINT,  -- 1-5
+    pet_friendly SMALLINT,    -- 1-5
+    temperament TEXT[] NOT NULL DEFAULT '{}',
     
     -- Engagement metrics 
     like_count INTEGER DEFAULT 0,
@@ -94,11 +114,25 @@ CREATE TABLE cat_breeds (
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
     
-    CONSTRAINT chk_name_not_empty CHECK (char_length(name) > 0)
+    -- Soft delete (ถังขยะ) ข้อมูลจะถูกลบจริงเมื่อพ้นระยะเวลาเก็บรักษา
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    deleted_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    
+    CONSTRAINT chk_name_not_empty CHECK (char_length(name) > 0),
+    CONSTRAINT chk_weight_range CHECK (weight_min_kg <= weight_max_kg),
+    CONSTRAINT chk_lifespan_range CHECK (lifespan_min_years <= lifespan_max_years),
+    CONSTRAINT chk_shedding_level CHECK (shedding_level BETWEEN 1 AND 5),
+    CONSTRAINT chk_activity_level CHECK (activity_level BETWEEN 1 AND 5),
+    CONSTRAINT chk_grooming_level CHECK (grooming_level BETWEEN 1 AND 5),
+    CONSTRAINT chk_child_friendly CHECK (child_friendly BETWEEN 1 AND 5),
+    CONSTRAINT chk_pet_friendly CHECK (pet_friendly BETWEEN 1 AND 5)
 );
 
 CREATE INDEX idx_cat_breeds_name ON cat_breeds(name);
 CREATE INDEX idx_cat_breeds_created_at ON cat_breeds(created_at);
+CREATE INDEX idx_cat_breeds_deleted_at ON cat_breeds(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_cat_breeds_coat_length ON cat_breeds(coat_length);
+CREATE INDEX idx_cat_breeds_temperament ON cat_breeds USING GIN (temperament);
 
 -- ===================== BREED REACTIONS (Like/Dislike) =====================
 
@@ -121,30 +155,94 @@ CREATE INDEX idx_breed_reactions_user_id ON breed_reactions(user_id);
 
 -- ===================== DISCUSSIONS (Comments) =====================
 
+-- Wilson score lower bound (95%) ใช้เรียง discussion แบบ best
+-- สูตรเดียวกับ WilsonLowerBound ใน ranking_infoDB.go
+CREATE OR REPLACE FUNCTION wilson_lower_bound(positive INTEGER, total INTEGER)
+RETURNS DOUBLE PRECISION AS $$
+    SELECT CASE WHEN total <= 0 THEN 0 ELSE
+        (p + 1.96 * 1.96 / (2 * n) - 1.96 * sqrt((p * (1 - p) + 1.96 * 1.96 / (4 * n)) / n))
+        / (1 + 1.96 * 1.96 / n)
+    END
+    FROM (SELECT positive::float8 / NULLIF(total, 0) AS p, total::float8 AS n) v
+$$ LANGUAGE SQL IMMUTABLE;
+
 CREATE TABLE discussions (
     id SERIAL PRIMARY KEY,
     breed_id INTEGER NOT NULL REFERENCES cat_breeds(id) ON DELETE CASCADE,
     user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
     message TEXT NOT NULL,
     parent_id INTEGER REFERENCES discussions(id) ON DELETE CASCADE, -- สำหรับ reply
+    depth INTEGER NOT NULL DEFAULT 0, -- 0 = top-level, reply = depth ของ parent + 1
     
     -- Engagement
     like_count INTEGER DEFAULT 0,
     dislike_count INTEGER DEFAULT 0,
     reply_count INTEGER DEFAULT 0,
+
+    -- คะแนนสำหรับ ?sort= คำนวณจาก like/dislike อัตโนมัติ
+    net_score INTEGER GENERATED ALWAYS AS (like_count - dislike_count) STORED,
+    controversy_score DOUBLE PRECISION GENERATED ALWAYS AS (
+        CASE WHEN like_count > 0 AND dislike_count > 0
+            THEN power((like_count + dislike_count)::float8,
+                       LEAST(like_count, dislike_count)::float8 / GREATEST(like_count, dislike_count))
+            ELSE 0
+        END
+    ) STORED,
+    best_score DOUBLE PRECISION GENERATED ALWAYS AS (
+        wilson_lower_bound(like_count, like_count + dislike_count)
+    ) STORED,
     
     is_deleted BOOLEAN DEFAULT FALSE,
+    edited_at TIMESTAMP WITH TIME ZONE, -- แก้ไขล่าสุดที่ถูกบันทึกใน discussion_edits (นอก grace window)
+    edit_count INTEGER NOT NULL DEFAULT 0,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     
-    CONSTRAINT chk_message_not_empty CHECK (char_length(message) > 0)
+    CONSTRAINT chk_message_not_empty CHECK (char_length(message) > 0),
+    CONSTRAINT chk_discussion_depth CHECK ((parent_id IS NULL) = (depth = 0))
 );
 
 CREATE INDEX idx_discussions_breed_id ON discussions(breed_id);
 CREATE INDEX idx_discussions_user_id ON discussions(user_id);
-CREATE INDEX idx_discussions_parent_id ON discussions(parent_id);
+CREATE INDEX idx_discussions_parent_id ON discussions(parent_id, created_at);
 CREATE INDEX idx_discussions_created_at ON discussions(created_at);
 
+-- ใช้กับ keyset pagination ของ comment ระดับบนสุดในแต่ละ sort
+CREATE INDEX idx_discussions_top_level_created ON discussions(breed_id, created_at, id) WHERE parent_id IS NULL;
+CREATE INDEX idx_discussions_top_level_net ON discussions(breed_id, net_score DESC, id DESC) WHERE parent_id IS NULL;
+CREATE INDEX idx_discussions_top_level_controversy ON discussions(breed_id, controversy_score DESC, id DESC) WHERE parent_id IS NULL;
+CREATE INDEX idx_discussions_top_level_best ON discussions(breed_id, best_score DESC, id DESC) WHERE parent_id IS NULL;
+
+-- ===================== DISCUSSION EDIT HISTORY =====================
+
+-- เก็บข้อความก่อนแก้ไขทุกครั้ง (ยกเว้นการแก้ภายใน grace window หลังโพสต์)
+CREATE TABLE discussion_edits (
+    id SERIAL PRIMARY KEY,
+    discussion_id INTEGER NOT NULL REFERENCES discussions(id) ON DELETE CASCADE,
+    message TEXT NOT NULL, -- ข้อความก่อนถูกแก้
+    edited_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP -- เวลาที่ข้อความนี้ถูกแทนที่
+);
+
+CREATE INDEX idx_discussion_edits_discussion ON discussion_edits(discussion_id, created_at DESC);
+
+-- ===================== DISCUSSION MENTIONS =====================
+
+-- @username ใน discussion (start_offset/length นับเป็นตัวอักษร (code point) ของ message)
+CREATE TABLE discussion_mentions (
+    discussion_id INTEGER NOT NULL REFERENCES discussions(id) ON DELETE CASCADE,
+    mentioned_user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    start_offset INTEGER NOT NULL,
+    length INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (discussion_id, start_offset)
+);
+
+CREATE INDEX idx_discussion_mentions_user ON discussion_mentions(mentioned_user_id, created_at DESC);
+
+-- resolve @username แบบไม่สนตัวพิมพ์เล็ก/ใหญ่
+CREATE INDEX idx_users_username_lower ON users(lower(username));
+
 -- ===================== DISCUSSION REACTIONS (Like/Dislike Comments) =====================
 
 CREATE TABLE discussion_reactions (
@@ -161,6 +259,326 @@ CREATE TABLE discussion_reactions (
 CREATE INDEX idx_discussion_reactions_discussion_id ON discussion_reactions(discussion_id);
 CREATE INDEX idx_discussion_reactions_user_id ON discussion_reactions(user_id);
 
+-- ===================== NOTIFICATIONS =====================
+
+CREATE TYPE notification_type_enum AS ENUM ('reply', 'mention', 'like');
+
+-- เหตุการณ์ประเภทเดียวกันบน comment เดียวกันรวมเป็นแจ้งเตือนเดียว ("5 people liked your comment")
+-- จนกว่าผู้รับจะอ่าน เหตุการณ์หลังจากนั้นเริ่มแจ้งเตือนใหม่
+CREATE TABLE notifications (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type notification_type_enum NOT NULL,
+    -- reply/like: comment ของผู้รับ, mention: comment ที่ mention ผู้รับ
+    discussion_id INTEGER NOT NULL REFERENCES discussions(id) ON DELETE CASCADE,
+    actor_count INTEGER NOT NULL DEFAULT 0,
+    read_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    -- เวลาของเหตุการณ์ล่าสุดในกลุ่ม
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_notifications_unread_group ON notifications(user_id, type, discussion_id) WHERE read_at IS NULL;
+CREATE INDEX idx_notifications_user ON notifications(user_id, updated_at DESC);
+
+CREATE TABLE notification_actors (
+    notification_id INTEGER NOT NULL REFERENCES notifications(id) ON DELETE CASCADE,
+    actor_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- reply ล่าสุดของ actor (type reply)
+    source_discussion_id INTEGER REFERENCES discussions(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (notification_id, actor_id)
+);
+
+CREATE INDEX idx_notification_actors_actor ON notification_actors(actor_id);
+
+-- ไม่มีแถว = เปิดรับแจ้งเตือนประเภทนั้น
+CREATE TABLE notification_preferences (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type notification_type_enum NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, type)
+);
+
+-- ===================== BREED REVISIONS (History) =====================
+
+CREATE TYPE revision_action_enum AS ENUM ('create', 'update', 'rollback');
+
+CREATE TABLE breed_revisions (
+    id SERIAL PRIMARY KEY,
+    breed_id INTEGER NOT NULL REFERENCES cat_breeds(id) ON DELETE CASCADE,
+    revision_number INTEGER NOT NULL,
+    action revision_action_enum NOT NULL,
+    -- revision ที่ถูก rollback กลับไป (เฉพาะ action = 'rollback')
+    rolled_back_from INTEGER,
+
+    -- snapshot ของข้อมูลหลังการเปลี่ยนแปลง
+    name VARCHAR(255) NOT NULL,
+    origin VARCHAR(255),
+    description TEXT,
+    care_instructions TEXT,
+    image_url TEXT,
+    attributes JSONB,
+
+    changed_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT unique_breed_revision UNIQUE (breed_id, revision_number)
+);
+
+CREATE INDEX idx_breed_revisions_breed_id ON breed_revisions(breed_id);
+
+-- ===================== BREED SLUG HISTORY =====================
+
+-- slug เก่าของ breed ที่ถูกเปลี่ยนชื่อ ใช้ redirect ไปยัง slug ปัจจุบัน
+CREATE TABLE breed_slug_history (
+    slug VARCHAR(255) PRIMARY KEY,
+    breed_id INTEGER NOT NULL REFERENCES cat_breeds(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_breed_slug_history_breed_id ON breed_slug_history(breed_id);
+
+-- ===================== BREED IMAGES (Gallery) =====================
+
+CREATE TABLE breed_images (
+    id SERIAL PRIMARY KEY,
+    breed_id INTEGER NOT NULL REFERENCES cat_breeds(id) ON DELETE CASCADE,
+    -- content type ที่ตรวจจากตัวไฟล์จริง (image/jpeg, image/png, image/webp)
+    source_type VARCHAR(50) NOT NULL,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    blurhash VARCHAR(100),
+    -- {"thumb": {"key": "...", "width": 160, "height": 160, "content_type": "image/jpeg"}, ...}
+    variants JSONB NOT NULL,
+
+    caption VARCHAR(500) NOT NULL DEFAULT '',
+    alt_text VARCHAR(300) NOT NULL DEFAULT '',
+    license VARCHAR(100) NOT NULL DEFAULT '',
+    attribution VARCHAR(300) NOT NULL DEFAULT '',
+    sort_order INTEGER NOT NULL DEFAULT 0,
+
+    uploaded_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_breed_images_breed_id ON breed_images(breed_id, sort_order);
+
+ALTER TABLE cat_breeds
+    ADD CONSTRAINT fk_cat_breeds_image FOREIGN KEY (image_id) REFERENCES breed_images(id) ON DELETE SET NULL;
+
+-- ===================== BREED TRANSLATIONS (i18n) =====================
+
+-- เนื้อหาใน cat_breeds คือภาษา default (th) ภาษาอื่นเก็บแยกตาม locale
+CREATE TABLE breed_translations (
+    breed_id INTEGER NOT NULL REFERENCES cat_breeds(id) ON DELETE CASCADE,
+    locale VARCHAR(10) NOT NULL,
+    name VARCHAR(255),
+    description TEXT,
+    care_instructions TEXT,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+
+    PRIMARY KEY (breed_id, locale)
+);
+
+CREATE INDEX idx_breed_translations_locale ON breed_translations(locale);
+
+-- ===================== BREED DAILY STATS (Analytics) =====================
+
+-- ยอด engagement รายวันของแต่ละ breed (วันตาม UTC)
+-- likes/dislikes/discussions เป็นค่าสุทธิของวันนั้น (ลบ reaction = -1) ผลรวมทุกวันจึงเท่ากับ counter ใน cat_breeds
+CREATE TABLE breed_daily_stats (
+    breed_id INTEGER NOT NULL REFERENCES cat_breeds(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    views INTEGER NOT NULL DEFAULT 0,
+    likes INTEGER NOT NULL DEFAULT 0,
+    dislikes INTEGER NOT NULL DEFAULT 0,
+    discussions INTEGER NOT NULL DEFAULT 0,
+
+    PRIMARY KEY (breed_id, day)
+);
+
+CREATE INDEX idx_breed_daily_stats_day ON breed_daily_stats(day);
+
+-- ===================== FAVORITES & COLLECTIONS =====================
+
+CREATE TABLE user_favorites (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    breed_id INTEGER NOT NULL REFERENCES cat_breeds(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (user_id, breed_id)
+);
+
+CREATE INDEX idx_user_favorites_breed_id ON user_favorites(breed_id);
+
+-- private = เจ้าของเท่านั้น, unlisted = ใครก็ได้ที่มี share link, public = แสดงในรายการสาธารณะ
+CREATE TYPE collection_visibility_enum AS ENUM ('private', 'unlisted', 'public');
+
+CREATE TABLE collections (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    visibility collection_visibility_enum NOT NULL DEFAULT 'private',
+    share_token VARCHAR(32) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_collections_user_name ON collections(user_id, lower(name));
+CREATE INDEX idx_collections_public ON collections(updated_at DESC) WHERE visibility = 'public';
+
+CREATE TABLE collection_items (
+    collection_id INTEGER NOT NULL REFERENCES collections(id) ON DELETE CASCADE,
+    breed_id INTEGER NOT NULL REFERENCES cat_breeds(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL DEFAULT 0,
+    note TEXT,
+    added_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (collection_id, breed_id)
+);
+
+CREATE INDEX idx_collection_items_position ON collection_items(collection_id, position);
+CREATE INDEX idx_collection_items_breed_id ON collection_items(breed_id);
+
+-- ===================== HEALTH CONDITIONS =====================
+
+CREATE TYPE condition_severity_enum AS ENUM ('mild', 'moderate', 'severe', 'life_threatening');
+CREATE TYPE condition_prevalence_enum AS ENUM ('rare', 'occasional', 'common', 'very_common');
+
+CREATE TABLE health_conditions (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    -- ชื่อย่อ เช่น PKD, HCM
+    abbreviation VARCHAR(20) NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    severity condition_severity_enum NOT NULL,
+    genetic BOOLEAN NOT NULL DEFAULT FALSE,
+    screening_tests TEXT[] NOT NULL DEFAULT '{}',
+    -- [{"title": "...", "url": "..."}]
+    reference_links JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_condition_name_not_empty CHECK (char_length(name) > 0)
+);
+
+CREATE UNIQUE INDEX idx_health_conditions_name ON health_conditions(lower(name));
+
+-- โรคที่พบในแต่ละสายพันธุ์ พร้อมระดับความชุก
+CREATE TABLE breed_health_conditions (
+    breed_id INTEGER NOT NULL REFERENCES cat_breeds(id) ON DELETE CASCADE,
+    condition_id INTEGER NOT NULL REFERENCES health_conditions(id) ON DELETE CASCADE,
+    prevalence condition_prevalence_enum NOT NULL,
+    notes TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (breed_id, condition_id)
+);
+
+CREATE INDEX idx_breed_health_conditions_condition ON breed_health_conditions(condition_id);
+
+-- ===================== BREED RELATIONSHIPS (Lineage) =====================
+
+CREATE TYPE breed_relationship_enum AS ENUM ('derived_from', 'variant_of', 'confused_with');
+
+-- breed_id derived_from / variant_of related_id (related_id คือสายพันธุ์ต้นทาง)
+-- confused_with เป็นความสัมพันธ์สองทาง เก็บแบบ breed_id < related_id
+CREATE TABLE breed_relationships (
+    id SERIAL PRIMARY KEY,
+    breed_id INTEGER NOT NULL REFERENCES cat_breeds(id) ON DELETE CASCADE,
+    related_id INTEGER NOT NULL REFERENCES cat_breeds(id) ON DELETE CASCADE,
+    type breed_relationship_enum NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (breed_id, related_id, type),
+    CHECK (breed_id <> related_id),
+    CHECK (type <> 'confused_with' OR breed_id < related_id)
+);
+
+CREATE INDEX idx_breed_relationships_related ON breed_relationships(related_id, type);
+
+-- ===================== BREED SUBMISSIONS (Community) =====================
+
+CREATE TYPE submission_kind_enum AS ENUM ('create', 'edit');
+CREATE TYPE submission_status_enum AS ENUM ('draft', 'pending', 'changes_requested', 'approved', 'rejected');
+
+-- ข้อเสนอเพิ่ม/แก้ไขสายพันธุ์จากผู้ใช้ admin ตรวจก่อนนำไปใช้จริง
+CREATE TABLE breed_submissions (
+    id SERIAL PRIMARY KEY,
+    kind submission_kind_enum NOT NULL,
+    -- สายพันธุ์ที่ขอแก้ (edit) หรือสายพันธุ์ที่สร้างขึ้นเมื่ออนุมัติ (create)
+    breed_id INTEGER REFERENCES cat_breeds(id) ON DELETE SET NULL,
+    submitted_by INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status submission_status_enum NOT NULL DEFAULT 'draft',
+
+    -- ข้อมูลที่เสนอ (รูปแบบเดียวกับ CreateCatRequest)
+    proposal JSONB NOT NULL,
+    -- แหล่งอ้างอิงหรือคำอธิบายจากผู้เสนอ
+    note TEXT NOT NULL DEFAULT '',
+
+    submitted_at TIMESTAMP WITH TIME ZONE,
+    reviewed_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_breed_submissions_user ON breed_submissions(submitted_by, created_at DESC);
+CREATE INDEX idx_breed_submissions_status ON breed_submissions(status, submitted_at);
+CREATE INDEX idx_breed_submissions_breed ON breed_submissions(breed_id) WHERE status = 'approved';
+
+-- ความเห็นของผู้ตรวจและผู้เสนอ status คือสถานะที่เปลี่ยนไปพร้อมความเห็นนั้น (ถ้ามี)
+CREATE TABLE submission_comments (
+    id SERIAL PRIMARY KEY,
+    submission_id INTEGER NOT NULL REFERENCES breed_submissions(id) ON DELETE CASCADE,
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    status submission_status_enum,
+    message TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_submission_comments_submission ON submission_comments(submission_id, created_at);
+
+-- ===================== RELATED BREEDS =====================
+
+-- เพื่อนบ้านที่คำนวณไว้ล่วงหน้าของแต่ละสายพันธุ์ (co-like + ความใกล้เคียงของคุณลักษณะ)
+CREATE TABLE breed_neighbors (
+    breed_id INTEGER NOT NULL REFERENCES cat_breeds(id) ON DELETE CASCADE,
+    neighbor_id INTEGER NOT NULL REFERENCES cat_breeds(id) ON DELETE CASCADE,
+    score DOUBLE PRECISION NOT NULL,
+    co_like_score DOUBLE PRECISION NOT NULL,
+    attribute_score DOUBLE PRECISION NOT NULL,
+    computed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (breed_id, neighbor_id),
+    CHECK (breed_id <> neighbor_id)
+);
+
+CREATE INDEX idx_breed_neighbors_score ON breed_neighbors(breed_id, score DESC);
+
+-- ===================== BREED MATCHER =====================
+
+-- น้ำหนักของแต่ละเกณฑ์ในแบบสอบถาม (admin ปรับได้)
+CREATE TABLE matcher_weights (
+    criterion VARCHAR(50) PRIMARY KEY,
+    weight NUMERIC(5,2) NOT NULL CHECK (weight >= 0 AND weight <= 10),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_by INTEGER REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- ผลการจับคู่ที่บันทึกไว้ของผู้ใช้ที่ login (snapshot ของคำตอบและอันดับ)
+CREATE TABLE matcher_results (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    answers JSONB NOT NULL,
+    matches JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_matcher_results_user_id ON matcher_results(user_id, created_at DESC);
+
 -- ===================== TRIGGERS =====================
 
 -- Auto-update updated_at timestamp
@@ -185,6 +603,18 @@ CREATE TRIGGER update_discussions_modtime
     BEFORE UPDATE ON discussions
     FOR EACH ROW EXECUTE FUNCTION update_modified_column();
 
+CREATE TRIGGER update_collections_modtime
+    BEFORE UPDATE ON collections
+    FOR EACH ROW EXECUTE FUNCTION update_modified_column();
+
+CREATE TRIGGER update_breed_submissions_modtime
+    BEFORE UPDATE ON breed_submissions
+    FOR EACH ROW EXECUTE FUNCTION update_modified_column();
+
+CREATE TRIGGER update_health_conditions_modtime
+    BEFORE UPDATE ON health_conditions
+    FOR EACH ROW EXECUTE FUNCTION update_modified_column();
+
 -- ===================== BREED REACTION COUNTERS =====================
 
 -- Update cat_breeds like/dislike count
@@ -290,6 +720,81 @@ CREATE TRIGGER trigger_breed_discussion_count
 AFTER INSERT OR DELETE ON discussions
 FOR EACH ROW EXECUTE FUNCTION update_breed_discussion_count();
 
+-- ===================== BREED DAILY STATS =====================
+
+-- วันของ bucket ที่ timestamp ตกอยู่
+CREATE OR REPLACE FUNCTION breed_stats_day(ts TIMESTAMP WITH TIME ZONE)
+RETURNS DATE AS $$
+    SELECT (ts AT TIME ZONE 'UTC')::date;
+$$ LANGUAGE sql IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION bump_breed_daily_stats(
+    p_breed_id INTEGER, p_views INTEGER, p_likes INTEGER, p_dislikes INTEGER, p_discussions INTEGER
+)
+RETURNS VOID AS $$
+BEGIN
+    INSERT INTO breed_daily_stats (breed_id, day, views, likes, dislikes, discussions)
+    VALUES (p_breed_id, breed_stats_day(CURRENT_TIMESTAMP), p_views, p_likes, p_dislikes, p_discussions)
+    ON CONFLICT (breed_id, day) DO UPDATE
+    SET views = breed_daily_stats.views + EXCLUDED.views,
+        likes = breed_daily_stats.likes + EXCLUDED.likes,
+        dislikes = breed_daily_stats.dislikes + EXCLUDED.dislikes,
+        discussions = breed_daily_stats.discussions + EXCLUDED.discussions;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION update_breed_daily_reactions()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF (TG_OP = 'INSERT') THEN
+        PERFORM bump_breed_daily_stats(NEW.breed_id, 0,
+            CASE WHEN NEW.reaction_type = 'like' THEN 1 ELSE 0 END,
+            CASE WHEN NEW.reaction_type = 'dislike' THEN 1 ELSE 0 END, 0);
+        RETURN NEW;
+    ELSIF (TG_OP = 'UPDATE') THEN
+        IF OLD.reaction_type <> NEW.reaction_type THEN
+            PERFORM bump_breed_daily_stats(NEW.breed_id, 0,
+                CASE WHEN NEW.reaction_type = 'like' THEN 1 ELSE -1 END,
+                CASE WHEN NEW.reaction_type = 'dislike' THEN 1 ELSE -1 END, 0);
+        END IF;
+        RETURN NEW;
+    ELSIF (TG_OP = 'DELETE') THEN
+        -- breed ถูกลบถาวร (cascade) ไม่ต้องบันทึก
+        IF EXISTS (SELECT 1 FROM cat_breeds WHERE id = OLD.breed_id) THEN
+            PERFORM bump_breed_daily_stats(OLD.breed_id, 0,
+                CASE WHEN OLD.reaction_type = 'like' THEN -1 ELSE 0 END,
+                CASE WHEN OLD.reaction_type = 'dislike' THEN -1 ELSE 0 END, 0);
+        END IF;
+        RETURN OLD;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_breed_daily_reactions
+AFTER INSERT OR UPDATE OR DELETE ON breed_reactions
+FOR EACH ROW EXECUTE FUNCTION update_breed_daily_reactions();
+
+CREATE OR REPLACE FUNCTION update_breed_daily_discussions()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF (TG_OP = 'INSERT') THEN
+        PERFORM bump_breed_daily_stats(NEW.breed_id, 0, 0, 0, 1);
+        RETURN NEW;
+    ELSIF (TG_OP = 'DELETE') THEN
+        IF EXISTS (SELECT 1 FROM cat_breeds WHERE id = OLD.breed_id) THEN
+            PERFORM bump_breed_daily_stats(OLD.breed_id, 0, 0, 0, -1);
+        END IF;
+        RETURN OLD;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_breed_daily_discussions
+AFTER INSERT OR DELETE ON discussions
+FOR EACH ROW EXECUTE FUNCTION update_breed_daily_discussions();
+
 
 -- ===================== INITIAL DATA =====================
 
@@ -338,32 +843,132 @@ WHERE r.name = 'user';
 
 
 -- Insert sample cat breeds
-INSERT INTO cat_breeds (name, origin, description, care_instructions, image_url) VALUES
-('Persian', 'Iran', 
+INSERT INTO cat_breeds (name, slug, origin, description, care_instructions, image_url) VALUES
+('Persian', 'persian', 'Iran', 
  'แมวเปอร์เซียเป็นสายพันธุ์ที่มีขนยาวและนุ่มนวล หน้าแบนและตาโต เป็นแมวที่นิ่งและชอบอยู่ในบ้าน มีนิสัยสงบเสงี่ยม เหมาะกับการเลี้ยงในอพาร์ทเมนท์',
  'ต้องหวีขนทุกวันเพื่อป้องกันขนพันกัน อาบน้ำเดือนละ 1-2 ครั้ง ตัดเล็บสม่ำเสมอ ทำความสะอาดรอบดวงตาเป็นประจำ',
  'https://images.unsplash.com/photo-1518791841217-8f162f1e1131'),
  
-('Siamese', 'Thailand', 
+('Siamese', 'siamese', 'Thailand', 
  'แมวสยามเป็นแมวพันธุ์ไทย มีลักษณะเด่นคือหูใหญ่ ตาสีฟ้า ขนสีอ่อนจุดสีเข้มที่หู หน้า ขา และหาง มีนิสัยช่างพูด ชอบสนใจและเข้าหาเจ้าของ',
  'หวีขนสัปดาห์ละ 2-3 ครั้ง ชอบพูดคุยและต้องการความสนใจ ให้อาหารคุณภาพดี เล่นกับแมวเป็นประจำ',
  'https://images.unsplash.com/photo-1513360371669-4adf3dd7dff8'),
  
-('Maine Coon', 'United States', 
+('Maine Coon', 'maine-coon', 'United States', 
  'แมวเมนคูนเป็นแมวขนาดใหญ่ มีขนยาวและหนา เป็นมิตร ฉลาด และชอบเล่นกับน้ำ มีนิสัยอ่อนโยนและเป็นมิตรกับทุกคน',
  'หวีขนสัปดาห์ละ 2-3 ครั้ง ต้องการพื้นที่ออกกำลังกาย ให้อาหารโปรตีนสูง มีน้ำสะอาดให้ตลอดเวลา',
  'https://images.unsplash.com/photo-1574158622682-e40e69881006'),
  
-('Scottish Fold', 'Scotland',
+('Scottish Fold', 'scottish-fold', 'Scotland',
  'แมวสก็อตติชโฟลด์มีลักษณะเด่นคือหูพับ ตัวกลม น่ารัก นิสัยอ่อนโยนและเป็นมิตร ชอบอยู่กับคนและสัตว์เลี้ยงอื่นๆ',
  'หวีขนสัปดาห์ละ 2-3 ครั้ง ตรวจหูสม่ำเสมอเพราะหูพับ เล่นกับแมวเป็นประจำ ให้ความรักและความสนใจ',
  'https://images.unsplash.com/photo-1568152950566-c1bf43f4ab28'),
 
-('British Shorthair', 'United Kingdom',
+('British Shorthair', 'british-shorthair', 'United Kingdom',
  'แมวบริติชชอร์ตแฮร์มีรูปร่างกลมอ้วน หน้ากลม แก้มป่อง ตาโต มีนิสัยสงบ อิสระ แต่ก็เป็นมิตรและรักเจ้าของ',
  'หวีขนสัปดาห์ละ 1-2 ครั้ง ควบคุมน้ำหนักเพราะชอบอ้วน ให้อาหารตามปริมาณที่เหมาะสม',
  'https://images.unsplash.com/photo-1595433707802-6b2626ef1c91');
 
+-- Structured attributes ของ breed ตัวอย่าง
+UPDATE cat_breeds AS cb SET
+    weight_min_kg = v.weight_min, weight_max_kg = v.weight_max,
+    lifespan_min_years = v.lifespan_min, lifespan_max_years = v.lifespan_max,
+    coat_length = v.coat::coat_length_enum,
+    shedding_level = v.shedding, activity_level = v.activity, grooming_level = v.grooming,
+    hypoallergenic = v.hypoallergenic, child_friendly = v.child, pet_friendly = v.pet,
+    temperament = v.temperament
+FROM (VALUES
+    ('Persian',           3.0, 5.5,  12, 17, 'long',  4, 1, 5, FALSE, 2, 3, ARRAY['calm', 'gentle', 'quiet', 'affectionate']),
+    ('Siamese',           2.5, 5.5,  15, 20, 'short', 2, 5, 1, FALSE, 4, 4, ARRAY['vocal', 'social', 'intelligent', 'affectionate', 'playful']),
+    ('Maine Coon',        4.5, 11.0, 12, 15, 'long',  4, 3, 3, FALSE, 5, 5, ARRAY['gentle', 'friendly', 'intelligent', 'playful']),
+    ('Scottish Fold',     2.5, 6.0,  11, 15, 'short', 3, 2, 2, FALSE, 4, 4, ARRAY['calm', 'affectionate', 'gentle', 'adaptable']),
+    ('British Shorthair', 3.5, 8.0,  12, 20, 'short', 3, 2, 2, FALSE, 4, 4, ARRAY['calm', 'independent', 'loyal', 'easygoing'])
+) AS v(name, weight_min, weight_max, lifespan_min, lifespan_max, coat, shedding, activity, grooming, hypoallergenic, child, pet, temperament)
+WHERE cb.name = v.name;
+
+-- revision แรกของ breed ตัวอย่าง
+INSERT INTO breed_revisions (breed_id, revision_number, action, name, origin, description, care_instructions, image_url, attributes)
+SELECT id, 1, 'create', name, origin, description, care_instructions, image_url,
+       jsonb_build_object(
+           'weight_min_kg', weight_min_kg, 'weight_max_kg', weight_max_kg,
+           'lifespan_min_years', lifespan_min_years, 'lifespan_max_years', lifespan_max_years,
+           'coat_length', coat_length, 'shedding_level', shedding_level,
+           'activity_level', activity_level, 'grooming_level', grooming_level,
+           'child_friendly', child_friendly, 'pet_friendly', pet_friendly,
+           'hypoallergenic', hypoallergenic, 'temperament', temperament
+       )
+FROM cat_breeds;
+
+
+-- คำแปลภาษาอังกฤษของ breed ตัวอย่าง
+INSERT INTO breed_translations (breed_id, locale, name, description, care_instructions)
+SELECT cb.id, 'en', v.name, v.description, v.care
+FROM cat_breeds cb
+JOIN (VALUES
+('Persian', 'Persian',
+ 'The Persian is a long-haired breed with a soft, flowing coat, a flat face and large eyes. Calm and home-loving with a gentle temperament, it is well suited to apartment living.',
+ 'Brush daily to prevent mats, bathe once or twice a month, trim the claws regularly and clean around the eyes every day.'),
+('Siamese', 'Siamese',
+ 'The Siamese is a breed from Thailand, known for its large ears, blue eyes and a pale coat with darker points on the ears, face, legs and tail. Talkative, curious and very people-oriented.',
+ 'Brush 2-3 times a week. Siamese love to talk and need attention, so feed a high-quality diet and play with them every day.'),
+('Maine Coon', 'Maine Coon',
+ 'The Maine Coon is a large cat with a long, thick coat. Friendly, intelligent and famously fond of water, it is gentle and gets along with everyone.',
+ 'Brush 2-3 times a week, provide plenty of room to exercise, feed a high-protein diet and always keep fresh water available.'),
+('Scottish Fold', 'Scottish Fold',
+ 'The Scottish Fold is recognised by its folded ears and round body. Sweet-natured and gentle, it enjoys the company of people and other pets.',
+ 'Brush 2-3 times a week and check the folded ears regularly. Play with your cat every day and give plenty of affection.'),
+('British Shorthair', 'British Shorthair',
+ 'The British Shorthair has a stocky, rounded body, a round face with chubby cheeks and large eyes. Calm and independent, yet friendly and devoted to its owners.',
+ 'Brush once or twice a week and watch its weight, as the breed gains weight easily. Feed measured portions.')
+) AS v(breed_name, name, description, care) ON cb.name = v.breed_name;
+
+
+-- โรคทางพันธุกรรมที่พบบ่อยของ breed ตัวอย่าง
+INSERT INTO health_conditions (name, abbreviation, description, severity, genetic, screening_tests, reference_links) VALUES
+('Polycystic Kidney Disease', 'PKD',
+ 'Fluid-filled cysts form in the kidneys from birth and grow over time, eventually leading to kidney failure.',
+ 'severe', TRUE, ARRAY['PKD1 DNA test', 'Kidney ultrasound'],
+ '[{"title": "International Cat Care: Polycystic kidney disease", "url": "https://icatcare.org/advice/polycystic-kidney-disease/"}]'),
+('Hypertrophic Cardiomyopathy', 'HCM',
+ 'Thickening of the heart muscle that reduces its ability to pump; the most common heart disease in cats.',
+ 'life_threatening', TRUE, ARRAY['Echocardiogram', 'MYBPC3 DNA test'],
+ '[{"title": "International Cat Care: Hypertrophic cardiomyopathy", "url": "https://icatcare.org/advice/hypertrophic-cardiomyopathy-hcm/"}]'),
+('Osteochondrodysplasia', 'OCD',
+ 'A cartilage and bone development disorder caused by the same gene that folds the ears, leading to painful joints and arthritis.',
+ 'severe', TRUE, ARRAY['Radiographs of limbs and tail'],
+ '[{"title": "International Cat Care: Scottish Fold osteochondrodysplasia", "url": "https://icatcare.org/advice/scottish-fold-osteochondrodysplasia/"}]'),
+('Progressive Retinal Atrophy', 'PRA',
+ 'Gradual degeneration of the retina that leads to blindness.',
+ 'moderate', TRUE, ARRAY['rdAc DNA test', 'Ophthalmic examination'],
+ '[]'),
+('Hip Dysplasia', '',
+ 'Malformation of the hip joint that causes lameness and arthritis, more common in large breeds.',
+ 'moderate', TRUE, ARRAY['Hip radiographs'],
+ '[]');
+
+INSERT INTO breed_health_conditions (breed_id, condition_id, prevalence)
+SELECT cb.id, hc.id, v.prevalence::condition_prevalence_enum
+FROM (VALUES
+    ('Persian',           'PKD', 'very_common'),
+    ('Persian',           'HCM', 'occasional'),
+    ('Siamese',           'PRA', 'occasional'),
+    ('Maine Coon',        'HCM', 'common'),
+    ('Maine Coon',        'Hip Dysplasia', 'common'),
+    ('Scottish Fold',     'OCD', 'very_common'),
+    ('Scottish Fold',     'PKD', 'occasional'),
+    ('British Shorthair', 'HCM', 'occasional'),
+    ('British Shorthair', 'PKD', 'occasional')
+) AS v(breed_name, condition, prevalence)
+JOIN cat_breeds cb ON cb.name = v.breed_name
+JOIN health_conditions hc ON hc.abbreviation = v.condition OR hc.name = v.condition;
+
+-- น้ำหนักเริ่มต้นของ breed matcher
+INSERT INTO matcher_weights (criterion, weight) VALUES
+('living_space', 1.0),
+('allergies', 2.0),
+('grooming_time', 1.0),
+('kids', 1.5),
+('other_pets', 1.0);
 
 -- ===================== INSERT USERS & ADMIN =====================
 